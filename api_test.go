@@ -0,0 +1,166 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/cavcrosby/rsb/notify"
+	"github.com/cavcrosby/rsb/store"
+)
+
+// Send the request to the handler, returning the response.
+func apiRequest(t *testing.T, h http.Handler, method, target string) *httptest.ResponseRecorder {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(method, target, nil))
+	return rec
+}
+
+func TestApiMatches(t *testing.T) {
+	matchStore := store.NewMatchStore(defaultRecentMatches)
+	matchStore.Record([]notify.MatchRecord{
+		{Subreddit: "buildapcsales", Title: "first", Rules: []string{"ramunderprice"}},
+		{Subreddit: "hardwareswap", Title: "second", Rules: []string{"gpuunderprice"}},
+	}, time.Date(2021, time.June, 1, 12, 0, 0, 0, time.UTC))
+	matchStore.Record([]notify.MatchRecord{
+		{Subreddit: "buildapcsales", Title: "third", Rules: []string{"ramunderprice", "keywordmatch"}},
+	}, time.Date(2021, time.June, 1, 12, 5, 0, 0, time.UTC))
+	h := newApiHandler(matchStore)
+
+	tests := []struct {
+		name       string
+		target     string
+		wantTitles []string
+	}{
+		{"every match", "/matches", []string{"third", "second", "first"}},
+		{"by rule", "/matches?rule=ramunderprice", []string{"third", "first"}},
+		{"by another rule of the match", "/matches?rule=keywordmatch", []string{"third"}},
+		{"by subreddit", "/matches?subreddit=hardwareswap", []string{"second"}},
+		{"by subreddit in another case", "/matches?subreddit=BuildAPCSales", []string{"third", "first"}},
+		{"by rule and subreddit", "/matches?rule=ramunderprice&subreddit=hardwareswap", []string{}},
+		{"by an unknown rule", "/matches?rule=notarule", []string{}},
+	}
+
+	for _, tt := range tests {
+		rec := apiRequest(t, h, http.MethodGet, tt.target)
+		if rec.Code != http.StatusOK {
+			t.Errorf("%v: GET %v status = %v, want %v", tt.name, tt.target, rec.Code, http.StatusOK)
+			continue
+		}
+		if got := rec.Header().Get("Content-Type"); got != "application/json" {
+			t.Errorf("%v: GET %v Content-Type = %q, want %q", tt.name, tt.target, got, "application/json")
+		}
+
+		var records []store.MatchStoreRecord
+		if err := json.Unmarshal(rec.Body.Bytes(), &records); err != nil {
+			t.Fatalf("%v: GET %v returned invalid JSON %q: %v", tt.name, tt.target, rec.Body.String(), err)
+		}
+		gotTitles := []string{}
+		for _, record := range records {
+			gotTitles = append(gotTitles, record.Title)
+		}
+		if !reflect.DeepEqual(gotTitles, tt.wantTitles) {
+			t.Errorf("%v: GET %v = %v, want %v", tt.name, tt.target, gotTitles, tt.wantTitles)
+		}
+	}
+}
+
+func TestApiRules(t *testing.T) {
+	rec := apiRequest(t, newApiHandler(store.NewMatchStore(defaultRecentMatches)), http.MethodGet, "/rules")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /rules status = %v, want %v", rec.Code, http.StatusOK)
+	}
+
+	var listings []ruleListing
+	if err := json.Unmarshal(rec.Body.Bytes(), &listings); err != nil {
+		t.Fatalf("GET /rules returned invalid JSON %q: %v", rec.Body.String(), err)
+	}
+	if want := listRules(); !reflect.DeepEqual(listings, want) {
+		t.Errorf("GET /rules = %v, want %v", listings, want)
+	}
+}
+
+func TestApiMethodNotAllowed(t *testing.T) {
+	h := newApiHandler(store.NewMatchStore(defaultRecentMatches))
+	tests := []struct {
+		method string
+		target string
+		want   int
+	}{
+		{http.MethodPost, "/matches", http.StatusMethodNotAllowed},
+		{http.MethodDelete, "/matches", http.StatusMethodNotAllowed},
+		{http.MethodPost, "/rules", http.StatusMethodNotAllowed},
+		{http.MethodGet, "/notfound", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		if rec := apiRequest(t, h, tt.method, tt.target); rec.Code != tt.want {
+			t.Errorf("%v %v status = %v, want %v", tt.method, tt.target, rec.Code, tt.want)
+		}
+	}
+}
+
+func TestServeHttp(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer listener.Close()
+
+	tests := []struct {
+		name    string
+		addr    string
+		wantErr bool
+	}{
+		// the address is already in use
+		{"address in use", listener.Addr().String(), true},
+		{"free address", "127.0.0.1:0", false},
+	}
+
+	for _, tt := range tests {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		errs := make(chan error, 1)
+		go func() { errs <- serveHttp(ctx, tt.addr, http.NotFoundHandler()) }()
+		if !tt.wantErr {
+			// the server stops without an error once the context is done
+			time.Sleep(50 * time.Millisecond)
+			cancel()
+		}
+
+		select {
+		case err := <-errs:
+			if (err != nil) != tt.wantErr {
+				t.Errorf("%v: serveHttp(%v) error = %v, wantErr %v", tt.name, tt.addr, err, tt.wantErr)
+			}
+		case <-time.After(5 * time.Second):
+			t.Errorf("%v: serveHttp(%v) did not return", tt.name, tt.addr)
+		}
+	}
+}
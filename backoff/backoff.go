@@ -0,0 +1,50 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package backoff
+
+import (
+	"math/rand"
+	"time"
+)
+
+// Determine how long to wait after an attempt (counting from 1) before the next
+// one. The delay doubles from the base delay each attempt, up to the maximum
+// delay. Half of the delay is fixed and the other half is random so retries from
+// multiple callers spread out. The jitter returns a number in [0, n), being
+// rand.Int63n if nil.
+func Delay(attempt int, baseDelay, maxDelay time.Duration, jitter func(n int64) int64) time.Duration {
+	delay := baseDelay
+	for i := 1; i < attempt && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxDelay {
+		delay = maxDelay
+	}
+
+	half := delay / 2
+	if half <= 0 {
+		return delay
+	}
+	if jitter == nil {
+		jitter = rand.Int63n
+	}
+
+	return half + time.Duration(jitter(int64(half)+1))
+}
@@ -0,0 +1,70 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package backoff
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDelay(t *testing.T) {
+	noJitter := func(n int64) int64 { return 0 }
+	fullJitter := func(n int64) int64 { return n - 1 }
+	tests := []struct {
+		attempt int
+		jitter  func(n int64) int64
+		want    time.Duration
+	}{
+		{1, noJitter, 500 * time.Millisecond},
+		{2, noJitter, time.Second},
+		{3, noJitter, 2 * time.Second},
+		{4, noJitter, 2500 * time.Millisecond},
+		{10, noJitter, 2500 * time.Millisecond},
+		{1, fullJitter, time.Second},
+		{4, fullJitter, 5 * time.Second},
+	}
+
+	for _, tt := range tests {
+		if got := Delay(tt.attempt, time.Second, 5*time.Second, tt.jitter); got != tt.want {
+			t.Errorf("Delay(%v) = %v, want %v", tt.attempt, got, tt.want)
+		}
+	}
+}
+
+func TestDelayRandomJitter(t *testing.T) {
+	for attempt := 1; attempt <= 8; attempt++ {
+		delay := time.Second << (attempt - 1)
+		if delay > 30*time.Second {
+			delay = 30 * time.Second
+		}
+
+		for i := 0; i < 20; i++ {
+			if got := Delay(attempt, time.Second, 30*time.Second, nil); got < delay/2 || got > delay {
+				t.Errorf("Delay(%v) = %v, want between %v and %v", attempt, got, delay/2, delay)
+			}
+		}
+	}
+}
+
+func TestDelayNoHalf(t *testing.T) {
+	if got := Delay(3, time.Nanosecond, time.Nanosecond, nil); got != time.Nanosecond {
+		t.Errorf("Delay(3) = %v, want %v", got, time.Nanosecond)
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/cavcrosby/rsb/engine"
+)
+
+const (
+	colorAuto   = "auto"
+	colorAlways = "always"
+	colorNever  = "never"
+)
+
+const (
+	ansiReset  = "\x1b[0m"
+	ansiBold   = "\x1b[1m"
+	ansiDim    = "\x1b[2m"
+	ansiYellow = "\x1b[33m"
+)
+
+var (
+	rePriceInText = regexp.MustCompile(`\$\d[\d,]*(?:\.\d+)?`)
+)
+
+// Determine if a file is a terminal.
+func isTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	if err != nil {
+		return false
+	}
+
+	return fi.Mode()&os.ModeCharDevice != 0
+}
+
+// Determine if output to the file should be colored given the color mode. In the
+// auto mode, output is colored only when the file is a terminal and the NO_COLOR
+// environment variable is not set.
+func colorEnabled(colorMode string, f *os.File) (bool, error) {
+	switch colorMode {
+	case colorAlways:
+		return true, nil
+	case colorNever:
+		return false, nil
+	case "", colorAuto:
+		_, noColor := os.LookupEnv("NO_COLOR")
+		return !noColor && isTerminal(f), nil
+	default:
+		return false, fmt.Errorf("the following color mode is not known: %v", colorMode)
+	}
+}
+
+// A type that prints matches for display, optionally with color (prices are
+// highlighted and rule names are dimmed).
+type matchPrinter struct {
+	w     io.Writer
+	color bool
+}
+
+// Print a match, numbered by its position passed in, followed by the reasons the
+// match's rules matched (one per line).
+func (mp *matchPrinter) print(i int, match *engine.PostMatch) {
+	rules := "(" + strings.Join(match.Rules, ",") + ")"
+	title := match.Post.Title
+	if mp.color {
+		rules = ansiDim + rules + ansiReset
+		title = rePriceInText.ReplaceAllStringFunc(title, func(price string) string {
+			return ansiBold + ansiYellow + price + ansiReset
+		})
+	}
+
+	line := strconv.Itoa(i) + rules + ". " + title
+	if match.Post.URL != "" {
+		line += " " + match.Post.URL
+	}
+	if match.Count > 1 {
+		line += fmt.Sprintf(" (seen %vx)", match.Count)
+	}
+	fmt.Fprintln(mp.w, line)
+
+	for _, reason := range match.Reasons {
+		if mp.color {
+			reason = ansiDim + reason + ansiReset
+		}
+		fmt.Fprintln(mp.w, "    "+reason)
+	}
+}
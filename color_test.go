@@ -0,0 +1,108 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cavcrosby/rsb/engine"
+	"github.com/turnage/graw/reddit"
+)
+
+func TestColorEnabled(t *testing.T) {
+	// a regular file stands in for stdout not being a terminal
+	f, err := os.Create(filepath.Join(t.TempDir(), "stdout"))
+	if err != nil {
+		t.Fatalf("failed to create file: %v", err)
+	}
+	defer f.Close()
+
+	tests := []struct {
+		colorMode string
+		noColor   bool
+		want      bool
+		wantErr   bool
+	}{
+		{colorAlways, false, true, false},
+		{colorAlways, true, true, false},
+		{colorNever, false, false, false},
+		{colorAuto, false, false, false},
+		{colorAuto, true, false, false},
+		{"", false, false, false},
+		{"sometimes", false, false, true},
+	}
+
+	for _, tt := range tests {
+		// NO_COLOR is restored once the test ends
+		t.Setenv("NO_COLOR", "1")
+		if !tt.noColor {
+			os.Unsetenv("NO_COLOR")
+		}
+
+		got, err := colorEnabled(tt.colorMode, f)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("colorEnabled(%q) error = %v, wantErr %v", tt.colorMode, err, tt.wantErr)
+		} else if got != tt.want {
+			t.Errorf("colorEnabled(%q) with NO_COLOR %v = %v, want %v", tt.colorMode, tt.noColor, got, tt.want)
+		}
+	}
+}
+
+func TestMatchPrinterPrint(t *testing.T) {
+	match := &engine.PostMatch{
+		Post:    &reddit.Post{Title: "[RAM] Corsair Vengeance 16GB $49.99", URL: "https://example.com/ram"},
+		Rules:   []string{"keywordmatch", "pricerange"},
+		Reasons: []string{"pricerange: $49.99 is within $0-$100"},
+		Count:   1,
+	}
+
+	tests := []struct {
+		color bool
+		want  string
+	}{
+		{
+			false,
+			"1(keywordmatch,pricerange). [RAM] Corsair Vengeance 16GB $49.99 https://example.com/ram\n" +
+				"    pricerange: $49.99 is within $0-$100\n",
+		},
+		{
+			true,
+			"1" + ansiDim + "(keywordmatch,pricerange)" + ansiReset + ". [RAM] Corsair Vengeance 16GB " +
+				ansiBold + ansiYellow + "$49.99" + ansiReset + " https://example.com/ram\n" +
+				"    " + ansiDim + "pricerange: $49.99 is within $0-$100" + ansiReset + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		mp := &matchPrinter{w: &buf, color: tt.color}
+		mp.print(1, match)
+		if got := buf.String(); got != tt.want {
+			t.Errorf("print() with color %v = %q, want %q", tt.color, got, tt.want)
+		}
+		if hasCodes := strings.Contains(buf.String(), "\x1b["); hasCodes != tt.color {
+			t.Errorf("print() with color %v has color codes = %v", tt.color, hasCodes)
+		}
+	}
+}
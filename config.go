@@ -0,0 +1,420 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/cavcrosby/rsb/engine"
+	"github.com/cavcrosby/rsb/logging"
+	"github.com/cavcrosby/rsb/rule"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	configVersion = 2
+)
+
+const (
+	envPrefix           = "RSB_"
+	envSubreddits       = envPrefix + "SUBREDDITS"
+	envRuleConfigPrefix = envPrefix + "RULE_"
+)
+
+var (
+	// the migrations are keyed by the version they migrate from
+	configMigrations = map[int]configMigration{
+		1: migrateConfigV1,
+	}
+)
+
+// A type that represents a migration of a decoded configuration file from one
+// version to the next.
+type configMigration func(doc map[string]interface{})
+
+// Migrate a version 1 configuration file. The keys of a version 1 file are kept
+// as they are, but a SMTP port given as a number (e.g. "smtp_port: 587" in YAML)
+// is converted to the string version 2 takes.
+func migrateConfigV1(doc map[string]interface{}) {
+	if port, ok := doc["smtp_port"].(float64); ok {
+		doc["smtp_port"] = strconv.FormatFloat(port, 'f', -1, 64)
+	}
+}
+
+// Migrate a decoded configuration file to the current version. Files without a
+// version are version 1, the version before versioning was introduced. Files of
+// a newer version than the program understands cannot be migrated.
+func migrateConfig(doc map[string]interface{}) error {
+	version := 1
+	if v, ok := doc["version"]; ok {
+		num, ok := v.(float64)
+		if !ok || num != float64(int(num)) || num < 1 {
+			return fmt.Errorf("the configuration file version is not valid: %v", v)
+		}
+		version = int(num)
+	}
+
+	if version > configVersion {
+		return fmt.Errorf("the configuration file is version %v, but this version of %v only understands up to version %v", version, progName, configVersion)
+	}
+
+	for ; version < configVersion; version++ {
+		logging.Debugf("%v: migrating the configuration file from version %v to %v", progName, version, version+1)
+		configMigrations[version](doc)
+	}
+	doc["version"] = configVersion
+
+	return nil
+}
+
+// Get the path to the configuration file of an instance. An existing JSON file
+// is preferred over an existing YAML file, with the JSON path being returned if
+// neither exists.
+func findProgConfigPath(progConfigDirPath, instName string) (string, error) {
+	for _, ext := range append([]string{progConfigExt}, engine.YamlConfigExts...) {
+		progConfigPath := filepath.Join(progConfigDirPath, instName+ext)
+		if _, err := os.Stat(progConfigPath); err == nil {
+			return progConfigPath, nil
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			return "", err
+		}
+	}
+
+	return filepath.Join(progConfigDirPath, instName+progConfigExt), nil
+}
+
+// Parse the bytes of a configuration file in the given format. YAML is parsed by
+// way of JSON so both formats populate the configTree the same way (e.g. the
+// numbers in rule configs are always float64). Older versions of the file are
+// migrated and unknown keys are rejected before the configTree is populated.
+func unmarshalConfigTree(data []byte, format string, ct *configTree) error {
+	doc, err := engine.DecodeConfig(data, format)
+	if err != nil {
+		return err
+	} else if doc == nil {
+		return nil
+	}
+
+	if obj, ok := doc.(map[string]interface{}); ok {
+		if err := migrateConfig(obj); err != nil {
+			return err
+		}
+	}
+	if keyPaths := unknownConfigKeys("", doc, reflect.TypeOf(*ct)); len(keyPaths) > 0 {
+		return fmt.Errorf("the following configuration keys are not known: %v", strings.Join(keyPaths, ", "))
+	}
+
+	jsonBytes, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(jsonBytes, ct)
+}
+
+// Get the fields of a struct type by the name each field is given in JSON, the
+// fields of embedded structs being included as if they were the struct's own.
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			for name, fieldType := range jsonFields(field.Type) {
+				fields[name] = fieldType
+			}
+			continue
+		} else if field.PkgPath != "" {
+			continue
+		}
+
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "-" {
+			continue
+		} else if name == "" {
+			name = field.Name
+		}
+		fields[name] = field.Type
+	}
+
+	return fields
+}
+
+// Get the field of a struct type with the name in JSON. Like encoding/json, an
+// exact match is preferred over a case insensitive one.
+func jsonField(fields map[string]reflect.Type, name string) (reflect.Type, bool) {
+	if fieldType, ok := fields[name]; ok {
+		return fieldType, true
+	}
+	for fieldName, fieldType := range fields {
+		if strings.EqualFold(fieldName, name) {
+			return fieldType, true
+		}
+	}
+
+	return nil, false
+}
+
+// Join a key onto the path of its parent (e.g. rules[0].configs.price).
+func joinKeyPath(keyPath, key string) string {
+	if keyPath == "" {
+		return key
+	}
+
+	return keyPath + "." + key
+}
+
+// Get the paths of the keys in a decoded JSON document that do not correspond to
+// a field of the type the document is decoded into. The configs of a engine.RuleConfig
+// are checked against the fields of the rule with the RuleConfig's id, rules not
+// in the rule registry being left to be reported elsewhere.
+func unknownConfigKeys(keyPath string, v interface{}, t reflect.Type) []string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	var keyPaths []string
+	switch t.Kind() {
+	case reflect.Struct:
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+
+		keys := make([]string, 0, len(obj))
+		for key := range obj {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		fields := jsonFields(t)
+		for _, key := range keys {
+			fieldType, ok := jsonField(fields, key)
+			if !ok {
+				keyPaths = append(keyPaths, joinKeyPath(keyPath, key))
+				continue
+			}
+
+			if t == reflect.TypeOf(engine.RuleConfig{}) && strings.EqualFold(key, "configs") {
+				id, _ := obj["id"].(string)
+				if r, err := rule.GetRuleRegistry().Lookup(id); err == nil {
+					fieldType = reflect.TypeOf(r)
+				}
+			}
+			keyPaths = append(keyPaths, unknownConfigKeys(joinKeyPath(keyPath, key), obj[key], fieldType)...)
+		}
+	case reflect.Slice, reflect.Array:
+		arr, ok := v.([]interface{})
+		if !ok {
+			return nil
+		}
+
+		for i, elem := range arr {
+			keyPaths = append(keyPaths, unknownConfigKeys(fmt.Sprintf("%v[%v]", keyPath, i), elem, t.Elem())...)
+		}
+	case reflect.Map:
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+
+		for key, elem := range obj {
+			keyPaths = append(keyPaths, unknownConfigKeys(joinKeyPath(keyPath, key), elem, t.Elem())...)
+		}
+		sort.Strings(keyPaths)
+	}
+
+	return keyPaths
+}
+
+// Clear the styles of a YAML node and its children, so the node is written in
+// the block style vs the flow style of JSON.
+func clearYamlStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		clearYamlStyle(child)
+	}
+}
+
+// Create the bytes of a configuration file in the given format. YAML keys keep
+// the names and order of their JSON counterparts.
+func marshalConfigTree(ct *configTree, format string) ([]byte, error) {
+	// use 4 spaces vs a tab character for indenting
+	jsonBytes, err := json.MarshalIndent(ct, "", "    ")
+	if err != nil || format != engine.YamlConfigFormat {
+		return jsonBytes, err
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(jsonBytes, &doc); err != nil {
+		return nil, err
+	}
+	clearYamlStyle(&doc)
+
+	return yaml.Marshal(&doc)
+}
+
+// Normalize a rule id or config key for comparing against part of an environment
+// variable name, the name being upper case and without underscores (e.g.
+// maxRealisticPrice becomes MAXREALISTICPRICE).
+func envName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			return -1
+		}
+		return unicode.ToUpper(r)
+	}, name)
+}
+
+// Get the config keys a rule accepts, these being the names of the fields of the
+// rule in JSON.
+func ruleConfigKeys(r rule.Rule) []string {
+	t := reflect.TypeOf(r)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var keys []string
+	for key := range jsonFields(t) {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// Parse the value of an environment variable used as a rule config. Values that
+// are valid JSON (e.g. 150, true or ["a", "b"]) are decoded, other values are
+// used as is.
+func parseEnvValue(value string) interface{} {
+	var v interface{}
+	if err := json.Unmarshal([]byte(value), &v); err != nil {
+		return value
+	}
+
+	return v
+}
+
+// Override a config of every engine.RuleConfig with the rule id, including RuleConfigs
+// nested in composed rules. The number of RuleConfigs overridden is returned.
+func overrideRuleConfig(rcs []engine.RuleConfig, id, key string, value interface{}) int {
+	var overridden int
+	for i := range rcs {
+		rc := &rcs[i]
+		overridden += overrideRuleConfig(rc.Rules, id, key, value)
+		if rc.Rule != nil {
+			childRcs := []engine.RuleConfig{*rc.Rule}
+			overridden += overrideRuleConfig(childRcs, id, key, value)
+			*rc.Rule = childRcs[0]
+		}
+		if rc.Op != "" || rc.ID != id {
+			continue
+		}
+
+		if rc.Configs == nil {
+			rc.Configs = make(map[string]interface{})
+		}
+		rc.Configs[key] = value
+		overridden++
+	}
+
+	return overridden
+}
+
+// Apply the overrides set in the environment to the configTree. Environment
+// variables take precedence over the configuration file, with the following
+// being supported:
+//
+// RSB_SUBREDDITS: a comma separated list of the subreddits to watch (e.g.
+// buildapcsales,hardwareswap).
+//
+// RSB_RULE_<ID>_<KEY>: a config of the rule with the id (e.g.
+// RSB_RULE_RAMUNDERPRICE_PRICE=150). The id and key are matched without regard
+// to case or underscores (e.g. RSB_RULE_RAMUNDERPRICE_MAX_REALISTIC_PRICE sets
+// maxRealisticPrice).
+func applyEnvOverrides(ct *configTree, environ []string) error {
+	for _, env := range environ {
+		nameValue := strings.SplitN(env, "=", 2)
+		if len(nameValue) != 2 {
+			continue
+		}
+		name, value := nameValue[0], nameValue[1]
+
+		switch {
+		case name == envSubreddits:
+			ct.Subreddits = nil
+			for _, subredditName := range strings.Split(value, ",") {
+				if subredditName = strings.TrimSpace(subredditName); subredditName != "" {
+					ct.Subreddits = append(ct.Subreddits, subredditName)
+				}
+			}
+		case strings.HasPrefix(name, envRuleConfigPrefix):
+			if err := applyRuleEnvOverride(ct, name, value); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// Apply a RSB_RULE_<ID>_<KEY> environment variable to the configTree.
+func applyRuleEnvOverride(ct *configTree, name, value string) error {
+	rest := strings.TrimPrefix(name, envRuleConfigPrefix)
+	for _, id := range rule.GetRuleRegistry().Names() {
+		idPrefix := envName(id) + "_"
+		if !strings.HasPrefix(rest, idPrefix) {
+			continue
+		}
+
+		r, err := rule.GetRuleRegistry().Lookup(id)
+		if err != nil {
+			return err
+		}
+
+		envKey := envName(strings.TrimPrefix(rest, idPrefix))
+		for _, key := range ruleConfigKeys(r) {
+			if envName(key) != envKey {
+				continue
+			}
+
+			if overrideRuleConfig(ct.RuleConfigs, id, key, parseEnvValue(value)) == 0 {
+				return fmt.Errorf("the environment variable %v overrides the %v rule, which is not configured", name, id)
+			}
+			return nil
+		}
+
+		return fmt.Errorf("the environment variable %v does not name a config of the %v rule", name, id)
+	}
+
+	return fmt.Errorf("the environment variable %v does not name a known rule", name)
+}
@@ -0,0 +1,88 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+//go:build !windows
+// +build !windows
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+func TestConfigFilePerms(t *testing.T) {
+	// the umask is cleared so the permission bits are those the files are created
+	// with
+	umask := syscall.Umask(0)
+	defer syscall.Umask(umask)
+
+	tests := []struct {
+		name  string
+		write func(progConfigPath string) error
+	}{
+		{
+			"default configuration file",
+			func(progConfigPath string) error {
+				return createDefaultProgConfig(filepath.Dir(progConfigPath), filepath.Base(progConfigPath))
+			},
+		},
+		{
+			"init configuration file",
+			func(progConfigPath string) error {
+				ct, _, err := newInitConfigTree(initOptions{
+					subreddits:  defaultSubreddits,
+					ruleID:      defaultStarterRule,
+					ruleConfigs: defaultStarterConfigs,
+				})
+				if err != nil {
+					return err
+				}
+
+				return writeInitConfig(progConfigPath, ct, false)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		progConfigDirPath := filepath.Join(t.TempDir(), progName)
+		progConfigPath := filepath.Join(progConfigDirPath, "rsb.json")
+		if err := tt.write(progConfigPath); err != nil {
+			t.Fatalf("%v: failed to write configuration file: %v", tt.name, err)
+		}
+
+		fi, err := os.Stat(progConfigPath)
+		if err != nil {
+			t.Fatalf("%v: failed to stat configuration file: %v", tt.name, err)
+		}
+		if !fi.Mode().IsRegular() || fi.Mode().Perm() != 0o644 {
+			t.Errorf("%v: configuration file mode = %v, want a regular file with -rw-r--r--", tt.name, fi.Mode())
+		}
+
+		dirFi, err := os.Stat(progConfigDirPath)
+		if err != nil {
+			t.Fatalf("%v: failed to stat configuration directory: %v", tt.name, err)
+		}
+		if !dirFi.IsDir() || dirFi.Mode().Perm() != 0o755 {
+			t.Errorf("%v: configuration directory mode = %v, want a directory with rwxr-xr-x", tt.name, dirFi.Mode())
+		}
+	}
+}
@@ -0,0 +1,372 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/cavcrosby/rsb/engine"
+)
+
+func TestUnmarshalConfigTreeMigrates(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   string
+		format string
+		want   configTree
+	}{
+		{
+			"json without a version",
+			`{"sendmail_from": "foo@bar.com", "sendmail_to": "baz@bar.com", "smtp_addr": "smtp.bar.com", "smtp_port": "1234", "subreddits": ["buildapcsales"]}`,
+			engine.JsonConfigFormat,
+			configTree{Version: configVersion, SendMailFrom: "foo@bar.com", SendMailTo: "baz@bar.com", SmtpAddr: "smtp.bar.com", SmtpPort: "1234", Subreddits: []string{"buildapcsales"}},
+		},
+		{
+			"yaml without a version",
+			"sendmail_from: foo@bar.com\nsendmail_to: baz@bar.com\n",
+			engine.YamlConfigFormat,
+			configTree{Version: configVersion, SendMailFrom: "foo@bar.com", SendMailTo: "baz@bar.com"},
+		},
+		{
+			"yaml with a numeric smtp port",
+			"smtp_addr: smtp.bar.com\nsmtp_port: 587\n",
+			engine.YamlConfigFormat,
+			configTree{Version: configVersion, SmtpAddr: "smtp.bar.com", SmtpPort: "587"},
+		},
+		{
+			"json of version 1",
+			`{"version": 1, "sendmail_to": "baz@bar.com"}`,
+			engine.JsonConfigFormat,
+			configTree{Version: configVersion, SendMailTo: "baz@bar.com"},
+		},
+		{
+			"json of the current version",
+			`{"version": 2, "sendmail_to": "baz@bar.com"}`,
+			engine.JsonConfigFormat,
+			configTree{Version: configVersion, SendMailTo: "baz@bar.com"},
+		},
+	}
+
+	for _, tt := range tests {
+		var got configTree
+		if err := unmarshalConfigTree([]byte(tt.data), tt.format, &got); err != nil {
+			t.Errorf("%v: unmarshalConfigTree returned an error: %v", tt.name, err)
+		} else if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%v: unmarshalConfigTree = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestMigrateConfig(t *testing.T) {
+	tests := []struct {
+		doc     map[string]interface{}
+		want    map[string]interface{}
+		wantErr string
+	}{
+		{
+			map[string]interface{}{"sendmail_from": "foo@bar.com"},
+			map[string]interface{}{"version": configVersion, "sendmail_from": "foo@bar.com"},
+			"",
+		},
+		{
+			map[string]interface{}{"version": float64(1), "rules": []interface{}{}},
+			map[string]interface{}{"version": configVersion, "rules": []interface{}{}},
+			"",
+		},
+		{
+			map[string]interface{}{"smtp_addr": "smtp.bar.com", "smtp_port": float64(587)},
+			map[string]interface{}{"version": configVersion, "smtp_addr": "smtp.bar.com", "smtp_port": "587"},
+			"",
+		},
+		{
+			map[string]interface{}{"version": float64(1), "smtp_port": "587"},
+			map[string]interface{}{"version": configVersion, "smtp_port": "587"},
+			"",
+		},
+		{map[string]interface{}{"version": float64(configVersion + 1)}, nil, "only understands up to version"},
+		{map[string]interface{}{"version": float64(0)}, nil, "version is not valid"},
+		{map[string]interface{}{"version": 1.5}, nil, "version is not valid"},
+		{map[string]interface{}{"version": "2"}, nil, "version is not valid"},
+	}
+
+	for _, tt := range tests {
+		doc := tt.doc
+		err := migrateConfig(doc)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("migrateConfig(%v) returned error %v, want an error containing %q", tt.doc, err, tt.wantErr)
+			}
+		} else if err != nil {
+			t.Errorf("migrateConfig(%v) returned an error: %v", tt.doc, err)
+		} else if !reflect.DeepEqual(doc, tt.want) {
+			t.Errorf("migrateConfig = %v, want %v", doc, tt.want)
+		}
+	}
+}
+
+func TestFindProgConfigPath(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing []string
+		instName string
+		want     string
+	}{
+		{"no file defaults to json", nil, "rsb-work", "rsb-work.json"},
+		{"existing yaml", []string{"rsb-work.yaml"}, "rsb-work", "rsb-work.yaml"},
+		{"json preferred over yaml", []string{"rsb-work.yaml", "rsb-work.json"}, "rsb-work", "rsb-work.json"},
+		{"other instance ignored", []string{"rsb.yaml"}, "rsb-work", "rsb-work.json"},
+	}
+	for _, tt := range tests {
+		progConfigDirPath := t.TempDir()
+		for _, name := range tt.existing {
+			if err := ioutil.WriteFile(filepath.Join(progConfigDirPath, name), []byte("{}"), 0o600); err != nil {
+				t.Fatalf("failed to write configuration file: %v", err)
+			}
+		}
+
+		got, err := findProgConfigPath(progConfigDirPath, tt.instName)
+		if err != nil {
+			t.Errorf("%v: findProgConfigPath() error = %v", tt.name, err)
+		} else if want := filepath.Join(progConfigDirPath, tt.want); got != want {
+			t.Errorf("%v: findProgConfigPath() = %q, want %q", tt.name, got, want)
+		}
+	}
+}
+
+func TestConfigFormatsRoundTrip(t *testing.T) {
+	jsonData := `{
+		"subreddits": ["buildapcsales", "hardwareswap"],
+		"pollInterval": {"base": "1m", "max": "10m", "multiplier": 2},
+		"matchMode": "all",
+		"rules": [
+			{"id": "ramunderprice", "configs": {"price": 100, "perSubreddit": {"buildapcsalescanada": 140}}},
+			{"id": "keywordmatch", "notifier": "discord", "configs": {"keywords": ["ram", "ddr5"]}}
+		]
+	}`
+	yamlData := `
+subreddits:
+  - buildapcsales
+  - hardwareswap
+pollInterval:
+  base: 1m
+  max: 10m
+  multiplier: 2
+matchMode: all
+rules:
+  - id: ramunderprice
+    configs:
+      price: 100
+      perSubreddit:
+        buildapcsalescanada: 140
+  - id: keywordmatch
+    notifier: discord
+    configs:
+      keywords: [ram, ddr5]
+`
+
+	var jsonCt, yamlCt configTree
+	if err := unmarshalConfigTree([]byte(jsonData), engine.JsonConfigFormat, &jsonCt); err != nil {
+		t.Fatalf("failed to unmarshal json: %v", err)
+	}
+	if err := unmarshalConfigTree([]byte(yamlData), engine.YamlConfigFormat, &yamlCt); err != nil {
+		t.Fatalf("failed to unmarshal yaml: %v", err)
+	}
+	if !reflect.DeepEqual(jsonCt, yamlCt) {
+		t.Fatalf("yaml configTree = %+v, want the json configTree %+v", yamlCt, jsonCt)
+	}
+
+	// the configTree survives being marshaled and unmarshaled in either format
+	for _, format := range []string{engine.JsonConfigFormat, engine.YamlConfigFormat} {
+		data, err := marshalConfigTree(&jsonCt, format)
+		if err != nil {
+			t.Fatalf("failed to marshal %v: %v", format, err)
+		}
+
+		var got configTree
+		if err := unmarshalConfigTree(data, format, &got); err != nil {
+			t.Errorf("failed to unmarshal marshaled %v: %v", format, err)
+		} else if !reflect.DeepEqual(got, jsonCt) {
+			t.Errorf("%v round trip = %+v, want %+v", format, got, jsonCt)
+		}
+	}
+}
+
+func TestExportConfigFormat(t *testing.T) {
+	tests := []struct {
+		name     string
+		contents string
+	}{
+		{"rsb.json", `{"subreddits": ["buildapcsales"], "rules": [{"id": "ramunderprice", "configs": {"price": 100}}]}`},
+		{"rsb.yaml", "subreddits: [buildapcsales]\nrules:\n  - id: ramunderprice\n    configs:\n      price: 100\n"},
+	}
+
+	for _, tt := range tests {
+		progConfigPath := writeTestConfig(t, tt.name, tt.contents)
+		output, err := runArgsOutput(t, "--config", progConfigPath, "--export-config")
+		if err != nil {
+			t.Errorf("export-config of %v returned an error: %v", tt.name, err)
+		} else if strings.TrimSpace(output) != strings.TrimSpace(tt.contents) {
+			t.Errorf("export-config of %v printed %q, want %q", tt.name, output, tt.contents)
+		}
+	}
+}
+
+func TestApplyEnvOverrides(t *testing.T) {
+	newCt := func() configTree {
+		var ct configTree
+		ct.Subreddits = []string{"buildapcsales"}
+		ct.RuleConfigs = []engine.RuleConfig{
+			{ID: "ramunderprice", Configs: map[string]interface{}{"price": 100.0}},
+			{ID: "deals", Op: "or", Rules: []engine.RuleConfig{
+				{ID: "keywordmatch", Configs: map[string]interface{}{"keywords": []interface{}{"ram"}}},
+				{ID: "ramunderprice", Configs: map[string]interface{}{"price": 80.0}},
+			}},
+		}
+		return ct
+	}
+
+	tests := []struct {
+		name           string
+		environ        []string
+		wantSubreddits []string
+		wantPrices     []interface{}
+		wantErr        bool
+	}{
+		{"no overrides", []string{"HOME=/root"}, []string{"buildapcsales"}, []interface{}{100.0, 80.0}, false},
+		{"subreddits", []string{"RSB_SUBREDDITS=hardwareswap, buildapcsalescanada,"}, []string{"hardwareswap", "buildapcsalescanada"}, []interface{}{100.0, 80.0}, false},
+		{"rule config", []string{"RSB_RULE_RAMUNDERPRICE_PRICE=150"}, []string{"buildapcsales"}, []interface{}{150.0, 150.0}, false},
+		{"rule config as a string", []string{`RSB_RULE_RAMUNDERPRICE_PRICE=€150`}, []string{"buildapcsales"}, []interface{}{"€150", "€150"}, false},
+		{"rule config with underscores", []string{"RSB_RULE_RAMUNDERPRICE_MAX_REALISTIC_PRICE=1500"}, []string{"buildapcsales"}, []interface{}{100.0, 80.0}, false},
+		{"unknown rule", []string{"RSB_RULE_NOTARULE_PRICE=150"}, nil, nil, true},
+		{"unknown config", []string{"RSB_RULE_RAMUNDERPRICE_COLOR=red"}, nil, nil, true},
+		{"rule not configured", []string{"RSB_RULE_GPUUNDERPRICE_PRICE=300"}, nil, nil, true},
+	}
+
+	for _, tt := range tests {
+		ct := newCt()
+		err := applyEnvOverrides(&ct, tt.environ)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%v: applyEnvOverrides() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		} else if err != nil {
+			continue
+		}
+
+		gotPrices := []interface{}{ct.RuleConfigs[0].Configs["price"], ct.RuleConfigs[1].Rules[1].Configs["price"]}
+		if !reflect.DeepEqual(ct.Subreddits, tt.wantSubreddits) {
+			t.Errorf("%v: subreddits = %v, want %v", tt.name, ct.Subreddits, tt.wantSubreddits)
+		}
+		if !reflect.DeepEqual(gotPrices, tt.wantPrices) {
+			t.Errorf("%v: prices = %v, want %v", tt.name, gotPrices, tt.wantPrices)
+		}
+	}
+}
+
+func TestEnvOverridesConfigFile(t *testing.T) {
+	progConfigPath := writeTestConfig(t, "rsb.json", `{
+		"subreddits": ["buildapcsales"],
+		"rules": [{"id": "ramunderprice", "configs": {"price": 100}}]
+	}`)
+	t.Setenv("RSB_SUBREDDITS", "hardwareswap")
+
+	output, err := runArgsOutput(t, "--config", progConfigPath, "--dry-run")
+	if err != nil {
+		t.Fatalf("run returned an error: %v", err)
+	}
+	if want := "subreddits:\n    hardwareswap\n"; !strings.HasSuffix(output, want) {
+		t.Errorf("run printed %q, want it to end with %q", output, want)
+	}
+}
+
+func TestUnmarshalConfigTreeUnknownKeys(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		format  string
+		wantErr string
+	}{
+		{"known keys", `{"subreddits": ["buildapcsales"], "rules": [{"id": "ramunderprice", "configs": {"price": 100}}]}`, engine.JsonConfigFormat, ""},
+		{"keys differing in case", `{"Subreddits": ["buildapcsales"]}`, engine.JsonConfigFormat, ""},
+		{"misspelled key", `{"subredits": ["buildapcsales"]}`, engine.JsonConfigFormat, "subredits"},
+		{"misspelled nested key", `{"pollInterval": {"bsae": "1m"}}`, engine.JsonConfigFormat, "pollInterval.bsae"},
+		{"misspelled rule key", `{"rules": [{"id": "ramunderprice", "config": {"price": 100}}]}`, engine.JsonConfigFormat, "rules[0].config"},
+		{"misspelled rule config", `{"rules": [{"id": "ramunderprice", "configs": {"prize": 100}}]}`, engine.JsonConfigFormat, "rules[0].configs.prize"},
+		{
+			"misspelled config of a composed rule",
+			`{"rules": [{"id": "deals", "op": "or", "rules": [{"id": "keywordmatch", "configs": {"keyword": ["ram"]}}]}]}`,
+			engine.JsonConfigFormat,
+			"rules[0].rules[0].configs.keyword",
+		},
+		{"misspelled yaml key", "subreddits: [buildapcsales]\nmatchmod: all\n", engine.YamlConfigFormat, "matchmod"},
+		{"configs of an unknown rule", `{"rules": [{"id": "notarule", "configs": {"price": 100}}]}`, engine.JsonConfigFormat, ""},
+	}
+
+	for _, tt := range tests {
+		var ct configTree
+		err := unmarshalConfigTree([]byte(tt.data), tt.format, &ct)
+		if tt.wantErr == "" && err != nil {
+			t.Errorf("%v: unmarshalConfigTree returned an error: %v", tt.name, err)
+		} else if tt.wantErr != "" && (err == nil || !strings.HasSuffix(err.Error(), "not known: "+tt.wantErr)) {
+			t.Errorf("%v: unmarshalConfigTree error = %v, want the unknown key %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestConfigTreeSubreddits(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   string
+		format string
+		want   []string
+	}{
+		{"json", `{"subreddits": ["buildapcsales", "hardwareswap"]}`, engine.JsonConfigFormat, []string{"buildapcsales", "hardwareswap"}},
+		{"yaml", "subreddits:\n  - buildapcsales\n  - hardwareswap\n", engine.YamlConfigFormat, []string{"buildapcsales", "hardwareswap"}},
+		{"empty", `{"subreddits": []}`, engine.JsonConfigFormat, []string{}},
+		{"missing", `{}`, engine.JsonConfigFormat, nil},
+	}
+
+	for _, tt := range tests {
+		var ct configTree
+		if err := unmarshalConfigTree([]byte(tt.data), tt.format, &ct); err != nil {
+			t.Errorf("%v: unmarshalConfigTree returned an error: %v", tt.name, err)
+		} else if !reflect.DeepEqual(ct.Subreddits, tt.want) {
+			t.Errorf("%v: subreddits = %#v, want %#v", tt.name, ct.Subreddits, tt.want)
+		}
+	}
+}
+
+func TestDefaultConfigSubreddits(t *testing.T) {
+	progConfigDirPath := t.TempDir()
+	if err := createDefaultProgConfig(progConfigDirPath, "rsb.json"); err != nil {
+		t.Fatalf("failed to create the default configuration file: %v", err)
+	}
+
+	ct, err := loadProgConfig(filepath.Join(progConfigDirPath, "rsb.json"))
+	if err != nil {
+		t.Fatalf("failed to load the default configuration file: %v", err)
+	}
+	if !reflect.DeepEqual(ct.Subreddits, defaultSubreddits) {
+		t.Errorf("default subreddits = %v, want %v", ct.Subreddits, defaultSubreddits)
+	}
+}
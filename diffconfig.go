@@ -0,0 +1,131 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/cavcrosby/rsb/engine"
+)
+
+// Format a configuration value for display.
+func formatConfigValue(value interface{}, ok bool) string {
+	if !ok {
+		return "(unset)"
+	}
+
+	valueBytes, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+
+	return string(valueBytes)
+}
+
+// Get the rule configs keyed by rule name (the rule ID unless a name is set). The
+// last config of a rule configured more than once under the same name wins.
+func ruleConfigsByName(rcs []engine.RuleConfig) map[string]engine.RuleConfig {
+	rcsByName := make(map[string]engine.RuleConfig)
+	for _, rc := range rcs {
+		rcsByName[rc.RuleName()] = rc
+	}
+
+	return rcsByName
+}
+
+// Get the sorted keys of the maps passed in, without duplicates.
+func sortedKeys(maps ...map[string]interface{}) []string {
+	keySet := make(map[string]bool)
+	for _, m := range maps {
+		for key := range m {
+			keySet[key] = true
+		}
+	}
+
+	var keys []string
+	for key := range keySet {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Compare the rules of two configTrees, describing the rules added (+), removed
+// (-) and changed (~) going from the old to the new configTree along with the
+// configurations that changed.
+func diffConfigTrees(oldCt, newCt configTree) []string {
+	oldRcs := ruleConfigsByName(oldCt.RuleConfigs)
+	newRcs := ruleConfigsByName(newCt.RuleConfigs)
+	names := make(map[string]interface{})
+	for name := range oldRcs {
+		names[name] = nil
+	}
+	for name := range newRcs {
+		names[name] = nil
+	}
+
+	var lines []string
+	for _, name := range sortedKeys(names) {
+		oldRc, inOld := oldRcs[name]
+		newRc, inNew := newRcs[name]
+		switch {
+		case !inOld:
+			lines = append(lines, fmt.Sprintf("+ rule %v %v", name, formatConfigValue(newRc.Configs, true)))
+		case !inNew:
+			lines = append(lines, fmt.Sprintf("- rule %v", name))
+		default:
+			var deltas []string
+			if oldRc.ID != newRc.ID {
+				deltas = append(deltas, fmt.Sprintf("    id: %q -> %q", oldRc.ID, newRc.ID))
+			}
+			if oldRc.Notifier != newRc.Notifier {
+				deltas = append(deltas, fmt.Sprintf("    notifier: %q -> %q", oldRc.Notifier, newRc.Notifier))
+			}
+			if oldRc.Disabled() != newRc.Disabled() {
+				deltas = append(deltas, fmt.Sprintf("    enabled: %v -> %v", !oldRc.Disabled(), !newRc.Disabled()))
+			}
+			if oldRc.Negate != newRc.Negate {
+				deltas = append(deltas, fmt.Sprintf("    negate: %v -> %v", oldRc.Negate, newRc.Negate))
+			}
+			for _, key := range sortedKeys(oldRc.Configs, newRc.Configs) {
+				oldValue, oldOk := oldRc.Configs[key]
+				newValue, newOk := newRc.Configs[key]
+				if oldOk != newOk || !reflect.DeepEqual(oldValue, newValue) {
+					deltas = append(deltas, fmt.Sprintf(
+						"    %v: %v -> %v",
+						key,
+						formatConfigValue(oldValue, oldOk),
+						formatConfigValue(newValue, newOk),
+					))
+				}
+			}
+
+			if len(deltas) > 0 {
+				lines = append(lines, fmt.Sprintf("~ rule %v", name))
+				lines = append(lines, deltas...)
+			}
+		}
+	}
+
+	return lines
+}
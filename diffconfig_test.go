@@ -0,0 +1,98 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/cavcrosby/rsb/engine"
+)
+
+func TestDiffConfigTrees(t *testing.T) {
+	disabled := false
+	ramRule := engine.RuleConfig{ID: "ramunderprice", Configs: map[string]interface{}{"price": 100.0}}
+
+	tests := []struct {
+		name   string
+		oldRcs []engine.RuleConfig
+		newRcs []engine.RuleConfig
+		want   []string
+	}{
+		{"unchanged", []engine.RuleConfig{ramRule}, []engine.RuleConfig{ramRule}, nil},
+		{
+			"threshold changed",
+			[]engine.RuleConfig{ramRule},
+			[]engine.RuleConfig{{ID: "ramunderprice", Configs: map[string]interface{}{"price": 80.0}}},
+			[]string{"~ rule ramunderprice", "    price: 100 -> 80"},
+		},
+		{
+			"config added",
+			[]engine.RuleConfig{ramRule},
+			[]engine.RuleConfig{{ID: "ramunderprice", Configs: map[string]interface{}{"price": 100.0, "maxRealisticPrice": 1500.0}}},
+			[]string{"~ rule ramunderprice", "    maxRealisticPrice: (unset) -> 1500"},
+		},
+		{
+			"rule disabled and notifier changed",
+			[]engine.RuleConfig{ramRule},
+			[]engine.RuleConfig{{ID: "ramunderprice", Notifier: "discord", Configs: ramRule.Configs, Enabled: &disabled}},
+			[]string{"~ rule ramunderprice", `    notifier: "" -> "discord"`, "    enabled: true -> false"},
+		},
+		{
+			"rules added and removed",
+			[]engine.RuleConfig{ramRule},
+			[]engine.RuleConfig{{ID: "keywordmatch", Configs: map[string]interface{}{"keywords": []interface{}{"ram"}}}},
+			[]string{`+ rule keywordmatch {"keywords":["ram"]}`, "- rule ramunderprice"},
+		},
+	}
+
+	for _, tt := range tests {
+		var oldCt, newCt configTree
+		oldCt.RuleConfigs, newCt.RuleConfigs = tt.oldRcs, tt.newRcs
+		got := diffConfigTrees(oldCt, newCt)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%v: diffConfigTrees() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestDiffConfigCommand(t *testing.T) {
+	oldConfigPath := writeTestConfig(t, "old.json", `{
+		"subreddits": ["buildapcsales"],
+		"rules": [{"id": "ramunderprice", "configs": {"price": 100}}]
+	}`)
+	newConfigPath := writeTestConfig(t, "new.yaml", `
+subreddits: [buildapcsales]
+rules:
+  - id: ramunderprice
+    configs:
+      price: 80
+`)
+
+	output, err := runArgsOutput(t, "diff-config", oldConfigPath, newConfigPath)
+	if err != nil {
+		t.Fatalf("diff-config returned an error: %v", err)
+	}
+
+	want := "~ rule ramunderprice\n    price: 100 -> 80\n"
+	if output != want {
+		t.Errorf("diff-config printed %q, want %q", output, want)
+	}
+}
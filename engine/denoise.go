@@ -0,0 +1,58 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package engine
+
+import (
+	"strings"
+	"unicode"
+)
+
+var (
+	markdownReplacer = strings.NewReplacer("**", "", "__", "", "~~", "", "*", "", "`", "")
+)
+
+// Determine if the rune is part of an emoji (including the modifiers and joiners
+// used to build up emoji sequences).
+func isEmoji(r rune) bool {
+	switch {
+	case r == '\u200d', r == '\u20e3':
+		return true
+	case r >= '\ufe00' && r <= '\ufe0f':
+		return true
+	case r >= 0x1f3fb && r <= 0x1f3ff:
+		return true
+	}
+
+	return unicode.Is(unicode.So, r)
+}
+
+// Strip emoji and markdown formatting from a title so it can be matched against
+// rules (e.g. "🔥 **RAM** $80 🔥" becomes "RAM $80").
+func denoiseTitle(title string) string {
+	title = strings.Map(func(r rune) rune {
+		if isEmoji(r) {
+			return ' '
+		}
+		return r
+	}, title)
+	title = markdownReplacer.Replace(title)
+
+	return strings.Join(strings.Fields(title), " ")
+}
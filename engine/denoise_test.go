@@ -0,0 +1,93 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package engine
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cavcrosby/rsb/rule"
+	"github.com/turnage/graw/reddit"
+)
+
+// A type that represents a rule matching posts whose title contains the text.
+type titleContainsRule struct {
+	text string
+}
+
+func (tc *titleContainsRule) Name() string {
+	return "titlecontains"
+}
+
+func (tc *titleContainsRule) Description() string {
+	return "matches posts whose title contains the text"
+}
+
+func (tc *titleContainsRule) RegisterConfigs(configs []byte) error {
+	return nil
+}
+
+func (tc *titleContainsRule) Match(post *reddit.Post) bool {
+	return strings.Contains(post.Title, tc.text)
+}
+
+func TestDenoiseTitle(t *testing.T) {
+	tests := []struct {
+		title string
+		want  string
+	}{
+		{"🔥 **RAM** $80 🔥", "RAM $80"},
+		{"[RAM] __Corsair__ ~~$99~~ `$80`", "[RAM] Corsair $99 $80"},
+		{"👍🏽 [GPU] RTX 4070 ❤️ $549", "[GPU] RTX 4070 $549"},
+		{"[RAM] Corsair 16GB $49.99", "[RAM] Corsair 16GB $49.99"},
+	}
+
+	for _, tt := range tests {
+		if got := denoiseTitle(tt.title); got != tt.want {
+			t.Errorf("denoiseTitle(%q) = %q, want %q", tt.title, got, tt.want)
+		}
+	}
+}
+
+func TestAppliedToDenoiseTitles(t *testing.T) {
+	rules := []rule.Rule{&titleContainsRule{text: "$80"}}
+	tests := []struct {
+		title   string
+		denoise bool
+		want    bool
+	}{
+		{"🔥 **RAM** $80 🔥", true, true},
+		{"🔥 **RAM** $80 🔥", false, true},
+		// the markdown splits the symbol from the amount
+		{"🔥 [RAM] 16GB DDR4 $**80** 🔥", true, true},
+		{"🔥 [RAM] 16GB DDR4 $**80** 🔥", false, false},
+	}
+
+	for _, tt := range tests {
+		post := &reddit.Post{ID: "noisy", Title: tt.title}
+		matches := NewHeuristic(rules).WithSettings(Settings{DenoiseTitles: tt.denoise}).AppliedTo([]*reddit.Post{post}, nil)
+		if got := len(matches) > 0; got != tt.want {
+			t.Errorf("AppliedTo(%q) with denoising %v matched = %v, want %v", tt.title, tt.denoise, got, tt.want)
+		} else if got && matches[0].Post.Title != tt.title {
+			// the title shown is the post's own, not the denoised title
+			t.Errorf("match title = %q, want %q", matches[0].Post.Title, tt.title)
+		}
+	}
+}
@@ -0,0 +1,424 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+// Package engine matches reddit posts against rules, independent of how the
+// posts are fetched or where matches are sent. The rsb program is built on top of
+// it, but other programs can embed it as well:
+//
+//	cfg, err := engine.LoadConfig("rsb.json")
+//	...
+//	rules, err := engine.BuildRules(cfg.RuleConfigs)
+//	...
+//	matches, err := engine.Match(cfg, rules, posts)
+//
+// Rules are looked up in the rule registry, so the rules used must be registered
+// (e.g. by importing the register package).
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/cavcrosby/rsb/logging"
+	"github.com/cavcrosby/rsb/rule"
+	"github.com/turnage/graw/reddit"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	JsonConfigFormat = "json"
+	YamlConfigFormat = "yaml"
+)
+
+var (
+	YamlConfigExts = []string{".yaml", ".yml"}
+)
+
+// A type used to represent the part of a configuration file that determines how
+// posts are matched, the rules and the settings used to match posts against them.
+// Programs embedding the engine can embed the type in their own configuration
+// (as rsb does) to add their own settings.
+type Config struct {
+	TrustedDomains  []string     `json:"trustedDomains"`
+	TrustBoost      int          `json:"trustBoost"`
+	CollapseReposts bool         `json:"collapseReposts"`
+	MatchMode       string       `json:"matchMode"`
+	DenoiseTitles   bool         `json:"denoiseTitles"`
+	Workers         int          `json:"workers"`
+	RuleConfigs     []RuleConfig `json:"rules"`
+}
+
+// A type used to serve as a frontend to allow certain rules to be selected
+// for use and to modify the rule's behavior to some extent through custom
+// configurations. This configuration is made available through Config.
+// Matches of the rule are sent to the named notifier (e.g. "email") if set,
+// otherwise to the default notifier.
+//
+// Rules can be composed by setting an op, "and" and "or" combining the rules
+// under "rules" and "not" negating the rule under "rule". The ID of a composed
+// rule is optional and only used to name the rule (e.g. for notifiers).
+//
+// Example ((cpuunderprice OR gpuunderprice) AND NOT authorblock):
+// {
+//     "id": "pcparts",
+//     "op": "and",
+//     "rules": [
+//         {
+//             "op": "or",
+//             "rules": [
+//                 {"id": "cpuunderprice", "configs": {"price": 150}},
+//                 {"id": "gpuunderprice", "configs": {"price": 500}}
+//             ]
+//         },
+//         {
+//             "op": "not",
+//             "rule": {"id": "keywordmatch", "configs": {"keywords": ["refurbished"]}}
+//         }
+//     ]
+// }
+//
+// Any rule can also be negated by setting negate, the rule then matching the
+// posts it would otherwise not match (the same as the "not" op), e.g.
+// {"id": "keywordmatch", "negate": true, "configs": {"keywords": ["refurbished"]}}.
+//
+// Each RuleConfig builds its own rule, so the same rule can be configured more
+// than once. Setting name tells the rules apart (e.g. in matches and when routing
+// matches to notifiers), e.g.
+// {"id": "ramunderprice", "name": "ram-cheap", "configs": {"price": 40}}.
+//
+// Rules are enabled unless enabled is set to false, letting a rule be turned off
+// without losing its configs. Disabled rules are skipped when building the rules,
+// including those composed by an op.
+type RuleConfig struct {
+	ID       string                 `json:"id"`
+	Name     string                 `json:"name,omitempty"`
+	Notifier string                 `json:"notifier"`
+	Configs  map[string]interface{} `json:"configs"`
+	Enabled  *bool                  `json:"enabled,omitempty"`
+	Negate   bool                   `json:"negate,omitempty"`
+	Op       string                 `json:"op,omitempty"`
+	Rules    []RuleConfig           `json:"rules,omitempty"`
+	Rule     *RuleConfig            `json:"rule,omitempty"`
+}
+
+// Determine if the RuleConfig is blank, this being the case for the rule of the
+// default configuration file.
+func (rc RuleConfig) Blank() bool {
+	return strings.TrimSpace(rc.ID) == "" && rc.Op == ""
+}
+
+// Get the name of the rule the RuleConfig builds, this being the RuleConfig's
+// name if set and its id otherwise.
+func (rc RuleConfig) RuleName() string {
+	if rc.Name != "" {
+		return rc.Name
+	}
+
+	return rc.ID
+}
+
+// Determine if the RuleConfig is disabled, RuleConfigs being enabled unless
+// enabled is set to false.
+func (rc RuleConfig) Disabled() bool {
+	return rc.Enabled != nil && !*rc.Enabled
+}
+
+// Get the configs of the RuleConfig as JSON to register with its rule. A
+// RuleConfig without configs gives an empty object, so rules still reject the
+// configs they require being missing.
+func (rc RuleConfig) ConfigsJson() ([]byte, error) {
+	if rc.Configs == nil {
+		return []byte("{}"), nil
+	}
+
+	return json.Marshal(rc.Configs)
+}
+
+// Get the format of a configuration file from its file extension. Files without
+// a YAML extension are treated as JSON.
+func ConfigFormat(configPath string) string {
+	ext := strings.ToLower(filepath.Ext(configPath))
+	for _, yamlExt := range YamlConfigExts {
+		if ext == yamlExt {
+			return YamlConfigFormat
+		}
+	}
+
+	return JsonConfigFormat
+}
+
+// Convert the maps of a decoded YAML document so their keys are strings,
+// allowing the document to be encoded as JSON.
+func yamlToJsonValue(v interface{}) (interface{}, error) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, val := range v {
+			conv, err := yamlToJsonValue(val)
+			if err != nil {
+				return nil, err
+			}
+			v[key] = conv
+		}
+		return v, nil
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, val := range v {
+			skey, ok := key.(string)
+			if !ok {
+				return nil, fmt.Errorf("unsupported non-string key %v", key)
+			}
+			conv, err := yamlToJsonValue(val)
+			if err != nil {
+				return nil, err
+			}
+			m[skey] = conv
+		}
+		return m, nil
+	case []interface{}:
+		s := make([]interface{}, len(v))
+		for i, val := range v {
+			conv, err := yamlToJsonValue(val)
+			if err != nil {
+				return nil, err
+			}
+			s[i] = conv
+		}
+		return s, nil
+	default:
+		return v, nil
+	}
+}
+
+// Decode the bytes of a configuration file in the given format into a JSON
+// document (e.g. map[string]interface{}). YAML is decoded by way of JSON so both
+// formats decode the same way (e.g. numbers are always float64).
+func DecodeConfig(data []byte, format string) (interface{}, error) {
+	jsonBytes := data
+	if format == YamlConfigFormat {
+		var yamlDoc interface{}
+		if err := yaml.Unmarshal(data, &yamlDoc); err != nil {
+			return nil, err
+		}
+
+		yamlDoc, err := yamlToJsonValue(yamlDoc)
+		if err != nil {
+			return nil, err
+		}
+
+		if jsonBytes, err = json.Marshal(yamlDoc); err != nil {
+			return nil, err
+		}
+	}
+
+	var doc interface{}
+	if err := json.Unmarshal(jsonBytes, &doc); err != nil {
+		return nil, err
+	}
+
+	return doc, nil
+}
+
+// Read and parse a configuration file, either JSON or YAML depending on the file
+// extension. Keys the engine does not use (e.g. the notifier settings of rsb) are
+// ignored, so the configuration file of rsb can be loaded as is.
+func LoadConfig(configPath string) (Config, error) {
+	var cfg Config
+	data, err := ioutil.ReadFile(configPath)
+	if err != nil {
+		return cfg, err
+	}
+
+	doc, err := DecodeConfig(data, ConfigFormat(configPath))
+	if err != nil || doc == nil {
+		return cfg, err
+	}
+
+	jsonBytes, err := json.Marshal(doc)
+	if err != nil {
+		return cfg, err
+	}
+	if err := json.Unmarshal(jsonBytes, &cfg); err != nil {
+		return cfg, fmt.Errorf("failed to parse %v: %v", configPath, err)
+	}
+
+	return cfg, nil
+}
+
+// Determine if a post must match all rules (vs any rule) from the match mode. The
+// match mode is either "any" (the default) or "all", the latter being useful when
+// combining rules that exclude posts (e.g. externalonly) with other rules.
+func MatchAllRules(matchMode string) (bool, error) {
+	switch matchMode {
+	case "", "any":
+		return false, nil
+	case "all":
+		return true, nil
+	default:
+		return false, fmt.Errorf("the following match mode is not known: %v", matchMode)
+	}
+}
+
+// Build the rules mentioned in the RuleConfigs, registering additional custom
+// configurations for each rule if specified. Configurations are specific to each
+// rule, meaning one configuration in one rule may not work in other rule.
+// RuleConfigs without an ID (e.g. from the default configuration file) and
+// disabled RuleConfigs are skipped.
+func BuildRules(rcs []RuleConfig) ([]rule.Rule, error) {
+	var rules []rule.Rule
+	for _, rc := range rcs {
+		if rc.Blank() {
+			logging.Warnf("skipping a rule without an id")
+			continue
+		} else if rc.Disabled() {
+			logging.Debugf("skipping the disabled rule %v", rc.ID)
+			continue
+		}
+
+		r, err := getRule(rc)
+		if err != nil {
+			return rules, err
+		}
+		rules = append(rules, r)
+	}
+
+	return rules, nil
+}
+
+// Get the RuleConfigs BuildRules builds rules from, in the order of the rules
+// built. RuleConfigs without an ID and disabled RuleConfigs are left out.
+func BuiltRuleConfigs(rcs []RuleConfig) []RuleConfig {
+	var built []RuleConfig
+	for _, rc := range rcs {
+		if !rc.Blank() && !rc.Disabled() {
+			built = append(built, rc)
+		}
+	}
+
+	return built
+}
+
+// Determine if any of the rules uses the post history (e.g. to compare posts with
+// when they were first seen).
+func UsesHistory(rules []rule.Rule) bool {
+	for _, r := range rules {
+		if rule.UsesHistory(r) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Retrieve the rule mentioned in the RuleConfig, composing the rules of the
+// RuleConfig if it has an op. The rule is wrapped in a not rule if the
+// RuleConfig is negated, the not rule keeping the RuleConfig's rule name.
+func getRule(rc RuleConfig) (rule.Rule, error) {
+	r, err := getBaseRule(rc)
+	if err != nil {
+		return nil, err
+	} else if rc.Negate {
+		return rule.NewNotRule(rc.RuleName(), r), nil
+	}
+
+	return r, nil
+}
+
+// Retrieve the rule mentioned in the RuleConfig, not taking into account whether
+// the RuleConfig is negated.
+func getBaseRule(rc RuleConfig) (rule.Rule, error) {
+	switch rc.Op {
+	case "":
+	case rule.AndOp, rule.OrOp:
+		if len(rc.Rules) == 0 {
+			return nil, fmt.Errorf("the %v rule %v has no rules", rc.Op, rc.ID)
+		}
+
+		var rules []rule.Rule
+		for _, childRc := range rc.Rules {
+			if childRc.Disabled() {
+				continue
+			}
+
+			r, err := getRule(childRc)
+			if err != nil {
+				return nil, err
+			}
+			rules = append(rules, r)
+		}
+
+		if len(rules) == 0 {
+			return nil, fmt.Errorf("the %v rule %v has no enabled rules", rc.Op, rc.ID)
+		} else if rc.Op == rule.AndOp {
+			return rule.NewAndRule(rc.RuleName(), rules), nil
+		}
+		return rule.NewOrRule(rc.RuleName(), rules), nil
+	case rule.NotOp:
+		if rc.Rule == nil {
+			return nil, fmt.Errorf("the not rule %v has no rule", rc.ID)
+		} else if rc.Rule.Disabled() {
+			return nil, fmt.Errorf("the not rule %v has no enabled rule", rc.ID)
+		}
+
+		r, err := getRule(*rc.Rule)
+		if err != nil {
+			return nil, err
+		}
+		return rule.NewNotRule(rc.RuleName(), r), nil
+	default:
+		return nil, fmt.Errorf("the following rule op is not known: %v", rc.Op)
+	}
+
+	// each RuleConfig gets a new instance of the rule from the registry, so
+	// RuleConfigs of the same rule do not interfere with each other
+	r, err := rule.RuleInRuleRegistry(rc.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if configsData, err := rc.ConfigsJson(); err != nil {
+		return nil, err
+	} else if err := r.RegisterConfigs(configsData); err != nil {
+		return nil, err
+	}
+
+	if rc.Name != "" && !rc.Negate {
+		return rule.NewNamedRule(rc.Name, r), nil
+	}
+	return r, nil
+}
+
+// Match the posts against the rules, using the settings of the configuration
+// (e.g. the match mode). The matches are sorted from the highest to the lowest
+// score, reposts being collapsed into a single match if configured.
+func Match(cfg Config, rules []rule.Rule, posts []*reddit.Post) ([]*PostMatch, error) {
+	settings, err := NewSettings(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	matches := NewHeuristic(rules).WithSettings(settings).AppliedTo(posts, nil)
+	if cfg.CollapseReposts {
+		matches = CollapseReposts(matches)
+	}
+
+	return matches, nil
+}
@@ -0,0 +1,372 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package engine
+
+import (
+	"reflect"
+	"testing"
+
+	_ "github.com/cavcrosby/rsb/register"
+	"github.com/turnage/graw/reddit"
+)
+
+func TestMatchAllRules(t *testing.T) {
+	tests := []struct {
+		matchMode string
+		want      bool
+		wantErr   bool
+	}{
+		{"", false, false},
+		{"any", false, false},
+		{"all", true, false},
+		{"most", false, true},
+	}
+
+	for _, tt := range tests {
+		got, err := MatchAllRules(tt.matchMode)
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("MatchAllRules(%q) = %v, %v, want %v, error %v", tt.matchMode, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestBuildRulesRegistersConfigs(t *testing.T) {
+	tests := []struct {
+		name    string
+		rc      RuleConfig
+		wantErr bool
+	}{
+		{"price rule without configs", RuleConfig{ID: "ramunderprice"}, true},
+		{"price rule with empty configs", RuleConfig{ID: "ramunderprice", Configs: map[string]interface{}{}}, true},
+		{"price rule with a price", RuleConfig{ID: "ramunderprice", Configs: map[string]interface{}{"price": 100}}, false},
+		{"regexmatch without a pattern", RuleConfig{ID: "regexmatch"}, true},
+		{"rule without required configs", RuleConfig{ID: "ramunder100"}, false},
+		{"rule rejecting configs", RuleConfig{ID: "ramunder100", Configs: map[string]interface{}{"price": 40}}, true},
+		{"composed rule without configs", RuleConfig{Op: "not", Rule: &RuleConfig{ID: "cpuunderprice"}}, true},
+	}
+
+	for _, tt := range tests {
+		_, err := BuildRules([]RuleConfig{tt.rc})
+		if gotErr := err != nil; gotErr != tt.wantErr {
+			t.Errorf("%v: BuildRules returned error %v, want error %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestBuildRulesComposition(t *testing.T) {
+	// (A OR B) AND NOT C, where A is RAM, B is an SSD and C is a refurbished part
+	rules, err := BuildRules([]RuleConfig{
+		{
+			ID: "deals",
+			Op: "and",
+			Rules: []RuleConfig{
+				{ID: "either", Op: "or", Rules: []RuleConfig{
+					{ID: "keywordmatch", Configs: map[string]interface{}{"keywords": []interface{}{"ram"}}},
+					{ID: "regexmatch", Configs: map[string]interface{}{"pattern": `(?i)\bssd\b`}},
+				}},
+				{ID: "notrefurbished", Op: "not", Rule: &RuleConfig{
+					ID: "flairmatch", Configs: map[string]interface{}{"flairs": []interface{}{"refurbished"}},
+				}},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("BuildRules returned an error: %v", err)
+	}
+	if len(rules) != 1 {
+		t.Fatalf("BuildRules returned %v rules, want 1", len(rules))
+	}
+
+	tests := []struct {
+		title string
+		flair string
+		want  bool
+	}{
+		{"[RAM] Corsair Vengeance 16GB $49.99", "", true},
+		{"[SSD] WD Black SN850X 2TB $129.99", "", true},
+		{"[RAM] Corsair Vengeance 16GB $39.99", "Refurbished", false},
+		{"[SSD] WD Black SN850X 2TB $99.99", "Refurbished", false},
+		{"[GPU] RTX 4070 $549.99", "", false},
+		{"[GPU] RTX 4070 $449.99", "Refurbished", false},
+	}
+
+	for _, tt := range tests {
+		if got := rules[0].Match(&reddit.Post{Title: tt.title, LinkFlairText: tt.flair}); got != tt.want {
+			t.Errorf("Match(%q with flair %q) = %v, want %v", tt.title, tt.flair, got, tt.want)
+		}
+	}
+}
+
+func TestMatch(t *testing.T) {
+	rules, err := BuildRules([]RuleConfig{
+		{ID: "keywordmatch", Configs: map[string]interface{}{"keywords": []interface{}{"ram"}}},
+		{ID: "ramunderprice", Configs: map[string]interface{}{"price": 100.0}},
+	})
+	if err != nil {
+		t.Fatalf("BuildRules returned an error: %v", err)
+	}
+	posts := []*reddit.Post{
+		{ID: "pricy", Title: "[RAM] G.Skill Trident Z5 64GB DDR5 $189.99"},
+		{ID: "cheap", Title: "[RAM] Corsair Vengeance 16GB DDR4 $49.99"},
+		{ID: "gpu", Title: "[GPU] RTX 4070 $549.99"},
+		{ID: "repost", Title: "[ram] corsair vengeance 16gb ddr4 $49.99"},
+	}
+
+	tests := []struct {
+		name    string
+		cfg     Config
+		wantIDs []string
+		wantErr bool
+	}{
+		{"any rule", Config{}, []string{"cheap", "repost", "pricy"}, false},
+		{"all rules", Config{MatchMode: "all"}, []string{"cheap", "repost"}, false},
+		{"reposts collapsed", Config{MatchMode: "all", CollapseReposts: true}, []string{"cheap"}, false},
+		{"unknown match mode", Config{MatchMode: "most"}, nil, true},
+	}
+
+	for _, tt := range tests {
+		matches, err := Match(tt.cfg, rules, posts)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%v: Match() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+
+		var gotIDs []string
+		for _, match := range matches {
+			gotIDs = append(gotIDs, match.Post.ID)
+		}
+		if !reflect.DeepEqual(gotIDs, tt.wantIDs) {
+			t.Errorf("%v: Match() matched %v, want %v", tt.name, gotIDs, tt.wantIDs)
+		}
+	}
+}
+
+func TestBuildRulesNegate(t *testing.T) {
+	keywords := map[string]interface{}{"keywords": []interface{}{"refurbished"}}
+	tests := []struct {
+		name string
+		rc   RuleConfig
+	}{
+		{"keyword rule", RuleConfig{ID: "keywordmatch", Configs: keywords}},
+		{"price rule", RuleConfig{ID: "ramunderprice", Configs: map[string]interface{}{"price": 50}}},
+		{
+			"composed rule",
+			RuleConfig{ID: "deals", Op: "or", Rules: []RuleConfig{
+				{ID: "keywordmatch", Configs: keywords},
+				{ID: "gpuunderprice", Configs: map[string]interface{}{"price": 500}},
+			}},
+		},
+	}
+	titles := []string{
+		"[RAM] Corsair Vengeance 16GB $49.99",
+		"[RAM] Corsair Vengeance 16GB refurbished $39.99",
+		"[RAM] Corsair Vengeance 32GB $89.99",
+		"[GPU] RTX 4070 $549.99",
+		"[GPU] RTX 4060 refurbished $249.99",
+		"[Monitor] Dell S2721DGF",
+	}
+
+	for _, tt := range tests {
+		negated := tt.rc
+		negated.Negate = true
+		rules, err := BuildRules([]RuleConfig{tt.rc, negated})
+		if err != nil {
+			t.Fatalf("%v: BuildRules returned an error: %v", tt.name, err)
+		}
+		base, not := rules[0], rules[1]
+
+		if not.Name() != base.Name() {
+			t.Errorf("%v: negated rule is named %v, want %v", tt.name, not.Name(), base.Name())
+		}
+		for _, title := range titles {
+			post := &reddit.Post{Title: title}
+			if got, want := not.Match(post), !base.Match(post); got != want {
+				t.Errorf("%v: negated Match(%q) = %v, want %v", tt.name, title, got, want)
+			}
+		}
+	}
+}
+
+func TestBuildRulesEnabled(t *testing.T) {
+	enabled, disabled := true, false
+	keywords := func(keywords ...interface{}) map[string]interface{} {
+		return map[string]interface{}{"keywords": keywords}
+	}
+
+	tests := []struct {
+		name      string
+		rcs       []RuleConfig
+		wantNames []string
+	}{
+		{
+			"enabled by default",
+			[]RuleConfig{{ID: "keywordmatch", Configs: keywords("ram")}},
+			[]string{"keywordmatch"},
+		},
+		{
+			"mix of enabled and disabled rules",
+			[]RuleConfig{
+				{ID: "keywordmatch", Name: "ram", Configs: keywords("ram")},
+				{ID: "keywordmatch", Name: "ssd", Enabled: &disabled, Configs: keywords("ssd")},
+				{ID: "keywordmatch", Name: "gpu", Enabled: &enabled, Configs: keywords("gpu")},
+				{ID: "ramunderprice", Enabled: &disabled, Configs: map[string]interface{}{"price": 100}},
+			},
+			[]string{"ram", "gpu"},
+		},
+		{
+			"disabled rules are not built",
+			[]RuleConfig{
+				{ID: "keywordmatch", Configs: keywords("ram")},
+				{ID: "regexmatch", Enabled: &disabled},
+				{ID: "notarule", Enabled: &disabled},
+			},
+			[]string{"keywordmatch"},
+		},
+		{
+			"every rule disabled",
+			[]RuleConfig{{ID: "keywordmatch", Enabled: &disabled, Configs: keywords("ram")}},
+			nil,
+		},
+		{
+			"disabled rules of a composed rule",
+			[]RuleConfig{{ID: "deals", Op: "or", Rules: []RuleConfig{
+				{ID: "keywordmatch", Configs: keywords("ram")},
+				{ID: "keywordmatch", Enabled: &disabled, Configs: keywords("ssd")},
+			}}},
+			[]string{"deals"},
+		},
+		{
+			"disabled composed rule",
+			[]RuleConfig{{ID: "deals", Op: "and", Enabled: &disabled}},
+			nil,
+		},
+	}
+
+	for _, tt := range tests {
+		rules, err := BuildRules(tt.rcs)
+		if err != nil {
+			t.Fatalf("%v: BuildRules returned an error: %v", tt.name, err)
+		}
+
+		var gotNames []string
+		for _, r := range rules {
+			gotNames = append(gotNames, r.Name())
+		}
+		if !reflect.DeepEqual(gotNames, tt.wantNames) {
+			t.Errorf("%v: BuildRules built %v, want %v", tt.name, gotNames, tt.wantNames)
+		}
+	}
+}
+
+func TestBuildRulesDisabledChildNotMatched(t *testing.T) {
+	disabled := false
+	rules, err := BuildRules([]RuleConfig{{ID: "deals", Op: "or", Rules: []RuleConfig{
+		{ID: "keywordmatch", Configs: map[string]interface{}{"keywords": []interface{}{"ram"}}},
+		{ID: "keywordmatch", Enabled: &disabled, Configs: map[string]interface{}{"keywords": []interface{}{"ssd"}}},
+	}}})
+	if err != nil {
+		t.Fatalf("BuildRules returned an error: %v", err)
+	}
+
+	tests := []struct {
+		title string
+		want  bool
+	}{
+		{"[RAM] Corsair Vengeance 16GB $49.99", true},
+		{"[SSD] WD Black SN850X 2TB $129.99", false},
+	}
+
+	for _, tt := range tests {
+		if got := rules[0].Match(&reddit.Post{Title: tt.title}); got != tt.want {
+			t.Errorf("Match(%q) = %v, want %v", tt.title, got, tt.want)
+		}
+	}
+}
+
+func TestBuildRulesNamedInstances(t *testing.T) {
+	rules, err := BuildRules([]RuleConfig{
+		{ID: "ramunderprice", Name: "ram-cheap", Configs: map[string]interface{}{"price": 40}},
+		{ID: "ramunderprice", Name: "ram-budget", Configs: map[string]interface{}{"price": 100}},
+	})
+	if err != nil {
+		t.Fatalf("BuildRules returned an error: %v", err)
+	}
+
+	var gotNames []string
+	for _, r := range rules {
+		gotNames = append(gotNames, r.Name())
+	}
+	if wantNames := []string{"ram-cheap", "ram-budget"}; !reflect.DeepEqual(gotNames, wantNames) {
+		t.Fatalf("BuildRules built %v, want %v", gotNames, wantNames)
+	}
+
+	tests := []struct {
+		title string
+		want  []bool
+	}{
+		{"[RAM] Corsair Vengeance LPX 16GB DDR4 3200 - $34.99", []bool{true, true}},
+		{"[RAM] Corsair Vengeance LPX 16GB DDR4 3200 - $49.99", []bool{false, true}},
+		{"[RAM] G.Skill Trident Z5 64GB DDR5-6000 $189.99", []bool{false, false}},
+	}
+
+	for _, tt := range tests {
+		for i, r := range rules {
+			if got := r.Match(&reddit.Post{Title: tt.title}); got != tt.want[i] {
+				t.Errorf("%v: Match(%q) = %v, want %v", r.Name(), tt.title, got, tt.want[i])
+			}
+		}
+	}
+}
+
+func TestBuildRulesIndependentInstances(t *testing.T) {
+	keywords := func(keywords ...interface{}) map[string]interface{} {
+		return map[string]interface{}{"keywords": keywords}
+	}
+
+	rules, err := BuildRules([]RuleConfig{
+		{ID: "keywordmatch", Configs: keywords("ram")},
+		{ID: "keywordmatch", Configs: keywords("ssd")},
+	})
+	if err != nil {
+		t.Fatalf("BuildRules returned an error: %v", err)
+	}
+
+	// configuring the same rule again must not change the rules already built
+	if _, err := BuildRules([]RuleConfig{{ID: "keywordmatch", Configs: keywords("gpu")}}); err != nil {
+		t.Fatalf("BuildRules returned an error: %v", err)
+	}
+
+	tests := []struct {
+		title string
+		want  []bool
+	}{
+		{"[RAM] Corsair Vengeance 16GB $49.99", []bool{true, false}},
+		{"[SSD] WD Black SN850X 2TB $129.99", []bool{false, true}},
+		{"[GPU] RTX 4070 $549.99", []bool{false, false}},
+	}
+
+	for _, tt := range tests {
+		for i, r := range rules {
+			if got := r.Match(&reddit.Post{Title: tt.title}); got != tt.want[i] {
+				t.Errorf("rule %v: Match(%q) = %v, want %v", i, tt.title, got, tt.want[i])
+			}
+		}
+	}
+}
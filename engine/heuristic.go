@@ -0,0 +1,312 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package engine
+
+import (
+	"errors"
+	"net/url"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/cavcrosby/rsb/logging"
+	"github.com/cavcrosby/rsb/rule"
+	"github.com/turnage/graw/reddit"
+)
+
+// A type that represents a reddit post that matched one or more rules. The
+// score is the aggregate used to rank matches against each other, and the count
+// is the number of times the same post was seen (e.g. reposts). The reasons are
+// why each matched rule matched (e.g. "ramunderprice: $59.99 <= $100"), for the
+// rules that can explain themselves.
+type PostMatch struct {
+	Post    *reddit.Post
+	Rules   []string
+	Reasons []string
+	Score   int
+	Count   int
+}
+
+// A type used to store the settings that influence how posts are matched and how
+// matches are scored. Posts linking to a trusted domain get a boost to their
+// score. Every post is traced (e.g. for debugging rules) if a tracer is set.
+type Settings struct {
+	MatchAll       bool
+	DenoiseTitles  bool
+	TrustedDomains []string
+	TrustBoost     int
+	Tracer         *Tracer
+	Workers        int
+}
+
+// Create the match settings from the configuration.
+func NewSettings(cfg Config) (Settings, error) {
+	matchAll, err := MatchAllRules(cfg.MatchMode)
+	if err != nil {
+		return Settings{}, err
+	}
+
+	if cfg.Workers < 0 {
+		return Settings{}, errors.New("workers must not be negative")
+	}
+
+	return Settings{
+		MatchAll:       matchAll,
+		DenoiseTitles:  cfg.DenoiseTitles,
+		TrustedDomains: cfg.TrustedDomains,
+		TrustBoost:     cfg.TrustBoost,
+		Workers:        cfg.Workers,
+	}, nil
+}
+
+// Determine if the host of the url is one of the trusted domains, subdomains of
+// a trusted domain are also considered trusted.
+func (s Settings) isTrusted(rawUrl string) bool {
+	u, err := url.Parse(rawUrl)
+	if err != nil {
+		return false
+	}
+
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	for _, domain := range s.TrustedDomains {
+		domain = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(domain)), "www.")
+		if domain != "" && (host == domain || strings.HasSuffix(host, "."+domain)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// A type that represents the rules posts are matched against, along with the
+// settings used to match them.
+type Heuristic struct {
+	rules    []rule.Rule
+	settings Settings
+}
+
+// Create a heuristic matching posts against the rules, using the default match
+// settings (e.g. a post matches if any rule matches).
+func NewHeuristic(rules []rule.Rule) *Heuristic {
+	return &Heuristic{rules: rules}
+}
+
+// Set the settings used to match posts, returning the heuristic.
+func (h *Heuristic) WithSettings(s Settings) *Heuristic {
+	h.settings = s
+	return h
+}
+
+// Test each reddit post passed in to see if a post matches any (or all) of the
+// heuristic's rules. Rules that need the context of a post are given the post's
+// context from the contexts passed in (keyed by post ID). Each post that matches
+// is scored by the number of rules it matched (plus a boost if it links to a
+// trusted domain), with the returned matches being sorted from the highest to the
+// lowest score. Posts are matched concurrently by a pool of workers, the size of
+// the pool being set by the match settings (defaulting to GOMAXPROCS).
+func (h *Heuristic) AppliedTo(posts []*reddit.Post, pctxs map[string]rule.PostContext) []*PostMatch {
+	workers := h.settings.Workers
+	if workers < 1 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if workers > len(posts) {
+		workers = len(posts)
+	}
+
+	// each post's match is kept at the post's index, so the matches are in the
+	// same order no matter which worker matched which post
+	results := make([]*PostMatch, len(posts))
+	if workers <= 1 {
+		for i, post := range posts {
+			results[i] = h.matchPost(post, pctxs[post.ID])
+		}
+	} else {
+		indexes := make(chan int)
+		var wg sync.WaitGroup
+		for w := 0; w < workers; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := range indexes {
+					results[i] = h.matchPost(posts[i], pctxs[posts[i].ID])
+				}
+			}()
+		}
+		for i := range posts {
+			indexes <- i
+		}
+		close(indexes)
+		wg.Wait()
+	}
+
+	var matches []*PostMatch
+	for _, match := range results {
+		if match != nil {
+			matches = append(matches, match)
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Score > matches[j].Score
+	})
+	return matches
+}
+
+// Get the post the heuristic's rules are given. Rules are given a copy of the
+// post with a denoised title if set, leaving the post's title intact for display.
+func (h *Heuristic) rulePost(post *reddit.Post) *reddit.Post {
+	if !h.settings.DenoiseTitles {
+		return post
+	}
+
+	postCopy := *post
+	postCopy.Title = denoiseTitle(post.Title)
+	return &postCopy
+}
+
+// Test a reddit post against the heuristic's rules, returning the post's match
+// or nil if the post did not match (or a rule failed to match the post). When
+// matching all rules, matching stops at the first rule the post fails. When
+// matching any rule, every rule is evaluated, as the post's score and the
+// notifiers its match is routed to depend on every rule it matched.
+func (h *Heuristic) matchPost(post *reddit.Post, pctx rule.PostContext) *PostMatch {
+	matchPost := h.rulePost(post)
+
+	var ruleNames []string
+	var reasons []string
+	var ruleTraces []ruleTrace
+	var matchErr error
+	for _, r := range h.rules {
+		var matched bool
+		if matched, matchErr = rule.EvaluateRule(r, matchPost, pctx); matchErr != nil {
+			logging.Warnf("skipping post %v, rule %v failed to match: %v", post.ID, r.Name(), matchErr)
+			break
+		}
+
+		if matched {
+			ruleNames = append(ruleNames, r.Name())
+			if reason := rule.ExplainRule(r, matchPost); reason != "" {
+				reasons = append(reasons, r.Name()+": "+reason)
+			}
+		}
+		if h.settings.Tracer != nil {
+			ruleTraces = append(ruleTraces, traceRule(r, matchPost, matched))
+		} else if !matched && h.settings.MatchAll {
+			// the post can no longer match all rules, the remaining rules are only
+			// evaluated when tracing
+			break
+		}
+	}
+
+	if matchErr != nil {
+		return nil
+	}
+
+	postMatched := len(ruleNames) > 0 && (!h.settings.MatchAll || len(ruleNames) == len(h.rules))
+	if h.settings.Tracer != nil {
+		if err := h.settings.Tracer.trace(postTrace{
+			PostID:  post.ID,
+			Title:   post.Title,
+			URL:     post.URL,
+			Matched: postMatched,
+			Rules:   ruleTraces,
+		}); err != nil {
+			logging.Errorf("failed to write trace: %v", err)
+		}
+	}
+
+	if !postMatched {
+		return nil
+	}
+
+	score := len(ruleNames)
+	if h.settings.isTrusted(post.URL) {
+		score += h.settings.TrustBoost
+	}
+	return &PostMatch{Post: post, Rules: ruleNames, Reasons: reasons, Score: score, Count: 1}
+}
+
+// A type that represents the verdict a rule gave a post, along with the rule's
+// reason if the rule can explain itself (or the error if the rule failed to match
+// the post).
+type Verdict struct {
+	Rule    string
+	Matched bool
+	Reason  string
+	Err     error
+}
+
+// Test a reddit post against each of the heuristic's rules, returning each rule's
+// verdict and whether the post matched. Unlike AppliedTo, every rule is evaluated
+// even once the post can no longer match (e.g. when matching all rules), so each
+// verdict can be shown (e.g. when tuning rules).
+func (h *Heuristic) Explain(post *reddit.Post, pctx rule.PostContext) ([]Verdict, bool) {
+	matchPost := h.rulePost(post)
+
+	var verdicts []Verdict
+	var matchedRules int
+	var failed bool
+	for _, r := range h.rules {
+		matched, err := rule.EvaluateRule(r, matchPost, pctx)
+		verdicts = append(verdicts, Verdict{
+			Rule:    r.Name(),
+			Matched: matched,
+			Reason:  rule.ExplainRule(r, matchPost),
+			Err:     err,
+		})
+
+		if err != nil {
+			failed = true
+		} else if matched {
+			matchedRules++
+		}
+	}
+
+	postMatched := !failed && matchedRules > 0 && (!h.settings.MatchAll || matchedRules == len(h.rules))
+	return verdicts, postMatched
+}
+
+// Normalize a post's title so reposts of the same post share the same identity.
+func normalizeTitle(title string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(title), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	}), " ")
+}
+
+// Group matches that share the same normalized title into a single match, keeping
+// the first match seen of the group and counting how many times it was seen.
+func CollapseReposts(matches []*PostMatch) []*PostMatch {
+	var collapsed []*PostMatch
+	seen := make(map[string]*PostMatch)
+	for _, match := range matches {
+		id := normalizeTitle(match.Post.Title)
+		if first, ok := seen[id]; ok {
+			first.Count += match.Count
+			continue
+		}
+
+		seen[id] = match
+		collapsed = append(collapsed, match)
+	}
+
+	return collapsed
+}
@@ -0,0 +1,438 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package engine
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+	"testing"
+
+	_ "github.com/cavcrosby/rsb/register"
+	"github.com/cavcrosby/rsb/rule"
+	"github.com/cavcrosby/rsb/rule/ramunderprice"
+	"github.com/cavcrosby/rsb/rule/regexmatch"
+	"github.com/turnage/graw/reddit"
+)
+
+// A type that represents a rule matching every post, used to test how matches are
+// gathered independently of the rules themselves.
+type matchAllRule struct {
+	name string
+}
+
+func (m *matchAllRule) Name() string {
+	return m.name
+}
+
+func (m *matchAllRule) Description() string {
+	return "matches every post"
+}
+
+func (m *matchAllRule) RegisterConfigs(configs []byte) error {
+	return nil
+}
+
+func (m *matchAllRule) Match(post *reddit.Post) bool {
+	return true
+}
+
+// A type that represents a rule that fails to match posts whose title contains
+// "absurd", matching every other post.
+type failingRule struct{}
+
+func (f *failingRule) Name() string {
+	return "failing"
+}
+
+func (f *failingRule) Description() string {
+	return "fails to match posts whose title is absurd"
+}
+
+func (f *failingRule) RegisterConfigs(configs []byte) error {
+	return nil
+}
+
+func (f *failingRule) Match(post *reddit.Post) bool {
+	matched, _ := f.TryMatch(post)
+	return matched
+}
+
+func (f *failingRule) TryMatch(post *reddit.Post) (bool, error) {
+	if strings.Contains(post.Title, "absurd") {
+		return false, fmt.Errorf("the title of post %v is absurd", post.ID)
+	}
+
+	return true, nil
+}
+
+func TestAppliedToSkipsFailedPosts(t *testing.T) {
+	h := &Heuristic{rules: []rule.Rule{&failingRule{}, &matchAllRule{name: "matchall"}}}
+	tests := []struct {
+		name    string
+		posts   []*reddit.Post
+		wantIDs []string
+	}{
+		{
+			"failed post first",
+			[]*reddit.Post{
+				{ID: "absurd", Title: "[RAM] Corsair Vengeance 16GB absurd"},
+				{ID: "cheap", Title: "[RAM] Corsair Vengeance 16GB $49.99"},
+			},
+			[]string{"cheap"},
+		},
+		{
+			"failed post between",
+			[]*reddit.Post{
+				{ID: "cheap", Title: "[RAM] Corsair Vengeance 16GB $49.99"},
+				{ID: "absurd", Title: "[RAM] G.Skill 32GB DDR5 absurd"},
+				{ID: "cheaper", Title: "[RAM] Crucial 8GB DDR4 $19.99"},
+			},
+			[]string{"cheap", "cheaper"},
+		},
+	}
+
+	for _, tt := range tests {
+		var gotIDs []string
+		for _, match := range h.AppliedTo(tt.posts, nil) {
+			gotIDs = append(gotIDs, match.Post.ID)
+		}
+		sort.Strings(gotIDs)
+
+		if !reflect.DeepEqual(gotIDs, tt.wantIDs) {
+			t.Errorf("%v: AppliedTo matched %v, want %v", tt.name, gotIDs, tt.wantIDs)
+		}
+	}
+}
+
+func TestAppliedToTrustedDomains(t *testing.T) {
+	rules := []rule.Rule{&matchAllRule{name: "matchall"}}
+	posts := []*reddit.Post{
+		{ID: "untrusted", Title: "[RAM] Corsair Vengeance 16GB $49.99", URL: "https://example.com/ram"},
+		{ID: "trusted", Title: "[RAM] Corsair Vengeance 16GB $49.99", URL: "https://www.newegg.com/p/ram"},
+	}
+
+	h := NewHeuristic(rules).WithSettings(Settings{TrustedDomains: []string{"newegg.com"}, TrustBoost: 5})
+	matches := h.AppliedTo(posts, nil)
+	if len(matches) != 2 {
+		t.Fatalf("AppliedTo matched %v posts, want 2", len(matches))
+	}
+
+	if matches[0].Post.ID != "trusted" {
+		t.Errorf("AppliedTo ranked post %v first, want the trusted post", matches[0].Post.ID)
+	}
+	if want := matches[1].Score + 5; matches[0].Score != want {
+		t.Errorf("trusted post scored %v, want %v", matches[0].Score, want)
+	}
+}
+
+func TestAppliedToMatchAllFilterRule(t *testing.T) {
+	rules, err := BuildRules([]RuleConfig{
+		{ID: "keywordmatch", Configs: map[string]interface{}{"keywords": []interface{}{"ram"}}},
+		{ID: "authorblock", Configs: map[string]interface{}{"blocked": []interface{}{"scalper99"}}},
+	})
+	if err != nil {
+		t.Fatalf("BuildRules returned an error: %v", err)
+	}
+	h := &Heuristic{rules: rules, settings: Settings{MatchAll: true}}
+
+	tests := []struct {
+		title  string
+		author string
+		want   bool
+	}{
+		{"[RAM] Corsair Vengeance 16GB $49.99", "dealhunter", true},
+		{"[RAM] Corsair Vengeance 16GB $49.99", "scalper99", false},
+		{"[GPU] RTX 4070 $549.99", "dealhunter", false},
+		{"[GPU] RTX 4070 $549.99", "scalper99", false},
+	}
+
+	for _, tt := range tests {
+		matches := h.AppliedTo([]*reddit.Post{{ID: "post", Title: tt.title, Author: tt.author}}, nil)
+		if got := len(matches) == 1; got != tt.want {
+			t.Errorf("AppliedTo(%q by %q) matched = %v, want %v", tt.title, tt.author, got, tt.want)
+		}
+	}
+}
+
+func TestAppliedToReasons(t *testing.T) {
+	rules, err := BuildRules([]RuleConfig{
+		{ID: "keywordmatch", Configs: map[string]interface{}{"keywords": []interface{}{"ram"}}},
+		{ID: "ramunderprice", Configs: map[string]interface{}{"price": 100.0}},
+		{ID: "mindiscount", Configs: map[string]interface{}{"minPercentOff": 30.0}},
+	})
+	if err != nil {
+		t.Fatalf("BuildRules returned an error: %v", err)
+	}
+	h := NewHeuristic(rules)
+
+	tests := []struct {
+		title       string
+		wantRules   []string
+		wantReasons []string
+	}{
+		{
+			"[RAM] Corsair Vengeance 16GB $49.99 (40% off)",
+			[]string{"keywordmatch", "ramunderprice", "mindiscount"},
+			[]string{"ramunderprice: $49.99 <= $100", "mindiscount: 40% off >= 30% off"},
+		},
+		{
+			"[RAM] G.Skill Trident Z5 64GB $189.99",
+			[]string{"keywordmatch"},
+			nil,
+		},
+		{
+			"[GPU] RTX 4070 $549.99 save 35%",
+			[]string{"mindiscount"},
+			[]string{"mindiscount: 35% off >= 30% off"},
+		},
+	}
+
+	for _, tt := range tests {
+		matches := h.AppliedTo([]*reddit.Post{{ID: "post", Title: tt.title}}, nil)
+		if len(matches) != 1 {
+			t.Errorf("AppliedTo(%q) matched %v posts, want 1", tt.title, len(matches))
+			continue
+		}
+
+		// rules that cannot explain themselves (e.g. keywordmatch) give no reason
+		if !reflect.DeepEqual(matches[0].Rules, tt.wantRules) {
+			t.Errorf("AppliedTo(%q) rules = %q, want %q", tt.title, matches[0].Rules, tt.wantRules)
+		}
+		if !reflect.DeepEqual(matches[0].Reasons, tt.wantReasons) {
+			t.Errorf("AppliedTo(%q) reasons = %q, want %q", tt.title, matches[0].Reasons, tt.wantReasons)
+		}
+	}
+}
+
+func TestAppliedToWorkers(t *testing.T) {
+	var rules []rule.Rule
+	for _, pattern := range []string{`(?i)\bddr4\b`, `\$\d+`} {
+		r := &regexmatch.RegexMatch{}
+		if err := r.RegisterConfigs([]byte(fmt.Sprintf(`{"pattern": %q}`, pattern))); err != nil {
+			t.Fatalf("RegisterConfigs returned an error: %v", err)
+		}
+		rules = append(rules, r)
+	}
+
+	// posts match both rules, one rule or none, so the matches are only in the
+	// same order if the pool keeps the order of posts with the same score
+	var posts []*reddit.Post
+	for i := 0; i < 300; i++ {
+		title := "[RAM] Corsair Vengeance 16GB DDR4 $49.99"
+		if i%3 == 1 {
+			title = "[GPU] RTX 4070 $549.99"
+		} else if i%3 == 2 {
+			title = "[META] Weekly discussion thread"
+		}
+		posts = append(posts, &reddit.Post{ID: fmt.Sprintf("post%v", i), Title: title})
+	}
+
+	matchIDs := func(workers int) []string {
+		var ids []string
+		h := NewHeuristic(rules).WithSettings(Settings{Workers: workers})
+		for _, match := range h.AppliedTo(posts, nil) {
+			ids = append(ids, fmt.Sprintf("%v:%v", match.Post.ID, match.Score))
+		}
+		return ids
+	}
+
+	want := matchIDs(1)
+	if len(want) != 200 {
+		t.Fatalf("AppliedTo with 1 worker matched %v posts, want 200", len(want))
+	}
+	for _, workers := range []int{2, 8, runtime.GOMAXPROCS(0)} {
+		if got := matchIDs(workers); !reflect.DeepEqual(got, want) {
+			t.Errorf("AppliedTo with %v workers matched %v, want %v", workers, got, want)
+		}
+	}
+}
+
+func TestHeuristicExplain(t *testing.T) {
+	rules, err := BuildRules([]RuleConfig{
+		{ID: "keywordmatch", Configs: map[string]interface{}{"keywords": []interface{}{"ram"}}},
+		{ID: "ramunderprice", Configs: map[string]interface{}{"price": 100.0}},
+	})
+	if err != nil {
+		t.Fatalf("BuildRules returned an error: %v", err)
+	}
+	h := &Heuristic{rules: rules, settings: Settings{MatchAll: true}}
+
+	tests := []struct {
+		title        string
+		wantVerdicts []bool
+		wantMatched  bool
+	}{
+		{"[RAM] Corsair Vengeance 16GB DDR4 $49.99", []bool{true, true}, true},
+		{"[RAM] G.Skill Trident Z5 64GB DDR5 $189.99", []bool{true, false}, false},
+		{"[GPU] RTX 4070 $549.99", []bool{false, false}, false},
+	}
+
+	for _, tt := range tests {
+		// every rule gives a verdict, even once the post can no longer match
+		verdicts, matched := h.Explain(&reddit.Post{Title: tt.title}, rule.PostContext{})
+		var gotVerdicts []bool
+		for _, verdict := range verdicts {
+			gotVerdicts = append(gotVerdicts, verdict.Matched)
+		}
+
+		if !reflect.DeepEqual(gotVerdicts, tt.wantVerdicts) || matched != tt.wantMatched {
+			t.Errorf("Explain(%q) = %v, %v, want %v, %v", tt.title, gotVerdicts, matched, tt.wantVerdicts, tt.wantMatched)
+		}
+	}
+}
+
+func TestSettingsIsTrusted(t *testing.T) {
+	s := Settings{TrustedDomains: []string{"newegg.com", " WWW.Amazon.com "}}
+	tests := []struct {
+		url  string
+		want bool
+	}{
+		{"https://www.newegg.com/p/ram", true},
+		{"https://newegg.com/p/ram", true},
+		{"https://shop.newegg.com/p/ram", true},
+		{"https://amazon.com/dp/123", true},
+		{"https://notnewegg.com/p/ram", false},
+		{"https://www.reddit.com/r/buildapcsales", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := s.isTrusted(tt.url); got != tt.want {
+			t.Errorf("isTrusted(%q) = %v, want %v", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestCollapseReposts(t *testing.T) {
+	var matches []*PostMatch
+	for i, title := range []string{
+		"[RAM] Corsair Vengeance 16GB $49.99",
+		"[ram] corsair vengeance 16gb - $49.99",
+		"[RAM] Corsair  Vengeance 16GB ($49.99)",
+		"[GPU] RTX 4070 $549.99",
+	} {
+		matches = append(matches, &PostMatch{Post: &reddit.Post{ID: fmt.Sprintf("post%v", i), Title: title}, Count: 1})
+	}
+
+	collapsed := CollapseReposts(matches)
+	if len(collapsed) != 2 {
+		t.Fatalf("CollapseReposts returned %v matches, want 2", len(collapsed))
+	}
+
+	if collapsed[0].Post.ID != "post0" || collapsed[0].Count != 3 {
+		t.Errorf("CollapseReposts()[0] = %v with count %v, want post0 with count 3", collapsed[0].Post.ID, collapsed[0].Count)
+	}
+	if collapsed[1].Post.ID != "post3" || collapsed[1].Count != 1 {
+		t.Errorf("CollapseReposts()[1] = %v with count %v, want post3 with count 1", collapsed[1].Post.ID, collapsed[1].Count)
+	}
+}
+
+func TestNewHeuristicWithSettings(t *testing.T) {
+	rules, err := BuildRules([]RuleConfig{
+		{ID: "keywordmatch", Configs: map[string]interface{}{"keywords": []interface{}{"ram"}}},
+	})
+	if err != nil {
+		t.Fatalf("BuildRules returned an error: %v", err)
+	}
+	rules = append(rules, &matchAllRule{name: "matchall"})
+	posts := []*reddit.Post{
+		{ID: "ram", Title: "[RAM] Corsair Vengeance 16GB DDR4 $49.99"},
+		{ID: "gpu", Title: "[GPU] RTX 4070 $549.99"},
+	}
+
+	tests := []struct {
+		name    string
+		h       *Heuristic
+		wantIDs []string
+	}{
+		{"default settings", NewHeuristic(rules), []string{"ram", "gpu"}},
+		{"all rules", NewHeuristic(rules).WithSettings(Settings{MatchAll: true}), []string{"ram"}},
+	}
+
+	for _, tt := range tests {
+		var gotIDs []string
+		for _, match := range tt.h.AppliedTo(posts, nil) {
+			gotIDs = append(gotIDs, match.Post.ID)
+		}
+		if !reflect.DeepEqual(gotIDs, tt.wantIDs) {
+			t.Errorf("%v: AppliedTo matched %v, want %v", tt.name, gotIDs, tt.wantIDs)
+		}
+	}
+}
+
+func BenchmarkAppliedTo(b *testing.B) {
+	// the failing rule fails every post, the other rules match every post
+	failingRule := &ramunderprice.RamUnderPrice{Price: rule.Dollars(10)}
+	var matchingRules []rule.Rule
+	for i := 0; i < 8; i++ {
+		r := &regexmatch.RegexMatch{}
+		if err := r.RegisterConfigs([]byte(`{"pattern": "(?i)\\b(?:ddr4|ddr5)\\b.*\\$\\d+"}`)); err != nil {
+			b.Fatalf("RegisterConfigs returned an error: %v", err)
+		}
+		matchingRules = append(matchingRules, r)
+	}
+
+	var posts []*reddit.Post
+	for i := 0; i < 100; i++ {
+		posts = append(posts, &reddit.Post{
+			ID:    fmt.Sprintf("post%v", i),
+			Title: "[RAM] Corsair Vengeance LPX 16GB DDR4 3200 $49.99",
+		})
+	}
+
+	benchmarks := []struct {
+		name     string
+		rules    []rule.Rule
+		matchAll bool
+	}{
+		// the post fails the first rule, so the remaining rules are skipped
+		{"all/fails first rule", append([]rule.Rule{failingRule}, matchingRules...), true},
+		// the post fails the last rule, so every rule is evaluated as before
+		// matching stopped at the first rule the post failed
+		{"all/fails last rule", append(append([]rule.Rule{}, matchingRules...), failingRule), true},
+		// every rule is evaluated, as the post's score and notifiers depend on
+		// every rule it matched
+		{"any", append([]rule.Rule{failingRule}, matchingRules...), false},
+	}
+
+	for _, bm := range benchmarks {
+		// matching serially and across a worker per CPU
+		pools := []struct {
+			name    string
+			workers int
+		}{
+			{"serial", 1},
+			{"pooled", runtime.GOMAXPROCS(0)},
+		}
+		for _, pool := range pools {
+			b.Run(bm.name+"/"+pool.name, func(b *testing.B) {
+				h := NewHeuristic(bm.rules).WithSettings(Settings{MatchAll: bm.matchAll, Workers: pool.workers})
+				b.ReportAllocs()
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					h.AppliedTo(posts, nil)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,130 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package engine_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/cavcrosby/rsb/engine"
+	_ "github.com/cavcrosby/rsb/register"
+	"github.com/turnage/graw/reddit"
+)
+
+// Match the posts using the configuration file, as a program using the engine
+// as a library would.
+func matchWithConfig(t *testing.T, configPath string, posts []*reddit.Post) []*engine.PostMatch {
+	t.Helper()
+	cfg, err := engine.LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("LoadConfig(%v) returned an error: %v", configPath, err)
+	}
+
+	rules, err := engine.BuildRules(cfg.RuleConfigs)
+	if err != nil {
+		t.Fatalf("BuildRules returned an error: %v", err)
+	}
+
+	matches, err := engine.Match(cfg, rules, posts)
+	if err != nil {
+		t.Fatalf("Match returned an error: %v", err)
+	}
+
+	return matches
+}
+
+func TestEngineEndToEnd(t *testing.T) {
+	posts := []*reddit.Post{
+		{ID: "pricy", Title: "[RAM] G.Skill Trident Z5 64GB DDR5 $189.99"},
+		{ID: "cheap", Title: "[RAM] Corsair Vengeance 16GB DDR4 $49.99"},
+		{ID: "refurbished", Title: "[RAM] Corsair Vengeance 16GB DDR4 refurbished $39.99"},
+		{ID: "gpu", Title: "[GPU] RTX 4070 $549.99"},
+	}
+
+	tests := []struct {
+		name       string
+		configName string
+		config     string
+		wantIDs    []string
+		wantRules  [][]string
+	}{
+		{
+			"json",
+			"rules.json",
+			`{"rules": [{"id": "ramunderprice", "configs": {"price": 100}}]}`,
+			[]string{"cheap", "refurbished"},
+			[][]string{{"ramunderprice"}, {"ramunderprice"}},
+		},
+		{
+			"yaml",
+			"rules.yaml",
+			"rules:\n  - id: ramunderprice\n    configs:\n      price: 100\n",
+			[]string{"cheap", "refurbished"},
+			[][]string{{"ramunderprice"}, {"ramunderprice"}},
+		},
+		{
+			"every rule must match",
+			"rules.json",
+			`{
+				"matchMode": "all",
+				"rules": [
+					{"id": "ramunderprice", "configs": {"price": 100}},
+					{"id": "keywordmatch", "negate": true, "configs": {"keywords": ["refurbished"]}}
+				]
+			}`,
+			[]string{"cheap"},
+			[][]string{{"ramunderprice", "keywordmatch"}},
+		},
+		{
+			"keys of rsb are ignored",
+			"rsb.json",
+			`{
+				"version": 2,
+				"sendmail_to": "baz@bar.com",
+				"subreddits": ["buildapcsales"],
+				"rules": [{"id": "gpuunderprice", "name": "gpu-deals", "configs": {"price": 600}}]
+			}`,
+			[]string{"gpu"},
+			[][]string{{"gpu-deals"}},
+		},
+	}
+
+	for _, tt := range tests {
+		configPath := filepath.Join(t.TempDir(), tt.configName)
+		if err := ioutil.WriteFile(configPath, []byte(tt.config), 0o600); err != nil {
+			t.Fatalf("%v: failed to write configuration file: %v", tt.name, err)
+		}
+
+		var gotIDs []string
+		var gotRules [][]string
+		for _, match := range matchWithConfig(t, configPath, posts) {
+			gotIDs = append(gotIDs, match.Post.ID)
+			gotRules = append(gotRules, match.Rules)
+		}
+		if !reflect.DeepEqual(gotIDs, tt.wantIDs) {
+			t.Errorf("%v: matched %v, want %v", tt.name, gotIDs, tt.wantIDs)
+		}
+		if !reflect.DeepEqual(gotRules, tt.wantRules) {
+			t.Errorf("%v: matched by %v, want %v", tt.name, gotRules, tt.wantRules)
+		}
+	}
+}
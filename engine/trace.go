@@ -0,0 +1,70 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package engine
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+
+	"github.com/cavcrosby/rsb/rule"
+	"github.com/turnage/graw/reddit"
+)
+
+// A type that represents the decision a rule made for a post.
+type ruleTrace struct {
+	Rule    string `json:"rule"`
+	Matched bool   `json:"matched"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+// A type that represents how a post was evaluated against every rule.
+type postTrace struct {
+	PostID  string      `json:"postId"`
+	Title   string      `json:"title"`
+	URL     string      `json:"url"`
+	Matched bool        `json:"matched"`
+	Rules   []ruleTrace `json:"rules"`
+}
+
+// A type that writes the evaluation of each post as a JSON record (one per line).
+// Posts matched concurrently may be written in any order.
+type Tracer struct {
+	mu      sync.Mutex
+	encoder *json.Encoder
+}
+
+// Create a tracer that writes to the writer passed in.
+func NewTracer(w io.Writer) *Tracer {
+	return &Tracer{encoder: json.NewEncoder(w)}
+}
+
+// Create the trace of a rule's decision for a post, including the rule's reason
+// if the rule can explain itself.
+func traceRule(r rule.Rule, post *reddit.Post, matched bool) ruleTrace {
+	return ruleTrace{Rule: r.Name(), Matched: matched, Reason: rule.ExplainRule(r, post)}
+}
+
+// Write the trace of a post.
+func (t *Tracer) trace(pt postTrace) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.encoder.Encode(pt)
+}
@@ -0,0 +1,84 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package engine
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/cavcrosby/rsb/rule"
+	"github.com/turnage/graw/reddit"
+)
+
+func TestTracer(t *testing.T) {
+	rules := []rule.Rule{&titleContainsRule{text: "[RAM]"}, &titleContainsRule{text: "$49.99"}}
+	posts := []*reddit.Post{
+		{ID: "cheapram", Title: "[RAM] Corsair Vengeance 16GB $49.99"},
+		{ID: "pricyram", Title: "[RAM] G.Skill Trident Z5 64GB $219.99"},
+		{ID: "gpu", Title: "[GPU] RTX 4070 $549.99"},
+	}
+
+	tests := []struct {
+		name        string
+		matchAll    bool
+		wantMatched map[string]bool
+	}{
+		{"any rule", false, map[string]bool{"cheapram": true, "pricyram": true, "gpu": false}},
+		{"all rules", true, map[string]bool{"cheapram": true, "pricyram": false, "gpu": false}},
+	}
+
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		h := NewHeuristic(rules).WithSettings(Settings{MatchAll: tt.matchAll, Tracer: NewTracer(&buf)})
+		h.AppliedTo(posts, nil)
+
+		traces := make(map[string]postTrace)
+		decoder := json.NewDecoder(&buf)
+		for decoder.More() {
+			var pt postTrace
+			if err := decoder.Decode(&pt); err != nil {
+				t.Fatalf("%v: failed to decode trace: %v", tt.name, err)
+			}
+			traces[pt.PostID] = pt
+		}
+
+		// every post is traced, even the posts that did not match
+		if len(traces) != len(posts) {
+			t.Errorf("%v: traced %v posts, want %v", tt.name, len(traces), len(posts))
+		}
+		for id, wantMatched := range tt.wantMatched {
+			pt, ok := traces[id]
+			if !ok {
+				t.Errorf("%v: post %v was not traced", tt.name, id)
+				continue
+			}
+
+			if pt.Matched != wantMatched {
+				t.Errorf("%v: trace of post %v matched = %v, want %v", tt.name, id, pt.Matched, wantMatched)
+			}
+			// every rule is traced, even the rules evaluated after the post can no
+			// longer match all rules
+			if len(pt.Rules) != len(rules) {
+				t.Errorf("%v: trace of post %v has %v rules, want %v", tt.name, id, len(pt.Rules), len(rules))
+			}
+		}
+	}
+}
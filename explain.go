@@ -0,0 +1,97 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/cavcrosby/rsb/engine"
+	"github.com/cavcrosby/rsb/rule"
+	"github.com/turnage/graw/reddit"
+)
+
+const (
+	explainPostID = "explain"
+)
+
+// A type used to store what the explain command builds the post it runs the
+// rules against from. Only the title is required.
+type explainOptions struct {
+	title     string
+	subreddit string
+	author    string
+	price     string
+}
+
+// Create the post the rules are run against, posted just now. The price, if
+// passed, is appended to the title (e.g. "[RAM] 16GB" becomes "[RAM] 16GB
+// $59.99"), as rules read prices from titles.
+func (opts explainOptions) post() (*reddit.Post, error) {
+	title := strings.TrimSpace(opts.title)
+	if title == "" {
+		return nil, errors.New("a title is required")
+	}
+
+	if opts.price != "" {
+		price, err := rule.ParsePrice(opts.price)
+		if err != nil {
+			return nil, err
+		}
+		title += " " + price.String()
+	}
+
+	return &reddit.Post{
+		ID:         explainPostID,
+		Name:       "t3_" + explainPostID,
+		Title:      title,
+		Subreddit:  opts.subreddit,
+		Author:     opts.author,
+		CreatedUTC: uint64(time.Now().Unix()),
+	}, nil
+}
+
+// Print each rule's verdict of the post (one per line, e.g. "PASS ramunderprice:
+// $59.99 <= $100"), followed by whether the post would match.
+func printVerdicts(w io.Writer, verdicts []engine.Verdict, matched bool) {
+	for _, verdict := range verdicts {
+		status, reason := "FAIL", verdict.Reason
+		if verdict.Err != nil {
+			status, reason = "ERROR", verdict.Err.Error()
+		} else if verdict.Matched {
+			status = "PASS"
+		}
+
+		line := fmt.Sprintf("%-5v %v", status, verdict.Rule)
+		if reason != "" {
+			line += ": " + reason
+		}
+		fmt.Fprintln(w, line)
+	}
+
+	if matched {
+		fmt.Fprintln(w, "the post would match")
+	} else {
+		fmt.Fprintln(w, "the post would not match")
+	}
+}
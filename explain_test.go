@@ -0,0 +1,104 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"testing"
+)
+
+func TestExplainCommand(t *testing.T) {
+	progConfigPath := writeTestConfig(t, "rsb.json", `{
+		"subreddits": ["buildapcsales"],
+		"rules": [
+			{"id": "ramunderprice", "configs": {"price": 100}},
+			{"id": "keywordmatch", "configs": {"keywords": ["ddr5"]}}
+		]
+	}`)
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{
+			"every rule passes",
+			[]string{"--title", "[RAM] G.Skill 32GB DDR5 $89.99"},
+			"PASS  ramunderprice: $89.99 <= $100\n" +
+				"PASS  keywordmatch\n" +
+				"the post would match\n",
+		},
+		{
+			"one rule passes",
+			[]string{"--title", "[RAM] Corsair Vengeance 16GB DDR4 $49.99"},
+			"PASS  ramunderprice: $49.99 <= $100\n" +
+				"FAIL  keywordmatch\n" +
+				"the post would match\n",
+		},
+		{
+			"no rule passes",
+			[]string{"--title", "[GPU] RTX 4070 $549.99"},
+			"FAIL  ramunderprice: no RAM in title\n" +
+				"FAIL  keywordmatch\n" +
+				"the post would not match\n",
+		},
+		{
+			"price passed in",
+			[]string{"--title", "[RAM] G.Skill 64GB DDR5", "--price", "189.99"},
+			"FAIL  ramunderprice: $189.99 > $100\n" +
+				"PASS  keywordmatch\n" +
+				"the post would match\n",
+		},
+	}
+
+	for _, tt := range tests {
+		args := append([]string{"--config", progConfigPath, "explain"}, tt.args...)
+		output, err := runArgsOutput(t, args...)
+		if err != nil {
+			t.Fatalf("%v: run() returned an error: %v", tt.name, err)
+		}
+		if output != tt.want {
+			t.Errorf("%v: printed\n%v\nwant\n%v", tt.name, output, tt.want)
+		}
+	}
+}
+
+func TestExplainOptionsPost(t *testing.T) {
+	tests := []struct {
+		opts      explainOptions
+		wantTitle string
+		wantErr   bool
+	}{
+		{explainOptions{title: "[RAM] 16GB $59.99"}, "[RAM] 16GB $59.99", false},
+		{explainOptions{title: "  [RAM] 16GB  "}, "[RAM] 16GB", false},
+		{explainOptions{title: "[RAM] 16GB", price: "59.99"}, "[RAM] 16GB $59.99", false},
+		{explainOptions{title: "[RAM] 16GB", price: "€59,99"}, "[RAM] 16GB €59.99", false},
+		{explainOptions{title: "[RAM] 16GB", price: "cheap"}, "", true},
+		{explainOptions{title: " "}, "", true},
+	}
+
+	for _, tt := range tests {
+		post, err := tt.opts.post()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%+v.post() error = %v, wantErr %v", tt.opts, err, tt.wantErr)
+		} else if err == nil && post.Title != tt.wantTitle {
+			t.Errorf("%+v.post() title = %q, want %q", tt.opts, post.Title, tt.wantTitle)
+		}
+	}
+}
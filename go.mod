@@ -5,6 +5,7 @@ go 1.17
 require (
 	github.com/turnage/graw v0.0.0-20201204201853-a177df1b5c91
 	github.com/urfave/cli/v2 v2.3.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
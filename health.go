@@ -0,0 +1,106 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	defaultHealthPath      string        = "/healthz"
+	defaultHealthThreshold time.Duration = 15 * time.Minute
+)
+
+// A type used to track whether the program is healthy. The program is considered
+// healthy when the last poll for posts succeeded within the threshold.
+type healthState struct {
+	mu          sync.Mutex
+	lastSuccess time.Time
+	threshold   time.Duration
+	now         func() time.Time
+}
+
+// Get the health threshold of a program polling at the poll interval. Polls can be
+// up to the max poll interval apart, so the threshold is twice the max poll
+// interval, being at least the default threshold.
+func healthThreshold(pi *pollInterval) time.Duration {
+	if threshold := 2 * pi.max; threshold > defaultHealthThreshold {
+		return threshold
+	}
+
+	return defaultHealthThreshold
+}
+
+// Create a health state that uses the wall clock.
+func newHealthState(threshold time.Duration) *healthState {
+	return &healthState{
+		threshold: threshold,
+		now:       time.Now,
+	}
+}
+
+// Record that a poll for posts has succeeded.
+func (h *healthState) pollSucceeded() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.lastSuccess = h.now()
+}
+
+// Determine if the last poll for posts succeeded within the threshold.
+func (h *healthState) healthy() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return !h.lastSuccess.IsZero() && h.now().Sub(h.lastSuccess) <= h.threshold
+}
+
+func (h *healthState) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h.healthy() {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("ok\n"))
+		return
+	}
+
+	w.WriteHeader(http.StatusServiceUnavailable)
+	w.Write([]byte("unhealthy\n"))
+}
+
+// Split an address to serve over http (e.g. ":8081/healthz") into the address to
+// listen on and the path to serve from, the default path being used if the
+// address has no path.
+func splitServeAddr(serveAddr, defaultPath string) (string, string) {
+	if i := strings.Index(serveAddr, "/"); i >= 0 {
+		return serveAddr[:i], serveAddr[i:]
+	}
+
+	return serveAddr, defaultPath
+}
+
+// Serve the health state over http on the health address until the context is
+// done.
+func serveHealth(ctx context.Context, healthAddr string, h *healthState) error {
+	addr, path := splitServeAddr(healthAddr, defaultHealthPath)
+	mux := http.NewServeMux()
+	mux.Handle(path, h)
+	return serveHttp(ctx, addr, mux)
+}
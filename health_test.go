@@ -0,0 +1,95 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHealthStateServeHTTP(t *testing.T) {
+	now := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	h := newHealthState(15 * time.Minute)
+	h.now = func() time.Time { return now }
+
+	tests := []struct {
+		name    string
+		advance time.Duration
+		poll    bool
+		want    int
+	}{
+		{"before the first poll", 0, false, http.StatusServiceUnavailable},
+		{"after a poll", 0, true, http.StatusOK},
+		{"within the threshold", 15 * time.Minute, false, http.StatusOK},
+		{"past the threshold", time.Second, false, http.StatusServiceUnavailable},
+		{"after polling again", 0, true, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		now = now.Add(tt.advance)
+		if tt.poll {
+			h.pollSucceeded()
+		}
+
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, defaultHealthPath, nil))
+		if rec.Code != tt.want {
+			t.Errorf("%v: ServeHTTP status = %v, want %v", tt.name, rec.Code, tt.want)
+		}
+	}
+}
+
+func TestHealthThreshold(t *testing.T) {
+	tests := []struct {
+		max  time.Duration
+		want time.Duration
+	}{
+		{time.Minute, defaultHealthThreshold},
+		{defaultHealthThreshold / 2, defaultHealthThreshold},
+		{time.Hour, 2 * time.Hour},
+	}
+
+	for _, tt := range tests {
+		if got := healthThreshold(newPollInterval(time.Minute, tt.max, 2)); got != tt.want {
+			t.Errorf("healthThreshold(max %v) = %v, want %v", tt.max, got, tt.want)
+		}
+	}
+}
+
+func TestSplitServeAddr(t *testing.T) {
+	tests := []struct {
+		serveAddr string
+		wantAddr  string
+		wantPath  string
+	}{
+		{":8081", ":8081", defaultHealthPath},
+		{":8081/health", ":8081", "/health"},
+		{"localhost:8081/status/health", "localhost:8081", "/status/health"},
+	}
+
+	for _, tt := range tests {
+		addr, path := splitServeAddr(tt.serveAddr, defaultHealthPath)
+		if addr != tt.wantAddr || path != tt.wantPath {
+			t.Errorf("splitServeAddr(%q) = %q, %q, want %q, %q", tt.serveAddr, addr, path, tt.wantAddr, tt.wantPath)
+		}
+	}
+}
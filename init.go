@@ -0,0 +1,165 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cavcrosby/rsb/engine"
+)
+
+var (
+	defaultStarterRule    string = "ramunderprice"
+	defaultStarterConfigs string = `{"price": 100}`
+)
+
+// A type used to store what the init command scaffolds the configuration file
+// with. Options left empty are prompted for (or defaulted when not interactive).
+type initOptions struct {
+	subreddits  []string
+	ruleID      string
+	ruleConfigs string
+	force       bool
+}
+
+// Ask a question, returning the default answer if the answer is empty.
+func prompt(scanner *bufio.Scanner, w io.Writer, question, defaultAnswer string) string {
+	fmt.Fprintf(w, "%v [%v]: ", question, defaultAnswer)
+	if !scanner.Scan() {
+		return defaultAnswer
+	}
+
+	if answer := strings.TrimSpace(scanner.Text()); answer != "" {
+		return answer
+	}
+
+	return defaultAnswer
+}
+
+// Fill in the init options that were not passed in, prompting for them if
+// interactive and using the defaults otherwise.
+func (opts *initOptions) complete(r io.Reader, w io.Writer, interactive bool) {
+	scanner := bufio.NewScanner(r)
+	if len(opts.subreddits) == 0 {
+		opts.subreddits = defaultSubreddits
+		if interactive {
+			answer := prompt(scanner, w, "subreddits to watch (comma separated)", strings.Join(defaultSubreddits, ","))
+			opts.subreddits = nil
+			for _, subreddit := range strings.Split(answer, ",") {
+				if subreddit = strings.TrimSpace(subreddit); subreddit != "" {
+					opts.subreddits = append(opts.subreddits, subreddit)
+				}
+			}
+		}
+	}
+
+	if opts.ruleID == "" {
+		opts.ruleID = defaultStarterRule
+		if interactive {
+			opts.ruleID = prompt(scanner, w, fmt.Sprintf("starter rule (run '%v list-rules' to see them)", progName), defaultStarterRule)
+		}
+	}
+
+	if opts.ruleConfigs == "" {
+		defaultConfigs := "{}"
+		if opts.ruleID == defaultStarterRule {
+			defaultConfigs = defaultStarterConfigs
+		}
+
+		opts.ruleConfigs = defaultConfigs
+		if interactive {
+			opts.ruleConfigs = prompt(scanner, w, fmt.Sprintf("configs of the %v rule as JSON", opts.ruleID), defaultConfigs)
+		}
+	}
+}
+
+// Create the configuration tree scaffolded from the init options, returning the
+// warnings of the configuration tree. Configuration trees that are not valid are
+// not created.
+func newInitConfigTree(opts initOptions) (configTree, []string, error) {
+	var configs map[string]interface{}
+	if err := json.Unmarshal([]byte(opts.ruleConfigs), &configs); err != nil {
+		return configTree{}, nil, fmt.Errorf("failed to parse configs of the %v rule: %v", opts.ruleID, err)
+	}
+
+	ct := configTree{
+		Version:    configVersion,
+		Subreddits: opts.subreddits,
+		Config: engine.Config{
+			RuleConfigs: []engine.RuleConfig{
+				{
+					ID:      opts.ruleID,
+					Configs: configs,
+				},
+			},
+		},
+	}
+
+	warnings, errs := validateConfigTree(ct)
+	if len(errs) > 0 {
+		var errMsgs []string
+		for _, err := range errs {
+			errMsgs = append(errMsgs, err.Error())
+		}
+		return configTree{}, warnings, fmt.Errorf("the scaffolded configuration is not valid: %v", strings.Join(errMsgs, "; "))
+	}
+
+	return ct, warnings, nil
+}
+
+// Write the scaffolded configuration tree to the configuration file, written as
+// YAML if the file has a YAML extension. An existing configuration file is only
+// overwritten if forced.
+func writeInitConfig(progConfigPath string, ct configTree, force bool) error {
+	if err := os.MkdirAll(filepath.Dir(progConfigPath), progConfigDirPerms); err != nil {
+		return fmt.Errorf("failed to create configuration directory %v: %v", filepath.Dir(progConfigPath), err)
+	}
+
+	ctBytes, err := marshalConfigTree(&ct, engine.ConfigFormat(progConfigPath))
+	if err != nil {
+		return err
+	}
+
+	flags := os.O_WRONLY | os.O_CREATE | os.O_EXCL
+	if force {
+		flags = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	}
+	progConfigFd, err := os.OpenFile(progConfigPath, flags, progConfigPerms)
+	if errors.Is(err, fs.ErrExist) {
+		return fmt.Errorf("the configuration file %v already exists, pass --force to overwrite it", progConfigPath)
+	} else if err != nil {
+		return fmt.Errorf("failed to create configuration file %v: %v", progConfigPath, err)
+	}
+	defer progConfigFd.Close()
+
+	if _, err := progConfigFd.Write(ctBytes); err != nil {
+		return fmt.Errorf("failed to write configuration file %v: %v", progConfigPath, err)
+	}
+
+	return progConfigFd.Close()
+}
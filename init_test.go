@@ -0,0 +1,143 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestInitOptionsComplete(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        initOptions
+		input       string
+		interactive bool
+		want        initOptions
+	}{
+		{
+			"defaults",
+			initOptions{},
+			"",
+			false,
+			initOptions{subreddits: defaultSubreddits, ruleID: defaultStarterRule, ruleConfigs: defaultStarterConfigs},
+		},
+		{
+			"prompted",
+			initOptions{},
+			"buildapcsales, hardwareswap\nkeywordmatch\n{\"keywords\": [\"ram\"]}\n",
+			true,
+			initOptions{
+				subreddits:  []string{"buildapcsales", "hardwareswap"},
+				ruleID:      "keywordmatch",
+				ruleConfigs: `{"keywords": ["ram"]}`,
+			},
+		},
+		{
+			"prompted with empty answers",
+			initOptions{},
+			"\n\n\n",
+			true,
+			initOptions{subreddits: defaultSubreddits, ruleID: defaultStarterRule, ruleConfigs: defaultStarterConfigs},
+		},
+		{
+			"passed in options are not prompted for",
+			initOptions{subreddits: []string{"hardwareswap"}, ruleID: "keywordmatch"},
+			"{\"keywords\": [\"gpu\"]}\n",
+			true,
+			initOptions{subreddits: []string{"hardwareswap"}, ruleID: "keywordmatch", ruleConfigs: `{"keywords": ["gpu"]}`},
+		},
+		{
+			"other rules default to empty configs",
+			initOptions{ruleID: "keywordmatch"},
+			"",
+			false,
+			initOptions{subreddits: defaultSubreddits, ruleID: "keywordmatch", ruleConfigs: "{}"},
+		},
+	}
+
+	for _, tt := range tests {
+		opts := tt.opts
+		opts.complete(strings.NewReader(tt.input), &bytes.Buffer{}, tt.interactive)
+		if !reflect.DeepEqual(opts, tt.want) {
+			t.Errorf("%v: complete() = %+v, want %+v", tt.name, opts, tt.want)
+		}
+	}
+}
+
+func TestNewInitConfigTree(t *testing.T) {
+	tests := []struct {
+		opts    initOptions
+		wantErr bool
+	}{
+		{initOptions{subreddits: defaultSubreddits, ruleID: defaultStarterRule, ruleConfigs: defaultStarterConfigs}, false},
+		{initOptions{subreddits: defaultSubreddits, ruleID: "notarule", ruleConfigs: "{}"}, true},
+		{initOptions{subreddits: defaultSubreddits, ruleID: defaultStarterRule, ruleConfigs: "{price: 100"}, true},
+	}
+
+	for _, tt := range tests {
+		ct, _, err := newInitConfigTree(tt.opts)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("newInitConfigTree(%+v) error = %v, wantErr %v", tt.opts, err, tt.wantErr)
+		} else if err == nil && unconfigured(ct) {
+			t.Errorf("newInitConfigTree(%+v) is unconfigured, want a configured rule", tt.opts)
+		}
+	}
+}
+
+func TestInitCommand(t *testing.T) {
+	tests := []struct {
+		name       string
+		configName string
+	}{
+		{"json", "rsb.json"},
+		{"yaml", "rsb.yaml"},
+	}
+
+	for _, tt := range tests {
+		progConfigPath := filepath.Join(t.TempDir(), tt.configName)
+		if err := runArgs(t, "--config", progConfigPath, "init", "--subreddit", "hardwareswap"); err != nil {
+			t.Fatalf("%v: init returned an error: %v", tt.name, err)
+		}
+
+		// the written configuration file loads and has a rule configured
+		if err := runArgs(t, "--config", progConfigPath, "validate-config", "--strict"); err != nil {
+			t.Errorf("%v: validate-config of the written configuration file returned an error: %v", tt.name, err)
+		}
+		ct, err := loadProgConfig(progConfigPath)
+		if err != nil {
+			t.Fatalf("%v: failed to load the written configuration file: %v", tt.name, err)
+		}
+		if !reflect.DeepEqual(ct.Subreddits, []string{"hardwareswap"}) || unconfigured(ct) {
+			t.Errorf("%v: written configuration has subreddits %v and unconfigured %v", tt.name, ct.Subreddits, unconfigured(ct))
+		}
+
+		// an existing configuration file is only overwritten if forced
+		if err := runArgs(t, "--config", progConfigPath, "init"); err == nil {
+			t.Errorf("%v: init over an existing configuration file returned no error", tt.name)
+		}
+		if err := runArgs(t, "--config", progConfigPath, "init", "--force"); err != nil {
+			t.Errorf("%v: init --force returned an error: %v", tt.name, err)
+		}
+	}
+}
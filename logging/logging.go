@@ -0,0 +1,100 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package logging
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync/atomic"
+)
+
+// A type that represents how severe a log message is.
+type Level int32
+
+const (
+	DebugLevel Level = iota
+	InfoLevel
+	WarnLevel
+	ErrorLevel
+)
+
+var (
+	DefaultLevel Level = InfoLevel
+	levelNames         = map[Level]string{
+		DebugLevel: "debug",
+		InfoLevel:  "info",
+		WarnLevel:  "warn",
+		ErrorLevel: "error",
+	}
+	level int32 = int32(DefaultLevel)
+)
+
+func (l Level) String() string {
+	return levelNames[l]
+}
+
+// Parse a level from its name (e.g. "warn").
+func ParseLevel(name string) (Level, error) {
+	for l, levelName := range levelNames {
+		if strings.EqualFold(name, levelName) {
+			return l, nil
+		}
+	}
+
+	return DefaultLevel, fmt.Errorf("the following log level is not known: %v", name)
+}
+
+// Set the minimum level of the messages that are logged.
+func SetLevel(l Level) {
+	atomic.StoreInt32(&level, int32(l))
+}
+
+// Determine if messages of the level are logged.
+func Enabled(l Level) bool {
+	return int32(l) >= atomic.LoadInt32(&level)
+}
+
+// Log a message of the level, prefixed with the level's name (e.g. "[warn]").
+func logf(l Level, format string, v ...interface{}) {
+	if Enabled(l) {
+		log.Printf("["+l.String()+"] "+format, v...)
+	}
+}
+
+// Log a message useful when diagnosing why a rule misbehaves.
+func Debugf(format string, v ...interface{}) {
+	logf(DebugLevel, format, v...)
+}
+
+// Log a message about the normal operation of the program.
+func Infof(format string, v ...interface{}) {
+	logf(InfoLevel, format, v...)
+}
+
+// Log a message about a problem the program recovered from.
+func Warnf(format string, v ...interface{}) {
+	logf(WarnLevel, format, v...)
+}
+
+// Log a message about a problem the program did not recover from.
+func Errorf(format string, v ...interface{}) {
+	logf(ErrorLevel, format, v...)
+}
@@ -0,0 +1,96 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package logging
+
+import (
+	"bytes"
+	"log"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Capture what is logged while running the function.
+func captureLog(t *testing.T, f func()) string {
+	t.Helper()
+	var buf bytes.Buffer
+	writer, flags := log.Writer(), log.Flags()
+	log.SetOutput(&buf)
+	log.SetFlags(0)
+	defer func() {
+		log.SetOutput(writer)
+		log.SetFlags(flags)
+	}()
+
+	f()
+	return buf.String()
+}
+
+func TestLevels(t *testing.T) {
+	defer SetLevel(DefaultLevel)
+
+	logAll := func() {
+		Debugf("debug %v", 1)
+		Infof("info %v", 2)
+		Warnf("warn %v", 3)
+		Errorf("error %v", 4)
+	}
+	tests := []struct {
+		level Level
+		want  []string
+	}{
+		{DebugLevel, []string{"[debug] debug 1", "[info] info 2", "[warn] warn 3", "[error] error 4"}},
+		{InfoLevel, []string{"[info] info 2", "[warn] warn 3", "[error] error 4"}},
+		{WarnLevel, []string{"[warn] warn 3", "[error] error 4"}},
+		{ErrorLevel, []string{"[error] error 4"}},
+	}
+
+	for _, tt := range tests {
+		SetLevel(tt.level)
+		got := strings.Split(strings.TrimSpace(captureLog(t, logAll)), "\n")
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("logged at level %v = %q, want %q", tt.level, got, tt.want)
+		}
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    Level
+		wantErr bool
+	}{
+		{"debug", DebugLevel, false},
+		{"INFO", InfoLevel, false},
+		{"Warn", WarnLevel, false},
+		{"error", ErrorLevel, false},
+		{"verbose", DefaultLevel, true},
+		{"", DefaultLevel, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseLevel(tt.name)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseLevel(%q) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		} else if got != tt.want {
+			t.Errorf("ParseLevel(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
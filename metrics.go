@@ -0,0 +1,137 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/cavcrosby/rsb/engine"
+	"github.com/turnage/graw/reddit"
+)
+
+const (
+	metricsContentType = "text/plain; version=0.0.4; charset=utf-8"
+)
+
+var (
+	defaultMetricsPath string = "/metrics"
+	labelValueReplacer        = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+)
+
+// A type that represents a counter metric, optionally broken down by the value of
+// a label (e.g. the matches of each rule). A counter without a label keeps its
+// count under the empty label value.
+type counter struct {
+	name   string
+	help   string
+	label  string
+	mu     sync.Mutex
+	counts map[string]uint64
+}
+
+// Create a counter, the label being empty for a counter not broken down by a
+// label.
+func newCounter(name, help, label string) *counter {
+	return &counter{
+		name:   name,
+		help:   help,
+		label:  label,
+		counts: make(map[string]uint64),
+	}
+}
+
+// Add to the count of the label value.
+func (c *counter) add(labelValue string, n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.counts[labelValue] += uint64(n)
+}
+
+// Write the counter in the Prometheus text format, the counts of a label being
+// sorted by the label's value.
+func (c *counter) write(w io.Writer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	fmt.Fprintf(w, "# HELP %v %v\n", c.name, c.help)
+	fmt.Fprintf(w, "# TYPE %v counter\n", c.name)
+	if c.label == "" {
+		fmt.Fprintf(w, "%v %v\n", c.name, c.counts[""])
+		return
+	}
+
+	labelValues := make([]string, 0, len(c.counts))
+	for labelValue := range c.counts {
+		labelValues = append(labelValues, labelValue)
+	}
+	sort.Strings(labelValues)
+
+	for _, labelValue := range labelValues {
+		fmt.Fprintf(w, "%v{%v=\"%v\"} %v\n", c.name, c.label, labelValueReplacer.Replace(labelValue), c.counts[labelValue])
+	}
+}
+
+// A type that stores the metrics of the program, served in the Prometheus text
+// format for monitoring the program over time.
+type metrics struct {
+	postsScanned *counter
+	matches      *counter
+	fetchErrors  *counter
+}
+
+// Create the metrics of the program, each counter starting at 0.
+func newMetrics() *metrics {
+	return &metrics{
+		postsScanned: newCounter(progName+"_posts_scanned_total", "The number of posts matched against the rules.", ""),
+		matches:      newCounter(progName+"_matches_total", "The number of matches of each rule.", "rule"),
+		fetchErrors:  newCounter(progName+"_fetch_errors_total", "The number of failed polls of each subreddit.", "subreddit"),
+	}
+}
+
+// Count the posts matched against the rules and the matches of each rule.
+func (m *metrics) recordMatches(posts []*reddit.Post, matches []*engine.PostMatch) {
+	m.postsScanned.add("", len(posts))
+	for _, match := range matches {
+		for _, ruleName := range match.Rules {
+			m.matches.add(ruleName, 1)
+		}
+	}
+}
+
+func (m *metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", metricsContentType)
+	for _, c := range []*counter{m.postsScanned, m.matches, m.fetchErrors} {
+		c.write(w)
+	}
+}
+
+// Serve the metrics over http on the metrics address until the context is done.
+func serveMetrics(ctx context.Context, metricsAddr string, m *metrics) error {
+	addr, path := splitServeAddr(metricsAddr, defaultMetricsPath)
+	mux := http.NewServeMux()
+	mux.Handle(path, m)
+	return serveHttp(ctx, addr, mux)
+}
@@ -0,0 +1,141 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cavcrosby/rsb/engine"
+	"github.com/turnage/graw/reddit"
+)
+
+// Scrape the metrics from the metrics endpoint.
+func scrapeMetrics(t *testing.T, m *metrics) string {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	m.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, defaultMetricsPath, nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("ServeHTTP status = %v, want %v", rec.Code, http.StatusOK)
+	}
+	if got := rec.Header().Get("Content-Type"); got != metricsContentType {
+		t.Errorf("ServeHTTP Content-Type = %q, want %q", got, metricsContentType)
+	}
+
+	return rec.Body.String()
+}
+
+func TestMetricsServeHTTP(t *testing.T) {
+	rules, err := engine.BuildRules([]engine.RuleConfig{
+		{ID: "ramunderprice", Configs: map[string]interface{}{"price": 100.0}},
+		{ID: "keywordmatch", Configs: map[string]interface{}{"keywords": []interface{}{"ddr5"}}},
+	})
+	if err != nil {
+		t.Fatalf("failed to build rules: %v", err)
+	}
+	h := engine.NewHeuristic(rules)
+
+	tests := []struct {
+		name        string
+		posts       []*reddit.Post
+		fetchErrors []string
+		want        string
+	}{
+		{
+			"before any activity",
+			nil,
+			nil,
+			"# HELP rsb_posts_scanned_total The number of posts matched against the rules.\n" +
+				"# TYPE rsb_posts_scanned_total counter\n" +
+				"rsb_posts_scanned_total 0\n" +
+				"# HELP rsb_matches_total The number of matches of each rule.\n" +
+				"# TYPE rsb_matches_total counter\n" +
+				"# HELP rsb_fetch_errors_total The number of failed polls of each subreddit.\n" +
+				"# TYPE rsb_fetch_errors_total counter\n",
+		},
+		{
+			"after a poll",
+			[]*reddit.Post{
+				{ID: "cheap", Title: "[RAM] Corsair Vengeance 16GB DDR4 $49.99"},
+				{ID: "ddr5", Title: "[RAM] G.Skill 32GB DDR5 $89.99"},
+				{ID: "gpu", Title: "[GPU] RTX 4070 $549.99"},
+			},
+			[]string{"hardwareswap"},
+			"# HELP rsb_posts_scanned_total The number of posts matched against the rules.\n" +
+				"# TYPE rsb_posts_scanned_total counter\n" +
+				"rsb_posts_scanned_total 3\n" +
+				"# HELP rsb_matches_total The number of matches of each rule.\n" +
+				"# TYPE rsb_matches_total counter\n" +
+				"rsb_matches_total{rule=\"keywordmatch\"} 1\n" +
+				"rsb_matches_total{rule=\"ramunderprice\"} 2\n" +
+				"# HELP rsb_fetch_errors_total The number of failed polls of each subreddit.\n" +
+				"# TYPE rsb_fetch_errors_total counter\n" +
+				"rsb_fetch_errors_total{subreddit=\"hardwareswap\"} 1\n",
+		},
+		{
+			"after another poll",
+			[]*reddit.Post{
+				{ID: "pricy", Title: "[RAM] G.Skill Trident Z5 64GB DDR5 $189.99"},
+			},
+			[]string{"hardwareswap", "buildapcsales"},
+			"# HELP rsb_posts_scanned_total The number of posts matched against the rules.\n" +
+				"# TYPE rsb_posts_scanned_total counter\n" +
+				"rsb_posts_scanned_total 4\n" +
+				"# HELP rsb_matches_total The number of matches of each rule.\n" +
+				"# TYPE rsb_matches_total counter\n" +
+				"rsb_matches_total{rule=\"keywordmatch\"} 2\n" +
+				"rsb_matches_total{rule=\"ramunderprice\"} 2\n" +
+				"# HELP rsb_fetch_errors_total The number of failed polls of each subreddit.\n" +
+				"# TYPE rsb_fetch_errors_total counter\n" +
+				"rsb_fetch_errors_total{subreddit=\"buildapcsales\"} 1\n" +
+				"rsb_fetch_errors_total{subreddit=\"hardwareswap\"} 2\n",
+		},
+	}
+
+	// each case continues the synthetic run of the case before it
+	m := newMetrics()
+	for _, tt := range tests {
+		for _, subreddit := range tt.fetchErrors {
+			m.fetchErrors.add(subreddit, 1)
+		}
+		if len(tt.posts) > 0 {
+			m.recordMatches(tt.posts, h.AppliedTo(tt.posts, nil))
+		}
+
+		if got := scrapeMetrics(t, m); got != tt.want {
+			t.Errorf("%v: scraped\n%v\nwant\n%v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCounterLabelValueEscaped(t *testing.T) {
+	c := newCounter("rsb_matches_total", "The number of matches of each rule.", "rule")
+	c.add("say \"hi\"\\\n", 1)
+
+	rec := httptest.NewRecorder()
+	c.write(rec)
+	want := "# HELP rsb_matches_total The number of matches of each rule.\n" +
+		"# TYPE rsb_matches_total counter\n" +
+		"rsb_matches_total{rule=\"say \\\"hi\\\"\\\\\\n\"} 1\n"
+	if got := rec.Body.String(); got != want {
+		t.Errorf("write() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,165 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package notify
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	discordTitleLimit      = 256
+	discordFieldValueLimit = 1024
+	discordMaxRateLimits   = 3
+)
+
+var (
+	// discord allows a webhook about 5 requests every 2 seconds
+	DefaultDiscordInterval time.Duration = 400 * time.Millisecond
+)
+
+// A type that represents a field of a discord embed.
+type discordField struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline"`
+}
+
+// A type that represents a discord embed, a rich preview of a link.
+type discordEmbed struct {
+	Title  string         `json:"title"`
+	URL    string         `json:"url"`
+	Fields []discordField `json:"fields,omitempty"`
+}
+
+// A type that represents a message sent to a discord webhook.
+type discordMessage struct {
+	Embeds []discordEmbed `json:"embeds"`
+}
+
+// A type that represents a notifier that sends each match to a discord webhook
+// as an embed, the post's title linking to the post. Requests are spaced out by
+// the interval, and a request that is rate limited anyway is sent again once
+// discord allows it.
+type Discord struct {
+	URL      string
+	Client   *http.Client
+	Interval time.Duration
+	mu       sync.Mutex
+	lastPost time.Time
+	sleep    func(d time.Duration)
+	now      func() time.Time
+}
+
+// Create a notifier that sends to the discord webhook URL, giving up on a request
+// after the timeout.
+func NewDiscord(url string, timeout time.Duration) *Discord {
+	return &Discord{
+		URL:      url,
+		Client:   &http.Client{Timeout: timeout},
+		Interval: DefaultDiscordInterval,
+		sleep:    time.Sleep,
+		now:      time.Now,
+	}
+}
+
+// Shorten the string to at most the limit in characters, marking that it was
+// shortened with an ellipsis.
+func truncate(s string, limit int) string {
+	runes := []rune(s)
+	if len(runes) <= limit {
+		return s
+	}
+
+	return string(runes[:limit-1]) + "…"
+}
+
+// Create the discord embed of a match record. Discord rejects embeds with text
+// over its limits, so text that is too long is truncated.
+func newDiscordEmbed(record MatchRecord) discordEmbed {
+	embed := discordEmbed{
+		Title: truncate(record.Title, discordTitleLimit),
+		URL:   record.URL,
+	}
+
+	if record.Author != "" {
+		embed.Fields = append(embed.Fields, discordField{
+			Name:   "Author",
+			Value:  truncate("u/"+record.Author, discordFieldValueLimit),
+			Inline: true,
+		})
+	}
+	if len(record.Rules) > 0 {
+		embed.Fields = append(embed.Fields, discordField{
+			Name:   "Rules",
+			Value:  truncate(strings.Join(record.Rules, ", "), discordFieldValueLimit),
+			Inline: true,
+		})
+	}
+	if len(record.Reasons) > 0 {
+		embed.Fields = append(embed.Fields, discordField{
+			Name:  "Reasons",
+			Value: truncate(strings.Join(record.Reasons, "\n"), discordFieldValueLimit),
+		})
+	}
+
+	return embed
+}
+
+// Send the message to the webhook, waiting out the interval since the last
+// message first. Rate limited messages are sent again after the wait discord
+// asks for, up to a few times.
+func (d *Discord) post(body []byte) error {
+	for rateLimits := 0; ; rateLimits++ {
+		if wait := d.Interval - d.now().Sub(d.lastPost); wait > 0 {
+			d.sleep(wait)
+		}
+		d.lastPost = d.now()
+
+		status, wait, err := postJson(d.Client, "discord", d.URL, body)
+		if status == http.StatusTooManyRequests && rateLimits < discordMaxRateLimits {
+			d.sleep(wait)
+			continue
+		}
+
+		return err
+	}
+}
+
+func (d *Discord) Notify(report *Report) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for _, record := range report.MatchRecords() {
+		msgBytes, err := json.Marshal(discordMessage{Embeds: []discordEmbed{newDiscordEmbed(record)}})
+		if err != nil {
+			return err
+		}
+
+		if err := d.post(msgBytes); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,206 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package notify
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/turnage/graw/reddit"
+)
+
+// Create a discord notifier whose sleeps are recorded instead of waited out and
+// whose clock only moves forward by sleeping.
+func newTestDiscord(url string, sleeps *[]time.Duration) *Discord {
+	d := NewDiscord(url, time.Second)
+	now := time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)
+	d.now = func() time.Time { return now }
+	d.sleep = func(dur time.Duration) {
+		*sleeps = append(*sleeps, dur)
+		now = now.Add(dur)
+	}
+
+	return d
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		s     string
+		limit int
+		want  string
+	}{
+		{"", 5, ""},
+		{"short", 5, "short"},
+		{"longer", 5, "long…"},
+		{"ünïcödé", 4, "ünï…"},
+		{strings.Repeat("a", 300), discordTitleLimit, strings.Repeat("a", discordTitleLimit-1) + "…"},
+	}
+
+	for _, tt := range tests {
+		got := truncate(tt.s, tt.limit)
+		if got != tt.want {
+			t.Errorf("truncate(%q, %v) = %q, want %q", tt.s, tt.limit, got, tt.want)
+		}
+		if n := len([]rune(got)); n > tt.limit {
+			t.Errorf("truncate(%q, %v) is %v characters, over the limit", tt.s, tt.limit, n)
+		}
+	}
+}
+
+func TestDiscordEmbed(t *testing.T) {
+	longTitle := strings.Repeat("t", 400)
+	longReason := strings.Repeat("r", 2000)
+	tests := []struct {
+		name  string
+		match Match
+		want  map[string]interface{}
+	}{
+		{
+			"every field",
+			Match{
+				Post:    &reddit.Post{Title: "[RAM] Corsair 16GB $49.99", URL: "https://example.com/ram", Author: "seller"},
+				Rules:   []string{"ramunderprice", "ramdeal"},
+				Reasons: []string{"$49.99 is at or below $100", "$3.12/GB"},
+				Count:   2,
+			},
+			map[string]interface{}{
+				"title": "[RAM] Corsair 16GB $49.99",
+				"url":   "https://example.com/ram",
+				"fields": []interface{}{
+					map[string]interface{}{"name": "Author", "value": "u/seller", "inline": true},
+					map[string]interface{}{"name": "Rules", "value": "ramunderprice, ramdeal", "inline": true},
+					map[string]interface{}{"name": "Reasons", "value": "$49.99 is at or below $100\n$3.12/GB", "inline": false},
+				},
+			},
+		},
+		{
+			"no fields",
+			Match{Post: &reddit.Post{Title: "[SSD] Samsung 1TB $79.99", URL: "https://example.com/ssd"}, Count: 1},
+			map[string]interface{}{
+				"title": "[SSD] Samsung 1TB $79.99",
+				"url":   "https://example.com/ssd",
+			},
+		},
+		{
+			"truncated",
+			Match{
+				Post:    &reddit.Post{Title: longTitle, URL: "https://example.com/long"},
+				Reasons: []string{longReason},
+				Count:   1,
+			},
+			map[string]interface{}{
+				"title": strings.Repeat("t", discordTitleLimit-1) + "…",
+				"url":   "https://example.com/long",
+				"fields": []interface{}{
+					map[string]interface{}{"name": "Reasons", "value": strings.Repeat("r", discordFieldValueLimit-1) + "…", "inline": false},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		s := startFakeWebhookServer(t)
+		var sleeps []time.Duration
+		report := &Report{Subreddit: "buildapcsales", Matches: []Match{tt.match}}
+		if err := newTestDiscord(s.URL, &sleeps).Notify(report); err != nil {
+			t.Fatalf("%v: Notify returned an error: %v", tt.name, err)
+		}
+
+		bodies := s.requests()
+		if len(bodies) != 1 {
+			t.Fatalf("%v: discord received %v requests, want 1", tt.name, len(bodies))
+		}
+		var msg map[string][]map[string]interface{}
+		if err := json.Unmarshal(bodies[0], &msg); err != nil {
+			t.Fatalf("%v: message is not valid JSON: %v", tt.name, err)
+		}
+		if len(msg["embeds"]) != 1 {
+			t.Fatalf("%v: message has %v embeds, want 1", tt.name, len(msg["embeds"]))
+		}
+		if got := msg["embeds"][0]; !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%v: embed = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestDiscordRateLimit(t *testing.T) {
+	tests := []struct {
+		name         string
+		matches      int
+		statuses     []int
+		retryAfter   string
+		wantRequests int
+		wantSleeps   []time.Duration
+		wantErr      bool
+	}{
+		{"one match", 1, nil, "", 1, nil, false},
+		{"spaced out", 3, nil, "", 3, []time.Duration{DefaultDiscordInterval, DefaultDiscordInterval}, false},
+		{"rate limited", 1, []int{http.StatusTooManyRequests}, "2", 2, []time.Duration{2 * time.Second}, false},
+		{"rate limited without retry after", 1, []int{http.StatusTooManyRequests}, "", 2, []time.Duration{time.Second}, false},
+		{
+			"rate limited too often",
+			1,
+			[]int{429, 429, 429, 429},
+			"0.5",
+			4,
+			[]time.Duration{500 * time.Millisecond, 500 * time.Millisecond, 500 * time.Millisecond},
+			true,
+		},
+		{"client error", 1, []int{http.StatusBadRequest}, "", 1, nil, true},
+	}
+
+	for _, tt := range tests {
+		requests := 0
+		s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			status := http.StatusNoContent
+			if requests < len(tt.statuses) {
+				status = tt.statuses[requests]
+			}
+			requests++
+			if tt.retryAfter != "" {
+				w.Header().Set("Retry-After", tt.retryAfter)
+			}
+			w.WriteHeader(status)
+		}))
+
+		var titles []string
+		for i := 0; i < tt.matches; i++ {
+			titles = append(titles, "[RAM] Corsair 16GB $49.99")
+		}
+		var sleeps []time.Duration
+		err := newTestDiscord(s.URL, &sleeps).Notify(reportOf(titles...))
+		s.Close()
+
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%v: Notify returned error %v, want error %v", tt.name, err, tt.wantErr)
+		}
+		if requests != tt.wantRequests {
+			t.Errorf("%v: discord received %v requests, want %v", tt.name, requests, tt.wantRequests)
+		}
+		if !reflect.DeepEqual(sleeps, tt.wantSleeps) {
+			t.Errorf("%v: slept %v, want %v", tt.name, sleeps, tt.wantSleeps)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package notify
+
+import (
+	"fmt"
+	"net/smtp"
+	"strconv"
+	"strings"
+)
+
+// A type that represents a notifier that sends reports by email.
+type Email struct {
+	Addr     string
+	Auth     smtp.Auth
+	From     string
+	To       string
+	ProgName string
+}
+
+func (e *Email) Notify(report *Report) error {
+	var postUrls []string
+	for i, post := range report.Posts {
+		postUrls = append(postUrls, strconv.Itoa(i+1)+". "+post.URL)
+	}
+
+	var matchUrls []string
+	for i, match := range report.Matches {
+		matchUrl := strconv.Itoa(i+1) + "(" + strings.Join(match.Rules, ",") + "). " + match.Post.URL
+		if match.Count > 1 {
+			matchUrl += fmt.Sprintf(" (seen %vx)", match.Count)
+		}
+		matchUrls = append(matchUrls, matchUrl)
+		for _, reason := range match.Reasons {
+			matchUrls = append(matchUrls, "    "+reason)
+		}
+	}
+
+	lines := []string{
+		fmt.Sprintf("To: %v", e.To),
+		fmt.Sprintf("Subject: %v Report: \"%v\"", e.ProgName, report.Subreddit),
+		"",
+		"Posts:",
+	}
+	lines = append(lines, postUrls...)
+	lines = append(lines, "Matches:")
+	lines = append(lines, matchUrls...)
+	msg := []byte(strings.Join(lines, "\r\n"))
+
+	return smtp.SendMail(e.Addr, e.Auth, e.From, []string{e.To}, msg)
+}
@@ -0,0 +1,188 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package notify
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	JsonFormat = "json"
+	CsvFormat  = "csv"
+)
+
+var (
+	csvHeader = []string{"time", "subreddit", "author", "title", "url", "rules"}
+)
+
+// A type that represents a match record written to a file, along with when the
+// match was written.
+type fileRecord struct {
+	Time time.Time `json:"time"`
+	MatchRecord
+}
+
+// A type that represents a notifier that appends matches to a file, either as a
+// JSON array of records or as CSV rows. The file stays valid across runs, the
+// JSON array being extended and the CSV header only being written once.
+type File struct {
+	Path   string
+	Format string
+	mu     sync.Mutex
+	now    func() time.Time
+}
+
+// Create a notifier that appends to the file at the path in the format. If the
+// format is empty, it is chosen by the file's extension.
+func NewFile(path, format string) (*File, error) {
+	if format == "" {
+		if format = strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), "."); format == "" {
+			return nil, fmt.Errorf("the format of the output file %v cannot be told from its extension", path)
+		}
+	}
+
+	switch format {
+	case JsonFormat, CsvFormat:
+	default:
+		return nil, fmt.Errorf("the following output format is not known: %v", format)
+	}
+
+	return &File{
+		Path:   path,
+		Format: format,
+		now:    time.Now,
+	}, nil
+}
+
+// Append the records to the JSON array in the file. The file is rewritten as a
+// whole to a temporary file that then replaces the file, so the file is never
+// left partially written.
+func (f *File) appendJson(records []fileRecord) error {
+	var existing []json.RawMessage
+	fileBytes, err := ioutil.ReadFile(f.Path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	} else if len(strings.TrimSpace(string(fileBytes))) > 0 {
+		if err := json.Unmarshal(fileBytes, &existing); err != nil {
+			return fmt.Errorf("failed to parse output file %v: %v", f.Path, err)
+		}
+	}
+
+	for _, record := range records {
+		recordBytes, err := json.Marshal(record)
+		if err != nil {
+			return err
+		}
+		existing = append(existing, recordBytes)
+	}
+
+	// use 4 spaces vs a tab character for indenting
+	arrayBytes, err := json.MarshalIndent(existing, "", "    ")
+	if err != nil {
+		return err
+	}
+
+	tmpFile, err := ioutil.TempFile(filepath.Dir(f.Path), filepath.Base(f.Path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpFile.Name())
+
+	if _, err := tmpFile.Write(append(arrayBytes, '\n')); err != nil {
+		tmpFile.Close()
+		return err
+	}
+	if err := tmpFile.Chmod(0644); err != nil {
+		tmpFile.Close()
+		return err
+	}
+	if err := tmpFile.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpFile.Name(), f.Path)
+}
+
+// Append the records to the file as CSV rows, writing the header first if the
+// file is empty.
+func (f *File) appendCsv(records []fileRecord) error {
+	fd, err := os.OpenFile(f.Path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	defer fd.Close()
+
+	fi, err := fd.Stat()
+	if err != nil {
+		return err
+	}
+
+	w := csv.NewWriter(fd)
+	if fi.Size() == 0 {
+		w.Write(csvHeader)
+	}
+	for _, record := range records {
+		w.Write([]string{
+			record.Time.Format(time.RFC3339),
+			record.Subreddit,
+			record.Author,
+			record.Title,
+			record.URL,
+			strings.Join(record.Rules, " "),
+		})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return err
+	}
+
+	return fd.Close()
+}
+
+func (f *File) Notify(report *Report) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	matchRecords := report.MatchRecords()
+	if len(matchRecords) == 0 {
+		return nil
+	}
+
+	now := f.now().UTC()
+	var records []fileRecord
+	for _, matchRecord := range matchRecords {
+		records = append(records, fileRecord{Time: now, MatchRecord: matchRecord})
+	}
+
+	if f.Format == CsvFormat {
+		return f.appendCsv(records)
+	}
+	return f.appendJson(records)
+}
@@ -0,0 +1,197 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package notify
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestNewFile(t *testing.T) {
+	tests := []struct {
+		path       string
+		format     string
+		wantFormat string
+		wantErr    bool
+	}{
+		{"matches.json", "", JsonFormat, false},
+		{"matches.CSV", "", CsvFormat, false},
+		{"matches.txt", JsonFormat, JsonFormat, false},
+		{"matches", CsvFormat, CsvFormat, false},
+		{"matches", "", "", true},
+		{"matches.txt", "", "", true},
+		{"matches.json", "xml", "", true},
+	}
+
+	for _, tt := range tests {
+		f, err := NewFile(tt.path, tt.format)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("NewFile(%q, %q) returned error %v, want error %v", tt.path, tt.format, err, tt.wantErr)
+			continue
+		}
+		if err == nil && f.Format != tt.wantFormat {
+			t.Errorf("NewFile(%q, %q) format = %q, want %q", tt.path, tt.format, f.Format, tt.wantFormat)
+		}
+	}
+}
+
+// Create a file notifier writing to the file at the path, every match being
+// written at the same time.
+func newTestFile(t *testing.T, path string) *File {
+	t.Helper()
+	f, err := NewFile(path, "")
+	if err != nil {
+		t.Fatalf("failed to create file notifier: %v", err)
+	}
+	f.now = func() time.Time { return time.Date(2021, time.March, 4, 5, 6, 7, 0, time.UTC) }
+
+	return f
+}
+
+func TestFileNotify(t *testing.T) {
+	tests := []struct {
+		name    string
+		reports [][]string
+	}{
+		{"one run", [][]string{{"first", "second"}}},
+		{"several runs", [][]string{{"first"}, {"second", "third"}, {"fourth"}}},
+		{"runs without matches", [][]string{{}, {"first"}, {}}},
+		{"quoted titles", [][]string{{`[RAM] 16GB, "fast" $49.99`}, {"line\nbreak"}}},
+	}
+
+	for _, tt := range tests {
+		var wantTitles []string
+		for _, titles := range tt.reports {
+			wantTitles = append(wantTitles, titles...)
+		}
+
+		for _, format := range []string{JsonFormat, CsvFormat} {
+			path := filepath.Join(t.TempDir(), "matches."+format)
+			for _, titles := range tt.reports {
+				// each run creates its own notifier
+				f := newTestFile(t, path)
+				if err := f.Notify(reportOf(titles...)); err != nil {
+					t.Fatalf("%v: %v Notify returned an error: %v", tt.name, format, err)
+				}
+			}
+
+			var gotTitles []string
+			if format == JsonFormat {
+				gotTitles = readJsonTitles(t, path, len(wantTitles) == 0)
+			} else {
+				gotTitles = readCsvTitles(t, path, len(wantTitles) == 0)
+			}
+			if !reflect.DeepEqual(gotTitles, wantTitles) {
+				t.Errorf("%v: %v file has titles %q, want %q", tt.name, format, gotTitles, wantTitles)
+			}
+		}
+	}
+}
+
+func TestFileNotifyInvalidJson(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "matches.json")
+	if err := ioutil.WriteFile(path, []byte("not json"), 0644); err != nil {
+		t.Fatalf("failed to write %v: %v", path, err)
+	}
+
+	if err := newTestFile(t, path).Notify(reportOf("first")); err == nil {
+		t.Errorf("Notify returned no error for an output file that is not a JSON array")
+	}
+	if fileBytes, _ := ioutil.ReadFile(path); string(fileBytes) != "not json" {
+		t.Errorf("output file was changed to %q, want it left as is", fileBytes)
+	}
+}
+
+// Read the titles of the records in the JSON file, checking every field of the
+// records along the way.
+func readJsonTitles(t *testing.T, path string, mayNotExist bool) []string {
+	t.Helper()
+	fileBytes, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) && mayNotExist {
+		return nil
+	} else if err != nil {
+		t.Fatalf("failed to read %v: %v", path, err)
+	}
+
+	var records []map[string]interface{}
+	if err := json.Unmarshal(fileBytes, &records); err != nil {
+		t.Fatalf("%v is not a valid JSON array: %v", path, err)
+	}
+
+	var titles []string
+	for _, record := range records {
+		title, _ := record["title"].(string)
+		titles = append(titles, title)
+		want := map[string]interface{}{
+			"time":      "2021-03-04T05:06:07Z",
+			"subreddit": "buildapcsales",
+			"title":     title,
+			"url":       "https://example.com/" + title,
+			"author":    "seller",
+			"rules":     []interface{}{"ramunderprice", "ramdeal"},
+			"reasons":   nil,
+			"count":     2.0,
+		}
+		if !reflect.DeepEqual(record, want) {
+			t.Errorf("record = %v, want %v", record, want)
+		}
+	}
+
+	return titles
+}
+
+// Read the titles of the rows in the CSV file, checking the header is only
+// written once and every field of the rows along the way.
+func readCsvTitles(t *testing.T, path string, mayNotExist bool) []string {
+	t.Helper()
+	fd, err := os.Open(path)
+	if os.IsNotExist(err) && mayNotExist {
+		return nil
+	} else if err != nil {
+		t.Fatalf("failed to open %v: %v", path, err)
+	}
+	defer fd.Close()
+
+	rows, err := csv.NewReader(fd).ReadAll()
+	if err != nil {
+		t.Fatalf("%v is not a valid CSV file: %v", path, err)
+	}
+	if len(rows) == 0 || !reflect.DeepEqual(rows[0], csvHeader) {
+		t.Fatalf("%v does not start with the header %q", path, csvHeader)
+	}
+
+	var titles []string
+	for _, row := range rows[1:] {
+		title := row[3]
+		titles = append(titles, title)
+		want := []string{"2021-03-04T05:06:07Z", "buildapcsales", "seller", title, "https://example.com/" + title, "ramunderprice ramdeal"}
+		if !reflect.DeepEqual(row, want) {
+			t.Errorf("row = %q, want %q", row, want)
+		}
+	}
+
+	return titles
+}
@@ -0,0 +1,110 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package notify
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/turnage/graw/reddit"
+)
+
+// A type that represents a post that matched one or more rules.
+type Match struct {
+	Post    *reddit.Post
+	Rules   []string
+	Reasons []string
+	Count   int
+}
+
+// A type that represents the posts gathered from a subreddit and the posts that
+// matched.
+type Report struct {
+	Subreddit string
+	Posts     []*reddit.Post
+	Matches   []Match
+}
+
+// A type that represents a match as a record for notifiers sending structured
+// data (e.g. JSON).
+type MatchRecord struct {
+	Subreddit string   `json:"subreddit"`
+	Title     string   `json:"title"`
+	URL       string   `json:"url"`
+	Author    string   `json:"author"`
+	Rules     []string `json:"rules"`
+	Reasons   []string `json:"reasons"`
+	Count     int      `json:"count"`
+}
+
+// Create the records of the matches in the report. A record's subreddit is that
+// of its post, falling back to the report's subreddit if the post's is not known.
+func (r *Report) MatchRecords() []MatchRecord {
+	var records []MatchRecord
+	for _, match := range r.Matches {
+		subreddit := match.Post.Subreddit
+		if subreddit == "" {
+			subreddit = r.Subreddit
+		}
+		records = append(records, MatchRecord{
+			Subreddit: subreddit,
+			Title:     match.Post.Title,
+			URL:       match.Post.URL,
+			Author:    match.Post.Author,
+			Rules:     match.Rules,
+			Reasons:   match.Reasons,
+			Count:     match.Count,
+		})
+	}
+
+	return records
+}
+
+// A type that defines what a notifier is.
+type Notifier interface {
+	Notify(report *Report) error
+}
+
+// A type that represents a notifier that notifies another notifier once per
+// match, so a failure (and the retries, see Retry) for one match does not cause
+// the other matches to be sent again or not at all.
+type PerMatch struct {
+	Notifier Notifier
+}
+
+func (p *PerMatch) Notify(report *Report) error {
+	var errs []string
+	for _, match := range report.Matches {
+		matchReport := &Report{
+			Subreddit: report.Subreddit,
+			Posts:     report.Posts,
+			Matches:   []Match{match},
+		}
+		if err := p.Notifier.Notify(matchReport); err != nil {
+			errs = append(errs, fmt.Sprintf("%v: %v", match.Post.URL, err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%v of %v matches failed: %v", len(errs), len(report.Matches), strings.Join(errs, "; "))
+	}
+
+	return nil
+}
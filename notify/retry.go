@@ -0,0 +1,106 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package notify
+
+import (
+	"errors"
+	"math/rand"
+	"time"
+
+	"github.com/cavcrosby/rsb/backoff"
+)
+
+var (
+	DefaultMaxAttempts int           = 3
+	DefaultBaseDelay   time.Duration = time.Second
+	DefaultMaxDelay    time.Duration = 30 * time.Second
+)
+
+// A type that represents an error that should not be retried.
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+// Mark an error returned by a notifier as permanent, meaning retrying the
+// notification would not help.
+func Permanent(err error) error {
+	return &permanentError{err: err}
+}
+
+// A type that represents a notifier that retries another notifier on transient
+// errors. Retries are delayed using exponential backoff with jitter, up to a
+// maximum number of attempts. If all attempts fail, GiveUp is called (if set),
+// for example to enqueue the report elsewhere.
+type Retry struct {
+	Notifier    Notifier
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+	GiveUp      func(report *Report, err error)
+	sleep       func(d time.Duration)
+	jitter      func(n int64) int64
+}
+
+// Create a notifier that retries the notifier passed in.
+func NewRetry(n Notifier, maxAttempts int, baseDelay, maxDelay time.Duration) *Retry {
+	return &Retry{
+		Notifier:    n,
+		MaxAttempts: maxAttempts,
+		BaseDelay:   baseDelay,
+		MaxDelay:    maxDelay,
+		sleep:       time.Sleep,
+		jitter:      rand.Int63n,
+	}
+}
+
+// Determine how long to wait before the next attempt.
+func (r *Retry) backoff(attempt int) time.Duration {
+	return backoff.Delay(attempt, r.BaseDelay, r.MaxDelay, r.jitter)
+}
+
+func (r *Retry) Notify(report *Report) error {
+	var err error
+	var perr *permanentError
+	for attempt := 1; attempt <= r.MaxAttempts || attempt == 1; attempt++ {
+		if err = r.Notifier.Notify(report); err == nil {
+			return nil
+		} else if errors.As(err, &perr) {
+			break
+		}
+
+		if attempt < r.MaxAttempts {
+			r.sleep(r.backoff(attempt))
+		}
+	}
+
+	if r.GiveUp != nil {
+		r.GiveUp(report, err)
+	}
+
+	return err
+}
@@ -0,0 +1,109 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package notify
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// A type that represents a notifier failing a number of times before succeeding.
+type flakyNotifier struct {
+	failures int
+	attempts int
+	err      error
+}
+
+func (f *flakyNotifier) Notify(report *Report) error {
+	f.attempts++
+	if f.attempts <= f.failures {
+		return f.err
+	}
+
+	return nil
+}
+
+// Create a retrying notifier whose sleeps are recorded instead of waited out and
+// whose jitter is always 0.
+func newTestRetry(n Notifier, maxAttempts int, sleeps *[]time.Duration) *Retry {
+	r := NewRetry(n, maxAttempts, time.Second, 3*time.Second)
+	r.sleep = func(d time.Duration) { *sleeps = append(*sleeps, d) }
+	r.jitter = func(n int64) int64 { return 0 }
+	return r
+}
+
+func TestRetryNotify(t *testing.T) {
+	errTransient := errors.New("connection reset")
+	tests := []struct {
+		name         string
+		failures     int
+		err          error
+		maxAttempts  int
+		wantAttempts int
+		wantSleeps   []time.Duration
+		wantErr      bool
+	}{
+		{"succeeds first", 0, errTransient, 4, 1, nil, false},
+		{"succeeds on the last attempt", 3, errTransient, 4, 4, []time.Duration{500 * time.Millisecond, time.Second, 1500 * time.Millisecond}, false},
+		{"gives up", 5, errTransient, 3, 3, []time.Duration{500 * time.Millisecond, time.Second}, true},
+		{"permanent error", 5, Permanent(errTransient), 3, 1, nil, true},
+		{"at least one attempt", 5, errTransient, 0, 1, nil, true},
+	}
+
+	for _, tt := range tests {
+		n := &flakyNotifier{failures: tt.failures, err: tt.err}
+		var sleeps []time.Duration
+		var gaveUp bool
+		r := newTestRetry(n, tt.maxAttempts, &sleeps)
+		r.GiveUp = func(report *Report, err error) { gaveUp = true }
+
+		err := r.Notify(&Report{})
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%v: Notify returned error %v, want error %v", tt.name, err, tt.wantErr)
+		}
+		if gaveUp != tt.wantErr {
+			t.Errorf("%v: GiveUp called = %v, want %v", tt.name, gaveUp, tt.wantErr)
+		}
+		if n.attempts != tt.wantAttempts {
+			t.Errorf("%v: notifier attempted %v times, want %v", tt.name, n.attempts, tt.wantAttempts)
+		}
+		if !reflect.DeepEqual(sleeps, tt.wantSleeps) {
+			t.Errorf("%v: slept %v, want %v", tt.name, sleeps, tt.wantSleeps)
+		}
+	}
+}
+
+func TestRetryBackoffJitter(t *testing.T) {
+	r := NewRetry(nil, 3, time.Second, 30*time.Second)
+	for attempt := 1; attempt <= 8; attempt++ {
+		delay := time.Second << (attempt - 1)
+		if delay > 30*time.Second {
+			delay = 30 * time.Second
+		}
+
+		for i := 0; i < 20; i++ {
+			if got := r.backoff(attempt); got < delay/2 || got > delay {
+				t.Errorf("backoff(%v) = %v, want between %v and %v", attempt, got, delay/2, delay)
+			}
+		}
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package notify
+
+import (
+	"encoding/json"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/cavcrosby/rsb/logging"
+)
+
+var (
+	socketDialTimeout  time.Duration = 5 * time.Second
+	socketWriteTimeout time.Duration = 5 * time.Second
+)
+
+// A type that represents a notifier that writes matches as JSON records (one per
+// line) to a Unix domain socket, for consumption by a local process. The socket
+// is reconnected to if the consumer restarts, and matches are dropped (and
+// logged) while there is no consumer.
+type Socket struct {
+	Path string
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+// Create a notifier that writes to the Unix domain socket at the path.
+func NewSocket(path string) *Socket {
+	return &Socket{Path: path}
+}
+
+// Write a record to the socket, connecting to the socket first if needed.
+func (s *Socket) write(record []byte) error {
+	if s.conn == nil {
+		conn, err := net.DialTimeout("unix", s.Path, socketDialTimeout)
+		if err != nil {
+			return err
+		}
+		s.conn = conn
+	}
+
+	s.conn.SetWriteDeadline(time.Now().Add(socketWriteTimeout))
+	if _, err := s.conn.Write(record); err != nil {
+		s.conn.Close()
+		s.conn = nil
+		return err
+	}
+
+	return nil
+}
+
+func (s *Socket) Notify(report *Report) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, record := range report.MatchRecords() {
+		recordBytes, err := json.Marshal(record)
+		if err != nil {
+			return err
+		}
+		recordBytes = append(recordBytes, '\n')
+
+		// the consumer may have restarted since the last write, reconnect once
+		if err := s.write(recordBytes); err != nil {
+			if err := s.write(recordBytes); err != nil {
+				logging.Warnf("dropping match %v, no consumer on socket %v: %v", record.URL, s.Path, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Close the connection to the socket (if any).
+func (s *Socket) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conn == nil {
+		return nil
+	}
+
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
@@ -0,0 +1,164 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package notify
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/turnage/graw/reddit"
+)
+
+// A type that represents a local process consuming the match records written to a
+// Unix domain socket.
+type fakeConsumer struct {
+	listener net.Listener
+	records  chan MatchRecord
+	mu       sync.Mutex
+	conns    []net.Conn
+}
+
+// Start a consumer listening on the socket at the path.
+func startFakeConsumer(t *testing.T, path string) *fakeConsumer {
+	t.Helper()
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatalf("failed to listen on socket: %v", err)
+	}
+
+	c := &fakeConsumer{listener: listener, records: make(chan MatchRecord, 10)}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			c.mu.Lock()
+			c.conns = append(c.conns, conn)
+			c.mu.Unlock()
+
+			go func() {
+				defer conn.Close()
+				scanner := bufio.NewScanner(conn)
+				for scanner.Scan() {
+					var record MatchRecord
+					if err := json.Unmarshal(scanner.Bytes(), &record); err == nil {
+						c.records <- record
+					}
+				}
+			}()
+		}
+	}()
+
+	return c
+}
+
+// Stop the consumer, closing its connections.
+func (c *fakeConsumer) stop() {
+	c.listener.Close()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, conn := range c.conns {
+		conn.Close()
+	}
+}
+
+// Get the titles of the records received, waiting for the number of records
+// passed in.
+func (c *fakeConsumer) titles(t *testing.T, n int) []string {
+	t.Helper()
+	var titles []string
+	for i := 0; i < n; i++ {
+		select {
+		case record := <-c.records:
+			titles = append(titles, record.Title)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("consumer received %v records, want %v", len(titles), n)
+		}
+	}
+
+	return titles
+}
+
+// Create a report of matches with the titles, each post being by an author and
+// matched by two rules.
+func reportOf(titles ...string) *Report {
+	report := &Report{Subreddit: "buildapcsales"}
+	for _, title := range titles {
+		report.Matches = append(report.Matches, Match{
+			Post:  &reddit.Post{Title: title, URL: "https://example.com/" + title, Author: "seller"},
+			Rules: []string{"ramunderprice", "ramdeal"},
+			Count: 2,
+		})
+	}
+
+	return report
+}
+
+func TestSocketNotify(t *testing.T) {
+	// socket paths are limited in length, os.TempDir is usually shorter than
+	// t.TempDir
+	dir, err := os.MkdirTemp("", "rsb")
+	if err != nil {
+		t.Fatalf("failed to create directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "matches.sock")
+
+	s := NewSocket(path)
+	defer s.Close()
+
+	// a missing consumer drops the matches without failing
+	if err := s.Notify(reportOf("[RAM] dropped $49.99")); err != nil {
+		t.Fatalf("Notify without a consumer returned an error: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		titles []string
+	}{
+		{"consumer started", []string{"[RAM] Corsair 16GB $49.99", "[GPU] RTX 4070 $549.99"}},
+		{"consumer restarted", []string{"[SSD] WD Black 2TB $119.99"}},
+	}
+
+	for _, tt := range tests {
+		c := startFakeConsumer(t, path)
+		if err := s.Notify(reportOf(tt.titles...)); err != nil {
+			t.Fatalf("%v: Notify returned an error: %v", tt.name, err)
+		}
+
+		got := c.titles(t, len(tt.titles))
+		for i, title := range tt.titles {
+			if got[i] != title {
+				t.Errorf("%v: record %v title = %q, want %q", tt.name, i, got[i], title)
+			}
+		}
+
+		// the consumer going away closes its connections, leaving the notifier to
+		// reconnect
+		c.stop()
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+var (
+	DefaultWebhookTimeout time.Duration = 10 * time.Second
+)
+
+// A type that represents a notifier that POSTs each match as a JSON record to a
+// webhook URL. Server errors and rate limiting are transient, other unsuccessful
+// responses are permanent (see Permanent).
+type Webhook struct {
+	URL    string
+	Client *http.Client
+}
+
+// Create a notifier that POSTs to the webhook URL, giving up on a request after
+// the timeout.
+func NewWebhook(url string, timeout time.Duration) *Webhook {
+	return &Webhook{
+		URL:    url,
+		Client: &http.Client{Timeout: timeout},
+	}
+}
+
+// POST the JSON body to the URL, the service posted to (e.g. "webhook") being
+// named in errors. Along with the status code, how long the service asked to wait
+// before sending again is returned (see retryAfter).
+func postJson(client *http.Client, service, url string, body []byte) (int, time.Duration, error) {
+	resp, err := client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return 0, 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, resp.Body)
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return resp.StatusCode, 0, nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return resp.StatusCode, retryAfter(resp), fmt.Errorf("%v responded with %v", service, resp.Status)
+	default:
+		return resp.StatusCode, 0, Permanent(fmt.Errorf("%v responded with %v", service, resp.Status))
+	}
+}
+
+// Determine how long the response asked to wait before sending again, defaulting
+// to a second.
+func retryAfter(resp *http.Response) time.Duration {
+	seconds, err := strconv.ParseFloat(resp.Header.Get("Retry-After"), 64)
+	if err != nil || seconds <= 0 {
+		return time.Second
+	}
+
+	return time.Duration(seconds * float64(time.Second))
+}
+
+func (w *Webhook) Notify(report *Report) error {
+	for _, record := range report.MatchRecords() {
+		recordBytes, err := json.Marshal(record)
+		if err != nil {
+			return err
+		}
+
+		if _, _, err := postJson(w.Client, "webhook", w.URL, recordBytes); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,173 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package notify
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/turnage/graw/reddit"
+)
+
+// A type that represents a webhook server responding with each status in turn,
+// recording the bodies POSTed to it.
+type fakeWebhookServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	statuses []int
+	bodies   [][]byte
+}
+
+// Start a webhook server responding with the statuses, then with 200 OK once the
+// statuses run out.
+func startFakeWebhookServer(t *testing.T, statuses ...int) *fakeWebhookServer {
+	t.Helper()
+	s := &fakeWebhookServer{statuses: statuses}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		status := http.StatusOK
+		if len(s.bodies) < len(s.statuses) {
+			status = s.statuses[len(s.bodies)]
+		}
+		s.bodies = append(s.bodies, body)
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+func (s *fakeWebhookServer) requests() [][]byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.bodies
+}
+
+func TestWebhookPayload(t *testing.T) {
+	s := startFakeWebhookServer(t)
+	report := &Report{
+		Subreddit: "buildapcsales",
+		Matches: []Match{
+			{
+				Post:    &reddit.Post{Title: "[RAM] Corsair 16GB $49.99", URL: "https://example.com/ram", Author: "seller", Subreddit: "hardwareswap"},
+				Rules:   []string{"ramunderprice"},
+				Reasons: []string{"$49.99 is at or below $100"},
+				Count:   1,
+			},
+			{
+				Post:  &reddit.Post{Title: "[SSD] Samsung 1TB $79.99", URL: "https://example.com/ssd", Author: "deals"},
+				Rules: []string{"ssdunderprice", "storageperprice"},
+				Count: 2,
+			},
+		},
+	}
+
+	if err := NewWebhook(s.URL, time.Second).Notify(report); err != nil {
+		t.Fatalf("Notify returned an error: %v", err)
+	}
+
+	want := []map[string]interface{}{
+		{
+			"subreddit": "hardwareswap",
+			"title":     "[RAM] Corsair 16GB $49.99",
+			"url":       "https://example.com/ram",
+			"author":    "seller",
+			"rules":     []interface{}{"ramunderprice"},
+			"reasons":   []interface{}{"$49.99 is at or below $100"},
+			"count":     1.0,
+		},
+		{
+			"subreddit": "buildapcsales",
+			"title":     "[SSD] Samsung 1TB $79.99",
+			"url":       "https://example.com/ssd",
+			"author":    "deals",
+			"rules":     []interface{}{"ssdunderprice", "storageperprice"},
+			"reasons":   nil,
+			"count":     2.0,
+		},
+	}
+	bodies := s.requests()
+	if len(bodies) != len(want) {
+		t.Fatalf("webhook received %v requests, want %v", len(bodies), len(want))
+	}
+	for i, body := range bodies {
+		var got map[string]interface{}
+		if err := json.Unmarshal(body, &got); err != nil {
+			t.Fatalf("request %v is not valid JSON: %v", i+1, err)
+		}
+		if !reflect.DeepEqual(got, want[i]) {
+			t.Errorf("request %v = %v, want %v", i+1, got, want[i])
+		}
+	}
+}
+
+func TestWebhookRetry(t *testing.T) {
+	tests := []struct {
+		name         string
+		statuses     []int
+		wantRequests int
+		wantErr      bool
+	}{
+		{"succeeds first", nil, 1, false},
+		{"retries server errors", []int{http.StatusInternalServerError, http.StatusBadGateway}, 3, false},
+		{"retries rate limiting", []int{http.StatusTooManyRequests}, 2, false},
+		{"gives up on server errors", []int{500, 500, 500, 500}, 3, true},
+		{"does not retry client errors", []int{http.StatusBadRequest}, 1, true},
+		{"does not retry missing webhooks", []int{http.StatusNotFound}, 1, true},
+	}
+
+	for _, tt := range tests {
+		s := startFakeWebhookServer(t, tt.statuses...)
+		var sleeps []time.Duration
+		r := newTestRetry(NewWebhook(s.URL, time.Second), 3, &sleeps)
+
+		err := r.Notify(reportOf("[RAM] Corsair 16GB $49.99"))
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%v: Notify returned error %v, want error %v", tt.name, err, tt.wantErr)
+		}
+		if got := len(s.requests()); got != tt.wantRequests {
+			t.Errorf("%v: webhook received %v requests, want %v", tt.name, got, tt.wantRequests)
+		}
+		if len(sleeps) != tt.wantRequests-1 {
+			t.Errorf("%v: backed off %v times, want %v", tt.name, len(sleeps), tt.wantRequests-1)
+		}
+	}
+}
+
+func TestWebhookTimeout(t *testing.T) {
+	release := make(chan struct{})
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer s.Close()
+	defer close(release)
+
+	if err := NewWebhook(s.URL, 50*time.Millisecond).Notify(reportOf("[RAM] Corsair 16GB $49.99")); err == nil {
+		t.Errorf("Notify returned no error for a webhook responding after the timeout")
+	}
+}
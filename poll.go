@@ -0,0 +1,222 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/cavcrosby/rsb/backoff"
+	"github.com/cavcrosby/rsb/logging"
+	"github.com/turnage/graw/reddit"
+)
+
+const (
+	newSort    = "new"
+	hotSort    = "hot"
+	topSort    = "top"
+	risingSort = "rising"
+)
+
+var (
+	defaultFetchLimit     int           = 100
+	maxPageSize           int           = 100
+	defaultFetchAttempts  int           = 3
+	defaultFetchBaseDelay time.Duration = 5 * time.Second
+	defaultFetchMaxDelay  time.Duration = time.Minute
+	defaultSort                         = newSort
+	defaultTimeWindow                   = "day"
+	knownSorts                          = []string{newSort, hotSort, topSort, risingSort}
+	knownTimeWindows                    = []string{"hour", "day", "week", "month", "year", "all"}
+)
+
+// Check the sort (and the time window, used by the top sort) of a subreddit's
+// listing.
+func validateSort(sort, timeWindow string) error {
+	if !stringInArr(sort, knownSorts) {
+		return fmt.Errorf("the following sort is not known: %v", sort)
+	} else if !stringInArr(timeWindow, knownTimeWindows) {
+		return fmt.Errorf("the following time window is not known: %v", timeWindow)
+	}
+
+	return nil
+}
+
+// Get the path and the params of a subreddit's listing in the sort, the time
+// window only being passed for the top sort (e.g. "/r/buildapcsales/top" with
+// t=week).
+func listingPath(subreddit, sort, timeWindow string) (string, map[string]string) {
+	params := map[string]string{"raw_json": "1"}
+	if sort == topSort {
+		params["t"] = timeWindow
+	}
+
+	return "/r/" + subreddit + "/" + sort, params
+}
+
+// A type that defines what is needed to fetch listings from reddit.
+type lister interface {
+	ListingWithParams(path string, params map[string]string) (reddit.Harvest, error)
+}
+
+// A type that polls a subreddit for its newest posts. The poller remembers the
+// newest post it has seen so each poll only returns posts that are new since the
+// last poll. Listings in a sort other than new (e.g. top) are not ordered by
+// when posts were posted, so each poll returns the listing up to the fetch limit
+// instead (the seen store leaving out posts already seen).
+type poller struct {
+	lister     lister
+	subreddit  string
+	fetchLimit int
+	sort       string
+	timeWindow string
+	lastSeen   string
+}
+
+// Fetch the posts that are new since the last poll, newest first. Pages of the
+// listing are fetched (using the 'after' cursor) until the last seen post is
+// reached or the fetch limit is met, whichever comes first. The first poll only
+// marks where the listing currently is and returns no posts.
+func (p *poller) poll() ([]*reddit.Post, error) {
+	sort := p.sort
+	if sort == "" {
+		sort = newSort
+	}
+	chronological := sort == newSort
+
+	var posts []*reddit.Post
+	var after string
+	for len(posts) < p.fetchLimit {
+		pageSize := p.fetchLimit - len(posts)
+		if pageSize > maxPageSize {
+			pageSize = maxPageSize
+		}
+
+		path, params := listingPath(p.subreddit, sort, p.timeWindow)
+		params["limit"] = strconv.Itoa(pageSize)
+		if after != "" {
+			params["after"] = after
+		}
+
+		harvest, err := p.lister.ListingWithParams(path, params)
+		if err != nil {
+			return nil, err
+		} else if len(harvest.Posts) == 0 {
+			break
+		}
+
+		reachedLastSeen := false
+		for _, post := range harvest.Posts {
+			if (chronological && post.Name == p.lastSeen) || len(posts) >= p.fetchLimit {
+				reachedLastSeen = chronological && post.Name == p.lastSeen
+				break
+			}
+			posts = append(posts, post)
+		}
+
+		if reachedLastSeen || (chronological && p.lastSeen == "") {
+			break
+		}
+		after = harvest.Posts[len(harvest.Posts)-1].Name
+	}
+
+	if !chronological {
+		return posts, nil
+	}
+
+	if len(posts) == 0 {
+		return nil, nil
+	}
+
+	firstPoll := p.lastSeen == ""
+	p.lastSeen = posts[0].Name
+	if firstPoll {
+		return nil, nil
+	}
+
+	return posts, nil
+}
+
+// A type that retries failed polls of a subreddit, up to a maximum number of
+// attempts. The delay between attempts starts at the base and doubles after each
+// attempt (up to the max), with jitter so retries of multiple subreddits spread
+// out.
+type fetchRetry struct {
+	maxAttempts int
+	baseDelay   time.Duration
+	maxDelay    time.Duration
+}
+
+// Poll the subreddit, retrying failed polls. The error of the last attempt is
+// returned if every attempt failed, retrying stops early if the context is done.
+func (fr fetchRetry) poll(ctx context.Context, p *poller) ([]*reddit.Post, error) {
+	for attempt := 1; ; attempt++ {
+		posts, err := p.poll()
+		if err == nil || attempt >= fr.maxAttempts {
+			return posts, err
+		}
+
+		delay := backoff.Delay(attempt, fr.baseDelay, fr.maxDelay, nil)
+		logging.Warnf("%v: failed to poll subreddit %v (attempt %v of %v), retrying in %v: %v", progName, p.subreddit, attempt, fr.maxAttempts, delay, err)
+		select {
+		case <-ctx.Done():
+			return nil, err
+		case <-time.After(delay):
+		}
+	}
+}
+
+// A type that adapts the interval between polls. After each poll that yields no
+// matches the interval grows by the multiplier (up to the max), and once a poll
+// yields a match the interval resets back to the base.
+type pollInterval struct {
+	base       time.Duration
+	max        time.Duration
+	multiplier float64
+	current    time.Duration
+}
+
+// Create a poll interval starting at the base interval.
+func newPollInterval(base, max time.Duration, multiplier float64) *pollInterval {
+	return &pollInterval{
+		base:       base,
+		max:        max,
+		multiplier: multiplier,
+		current:    base,
+	}
+}
+
+// Get the interval to wait before the next poll, given whether the last poll
+// yielded a match.
+func (pi *pollInterval) next(matched bool) time.Duration {
+	if matched {
+		pi.current = pi.base
+		return pi.current
+	}
+
+	pi.current = time.Duration(float64(pi.current) * pi.multiplier)
+	if pi.current > pi.max {
+		pi.current = pi.max
+	}
+
+	return pi.current
+}
@@ -0,0 +1,363 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/turnage/graw/reddit"
+)
+
+// A type that represents a subreddit listing served a page at a time, the posts
+// being newest first.
+type fakeLister struct {
+	posts []*reddit.Post
+	paths []string
+	pages int
+	err   error
+}
+
+// Create a fake listing of the number of posts, newest first.
+func newFakeLister(numPosts int) *fakeLister {
+	f := &fakeLister{}
+	for i := numPosts; i > 0; i-- {
+		f.posts = append(f.posts, &reddit.Post{ID: strconv.Itoa(i), Name: fmt.Sprintf("t3_%v", i), Title: fmt.Sprintf("post %v", i)})
+	}
+
+	return f
+}
+
+// Add newer posts to the top of the listing.
+func (f *fakeLister) post(numPosts int) {
+	newest := len(f.posts)
+	var posts []*reddit.Post
+	for i := newest + numPosts; i > newest; i-- {
+		posts = append(posts, &reddit.Post{ID: strconv.Itoa(i), Name: fmt.Sprintf("t3_%v", i), Title: fmt.Sprintf("post %v", i)})
+	}
+	f.posts = append(posts, f.posts...)
+}
+
+func (f *fakeLister) ListingWithParams(path string, params map[string]string) (reddit.Harvest, error) {
+	f.paths = append(f.paths, path)
+	if f.err != nil {
+		return reddit.Harvest{}, f.err
+	}
+	f.pages++
+
+	start := 0
+	if after, ok := params["after"]; ok {
+		for i, p := range f.posts {
+			if p.Name == after {
+				start = i + 1
+			}
+		}
+	}
+
+	limit, err := strconv.Atoi(params["limit"])
+	if err != nil {
+		return reddit.Harvest{}, err
+	}
+
+	end := start + limit
+	if end > len(f.posts) {
+		end = len(f.posts)
+	}
+
+	return reddit.Harvest{Posts: f.posts[start:end]}, nil
+}
+
+func TestPollerFetchLimit(t *testing.T) {
+	tests := []struct {
+		name       string
+		newPosts   int
+		fetchLimit int
+		wantPosts  int
+		wantPages  int
+	}{
+		{"fewer new posts than the limit", 30, 100, 30, 1},
+		{"more new posts than the limit", 150, 50, 50, 1},
+		{"limit spanning pages", 250, 150, 150, 2},
+		{"new posts spanning pages", 120, 300, 120, 2},
+		{"no new posts", 0, 100, 0, 1},
+	}
+
+	for _, tt := range tests {
+		l := newFakeLister(10)
+		p := &poller{lister: l, subreddit: "buildapcsales", fetchLimit: tt.fetchLimit}
+
+		// the first poll only marks where the listing is
+		if posts, err := p.poll(); err != nil || len(posts) != 0 {
+			t.Fatalf("%v: first poll = %v posts, %v, want 0 posts", tt.name, len(posts), err)
+		}
+
+		l.post(tt.newPosts)
+		l.pages = 0
+		posts, err := p.poll()
+		if err != nil {
+			t.Fatalf("%v: poll returned an error: %v", tt.name, err)
+		}
+
+		if len(posts) != tt.wantPosts {
+			t.Errorf("%v: poll returned %v posts, want %v", tt.name, len(posts), tt.wantPosts)
+		}
+		if l.pages != tt.wantPages {
+			t.Errorf("%v: poll fetched %v pages, want %v", tt.name, l.pages, tt.wantPages)
+		}
+		if len(posts) > 0 && posts[0].Name != l.posts[0].Name {
+			t.Errorf("%v: poll returned %v first, want the newest post %v", tt.name, posts[0].Name, l.posts[0].Name)
+		}
+	}
+}
+
+func TestPollIntervalNext(t *testing.T) {
+	pi := newPollInterval(time.Minute, 10*time.Minute, 2)
+
+	// each poll without a match grows the interval up to the max, a match resets it
+	tests := []struct {
+		matched bool
+		want    time.Duration
+	}{
+		{false, 2 * time.Minute},
+		{false, 4 * time.Minute},
+		{false, 8 * time.Minute},
+		{false, 10 * time.Minute},
+		{false, 10 * time.Minute},
+		{true, time.Minute},
+		{false, 2 * time.Minute},
+		{true, time.Minute},
+		{true, time.Minute},
+	}
+
+	for i, tt := range tests {
+		if got := pi.next(tt.matched); got != tt.want {
+			t.Errorf("poll %v: next(%v) = %v, want %v", i+1, tt.matched, got, tt.want)
+		}
+	}
+}
+
+func TestFetchLimitFlag(t *testing.T) {
+	tests := []struct {
+		args      []string
+		wantLimit int
+		wantErr   bool
+	}{
+		{nil, defaultFetchLimit, false},
+		{[]string{"--fetch-limit", "5"}, 5, false},
+		{[]string{"--limit", "5"}, 5, false},
+		{[]string{"-n", "5"}, 5, false},
+		{[]string{"--limit", "0"}, 0, true},
+		{[]string{"--limit", "-5"}, 0, true},
+	}
+
+	for _, tt := range tests {
+		pconfs, err := parseArgs(t, tt.args...)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parsing %q returned error %v, want error %v", tt.args, err, tt.wantErr)
+			continue
+		} else if err != nil {
+			continue
+		}
+		if pconfs.fetchLimit != tt.wantLimit {
+			t.Errorf("parsing %q set the fetch limit to %v, want %v", tt.args, pconfs.fetchLimit, tt.wantLimit)
+		}
+
+		// only the limit's worth of posts are processed when more are available
+		l := newFakeLister(10)
+		p := &poller{lister: l, subreddit: "buildapcsales", fetchLimit: pconfs.fetchLimit}
+		if _, err := p.poll(); err != nil {
+			t.Fatalf("parsing %q: first poll returned an error: %v", tt.args, err)
+		}
+		l.post(tt.wantLimit + 20)
+		posts, err := p.poll()
+		if err != nil {
+			t.Fatalf("parsing %q: poll returned an error: %v", tt.args, err)
+		}
+		if len(posts) != tt.wantLimit {
+			t.Errorf("parsing %q: poll returned %v posts, want %v", tt.args, len(posts), tt.wantLimit)
+		}
+	}
+}
+
+func TestListingPath(t *testing.T) {
+	tests := []struct {
+		sort       string
+		timeWindow string
+		wantPath   string
+		wantParams map[string]string
+	}{
+		{newSort, defaultTimeWindow, "/r/buildapcsales/new", map[string]string{"raw_json": "1"}},
+		{hotSort, defaultTimeWindow, "/r/buildapcsales/hot", map[string]string{"raw_json": "1"}},
+		{risingSort, "week", "/r/buildapcsales/rising", map[string]string{"raw_json": "1"}},
+		{topSort, "hour", "/r/buildapcsales/top", map[string]string{"raw_json": "1", "t": "hour"}},
+		{topSort, "day", "/r/buildapcsales/top", map[string]string{"raw_json": "1", "t": "day"}},
+		{topSort, "week", "/r/buildapcsales/top", map[string]string{"raw_json": "1", "t": "week"}},
+		{topSort, "all", "/r/buildapcsales/top", map[string]string{"raw_json": "1", "t": "all"}},
+	}
+
+	for _, tt := range tests {
+		path, params := listingPath("buildapcsales", tt.sort, tt.timeWindow)
+		if path != tt.wantPath {
+			t.Errorf("listingPath(%q, %q) path = %q, want %q", tt.sort, tt.timeWindow, path, tt.wantPath)
+		}
+		if !reflect.DeepEqual(params, tt.wantParams) {
+			t.Errorf("listingPath(%q, %q) params = %v, want %v", tt.sort, tt.timeWindow, params, tt.wantParams)
+		}
+	}
+}
+
+func TestSortFlags(t *testing.T) {
+	tests := []struct {
+		args           []string
+		wantSort       string
+		wantTimeWindow string
+		wantErr        bool
+	}{
+		{nil, defaultSort, defaultTimeWindow, false},
+		{[]string{"--sort", "hot"}, hotSort, defaultTimeWindow, false},
+		{[]string{"--sort", "top", "--time", "week"}, topSort, "week", false},
+		{[]string{"--sort", "controversial"}, "", "", true},
+		{[]string{"--sort", "top", "--time", "decade"}, "", "", true},
+		{[]string{"--sort", "top", "--watch"}, "", "", true},
+	}
+
+	for _, tt := range tests {
+		pconfs, err := parseArgs(t, tt.args...)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parsing %q returned error %v, want error %v", tt.args, err, tt.wantErr)
+			continue
+		} else if err != nil {
+			continue
+		}
+		if pconfs.sort != tt.wantSort || pconfs.timeWindow != tt.wantTimeWindow {
+			t.Errorf("parsing %q = sort %q, time %q, want sort %q, time %q", tt.args, pconfs.sort, pconfs.timeWindow, tt.wantSort, tt.wantTimeWindow)
+		}
+	}
+}
+
+func TestPollerSort(t *testing.T) {
+	tests := []struct {
+		sort          string
+		wantPath      string
+		wantFirstPoll int
+	}{
+		{"", "/r/buildapcsales/new", 0},
+		{newSort, "/r/buildapcsales/new", 0},
+		{hotSort, "/r/buildapcsales/hot", 10},
+		{topSort, "/r/buildapcsales/top", 10},
+	}
+
+	for _, tt := range tests {
+		l := newFakeLister(10)
+		p := &poller{lister: l, subreddit: "buildapcsales", fetchLimit: 100, sort: tt.sort, timeWindow: "week"}
+
+		// listings not ordered by when posts were posted are returned as a whole
+		posts, err := p.poll()
+		if err != nil {
+			t.Fatalf("poll with sort %q returned an error: %v", tt.sort, err)
+		}
+		if len(posts) != tt.wantFirstPoll {
+			t.Errorf("first poll with sort %q returned %v posts, want %v", tt.sort, len(posts), tt.wantFirstPoll)
+		}
+		for _, path := range l.paths {
+			if path != tt.wantPath {
+				t.Errorf("poll with sort %q fetched %q, want %q", tt.sort, path, tt.wantPath)
+			}
+		}
+	}
+}
+
+func TestFetchRetryPoll(t *testing.T) {
+	errFetch := errors.New("connection reset")
+	tests := []struct {
+		name         string
+		failures     int
+		maxAttempts  int
+		wantAttempts int
+		wantErr      bool
+	}{
+		{"succeeds first", 0, 3, 1, false},
+		{"succeeds after failing", 2, 3, 3, false},
+		{"gives up", 5, 3, 3, true},
+		{"at least one attempt", 5, 0, 1, true},
+	}
+
+	for _, tt := range tests {
+		l := &flakyLister{fakeLister: newFakeLister(10), err: errFetch}
+		p := &poller{lister: l, subreddit: "buildapcsales", fetchLimit: 100}
+		fr := fetchRetry{maxAttempts: tt.maxAttempts, baseDelay: time.Millisecond, maxDelay: time.Millisecond}
+
+		// the first poll only marks where the listing is
+		if _, err := fr.poll(context.Background(), p); err != nil {
+			t.Fatalf("%v: first poll returned an error: %v", tt.name, err)
+		}
+
+		l.post(3)
+		l.attempts, l.failures = 0, tt.failures
+		posts, err := fr.poll(context.Background(), p)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%v: poll returned error %v, want error %v", tt.name, err, tt.wantErr)
+		} else if err == nil && len(posts) != 3 {
+			t.Errorf("%v: poll returned %v posts, want 3", tt.name, len(posts))
+		}
+		if l.attempts != tt.wantAttempts {
+			t.Errorf("%v: polled %v times, want %v", tt.name, l.attempts, tt.wantAttempts)
+		}
+	}
+}
+
+func TestFetchRetryPollCancelled(t *testing.T) {
+	l := &flakyLister{fakeLister: newFakeLister(10), failures: 5, err: errors.New("connection reset")}
+	p := &poller{lister: l, subreddit: "buildapcsales", fetchLimit: 100}
+	fr := fetchRetry{maxAttempts: 5, baseDelay: time.Hour, maxDelay: time.Hour}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := fr.poll(ctx, p); err == nil {
+		t.Errorf("poll returned no error after failing with the context done")
+	}
+	if l.attempts != 1 {
+		t.Errorf("polled %v times, want 1 as retrying stops once the context is done", l.attempts)
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"success", nil, 0},
+		{"partial failure", &partialFailureError{errors.New("failed to poll the following subreddits: hardwareswap")}, partialFailureExitCode},
+		{"wrapped partial failure", fmt.Errorf("run failed: %w", &partialFailureError{errors.New("failed")}), partialFailureExitCode},
+		{"total failure", errors.New("failed to poll every subreddit"), 1},
+	}
+
+	for _, tt := range tests {
+		if got := exitCode(tt.err); got != tt.want {
+			t.Errorf("%v: exitCode(%v) = %v, want %v", tt.name, tt.err, got, tt.want)
+		}
+	}
+}
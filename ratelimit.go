@@ -0,0 +1,116 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"errors"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cavcrosby/rsb/backoff"
+	"github.com/cavcrosby/rsb/logging"
+	"github.com/turnage/graw/reddit"
+)
+
+var (
+	defaultMinRequestInterval time.Duration = time.Second
+	defaultRateLimitAttempts  int           = 5
+	defaultRateLimitBaseDelay time.Duration = 2 * time.Second
+	defaultRateLimitMaxDelay  time.Duration = time.Minute
+)
+
+// Determine if an error from reddit means reddit is rate limiting requests or is
+// having trouble (a 5xx response), both being worth retrying after a delay.
+func rateLimited(err error) bool {
+	for _, redditErr := range []error{reddit.RateLimitErr, reddit.BusyErr, reddit.GatewayErr, reddit.GatewayTimeoutErr} {
+		if errors.Is(err, redditErr) {
+			return true
+		}
+	}
+
+	// other 5xx responses are only reported by their code
+	return strings.HasPrefix(err.Error(), "bad response code: 5")
+}
+
+// A type that represents a lister that spaces its requests to reddit at least the
+// minimum interval apart. Requests reddit rate limits (or fails with a 5xx
+// response) are retried using exponential backoff with jitter, up to a maximum
+// number of attempts.
+type rateLimitedLister struct {
+	lister      lister
+	minInterval time.Duration
+	maxAttempts int
+	baseDelay   time.Duration
+	maxDelay    time.Duration
+	mu          sync.Mutex
+	last        time.Time
+	now         func() time.Time
+	sleep       func(d time.Duration)
+	jitter      func(n int64) int64
+}
+
+// Create a lister that rate limits the lister passed in.
+func newRateLimitedLister(l lister, minInterval time.Duration, maxAttempts int, baseDelay, maxDelay time.Duration) *rateLimitedLister {
+	return &rateLimitedLister{
+		lister:      l,
+		minInterval: minInterval,
+		maxAttempts: maxAttempts,
+		baseDelay:   baseDelay,
+		maxDelay:    maxDelay,
+		now:         time.Now,
+		sleep:       time.Sleep,
+		jitter:      rand.Int63n,
+	}
+}
+
+// Wait until the minimum interval has passed since the last request.
+func (rl *rateLimitedLister) wait() {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	if !rl.last.IsZero() {
+		if delay := rl.minInterval - rl.now().Sub(rl.last); delay > 0 {
+			logging.Debugf("%v: waiting %v before the next request to reddit", progName, delay)
+			rl.sleep(delay)
+		}
+	}
+	rl.last = rl.now()
+}
+
+// Determine how long to wait before the next attempt.
+func (rl *rateLimitedLister) backoff(attempt int) time.Duration {
+	return backoff.Delay(attempt, rl.baseDelay, rl.maxDelay, rl.jitter)
+}
+
+func (rl *rateLimitedLister) ListingWithParams(path string, params map[string]string) (reddit.Harvest, error) {
+	for attempt := 1; ; attempt++ {
+		rl.wait()
+		harvest, err := rl.lister.ListingWithParams(path, params)
+		if err == nil || !rateLimited(err) || attempt >= rl.maxAttempts {
+			return harvest, err
+		}
+
+		delay := rl.backoff(attempt)
+		logging.Debugf("%v: request to reddit for %v failed (%v), retrying in %v", progName, path, err, delay)
+		rl.sleep(delay)
+	}
+}
@@ -0,0 +1,136 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/turnage/graw/reddit"
+)
+
+// A type that represents a lister failing a number of times before listing the
+// posts of a fake listing.
+type flakyLister struct {
+	*fakeLister
+	failures int
+	err      error
+	attempts int
+}
+
+func (f *flakyLister) ListingWithParams(path string, params map[string]string) (reddit.Harvest, error) {
+	f.attempts++
+	if f.attempts <= f.failures {
+		return reddit.Harvest{}, f.err
+	}
+
+	return f.fakeLister.ListingWithParams(path, params)
+}
+
+// Create a rate limited lister whose sleeps are recorded instead of waited out,
+// whose clock only moves forward by sleeping and whose jitter is always 0.
+func newTestRateLimitedLister(l lister, maxAttempts int, sleeps *[]time.Duration) *rateLimitedLister {
+	rl := newRateLimitedLister(l, time.Second, maxAttempts, 2*time.Second, 5*time.Second)
+	now := time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)
+	rl.now = func() time.Time { return now }
+	rl.sleep = func(d time.Duration) {
+		*sleeps = append(*sleeps, d)
+		now = now.Add(d)
+	}
+	rl.jitter = func(n int64) int64 { return 0 }
+
+	return rl
+}
+
+func TestRateLimited(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{reddit.RateLimitErr, true},
+		{reddit.BusyErr, true},
+		{reddit.GatewayErr, true},
+		{reddit.GatewayTimeoutErr, true},
+		{errors.New("bad response code: 500"), true},
+		{errors.New("bad response code: 404"), false},
+		{reddit.PermissionDeniedErr, false},
+		{errors.New("connection reset"), false},
+	}
+
+	for _, tt := range tests {
+		if got := rateLimited(tt.err); got != tt.want {
+			t.Errorf("rateLimited(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestRateLimitedListerBackoff(t *testing.T) {
+	tests := []struct {
+		name         string
+		failures     int
+		err          error
+		maxAttempts  int
+		wantAttempts int
+		wantSleeps   []time.Duration
+		wantErr      bool
+	}{
+		{"succeeds first", 0, reddit.RateLimitErr, 5, 1, nil, false},
+		{"succeeds after rate limits", 3, reddit.RateLimitErr, 5, 4, []time.Duration{time.Second, 2 * time.Second, 2500 * time.Millisecond}, false},
+		{"succeeds after server errors", 1, errors.New("bad response code: 502"), 5, 2, []time.Duration{time.Second}, false},
+		{"gives up", 5, reddit.BusyErr, 3, 3, []time.Duration{time.Second, 2 * time.Second}, true},
+		{"does not retry other errors", 5, reddit.PermissionDeniedErr, 5, 1, nil, true},
+	}
+
+	for _, tt := range tests {
+		l := &flakyLister{fakeLister: newFakeLister(10), failures: tt.failures, err: tt.err}
+		var sleeps []time.Duration
+		rl := newTestRateLimitedLister(l, tt.maxAttempts, &sleeps)
+
+		harvest, err := rl.ListingWithParams("/r/buildapcsales/new", map[string]string{"limit": "5"})
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%v: ListingWithParams returned error %v, want error %v", tt.name, err, tt.wantErr)
+		} else if err == nil && len(harvest.Posts) != 5 {
+			t.Errorf("%v: ListingWithParams returned %v posts, want 5", tt.name, len(harvest.Posts))
+		}
+		if l.attempts != tt.wantAttempts {
+			t.Errorf("%v: lister attempted %v times, want %v", tt.name, l.attempts, tt.wantAttempts)
+		}
+		if !reflect.DeepEqual(sleeps, tt.wantSleeps) {
+			t.Errorf("%v: slept %v, want %v", tt.name, sleeps, tt.wantSleeps)
+		}
+	}
+}
+
+func TestRateLimitedListerInterval(t *testing.T) {
+	var sleeps []time.Duration
+	rl := newTestRateLimitedLister(newFakeLister(10), 5, &sleeps)
+	for i := 0; i < 3; i++ {
+		if _, err := rl.ListingWithParams("/r/buildapcsales/new", map[string]string{"limit": "5"}); err != nil {
+			t.Fatalf("ListingWithParams returned an error: %v", err)
+		}
+	}
+
+	// the first request is sent right away, the others wait out the interval
+	if want := []time.Duration{time.Second, time.Second}; !reflect.DeepEqual(sleeps, want) {
+		t.Errorf("slept %v, want %v", sleeps, want)
+	}
+}
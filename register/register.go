@@ -20,5 +20,26 @@
 package register
 
 import (
+	_ "github.com/cavcrosby/rsb/rule/authorblock"
+	_ "github.com/cavcrosby/rsb/rule/categories"
+	_ "github.com/cavcrosby/rsb/rule/cpuunderprice"
+	_ "github.com/cavcrosby/rsb/rule/domainmatch"
+	_ "github.com/cavcrosby/rsb/rule/externalonly"
+	_ "github.com/cavcrosby/rsb/rule/flairmatch"
+	_ "github.com/cavcrosby/rsb/rule/gpuunderprice"
+	_ "github.com/cavcrosby/rsb/rule/keywordmatch"
+	_ "github.com/cavcrosby/rsb/rule/maxage"
+	_ "github.com/cavcrosby/rsb/rule/maxrank"
+	_ "github.com/cavcrosby/rsb/rule/mindiscount"
+	_ "github.com/cavcrosby/rsb/rule/minscore"
+	_ "github.com/cavcrosby/rsb/rule/perunitprice"
+	_ "github.com/cavcrosby/rsb/rule/pricerange"
+	_ "github.com/cavcrosby/rsb/rule/ramdeal"
+	_ "github.com/cavcrosby/rsb/rule/ramunder100"
 	_ "github.com/cavcrosby/rsb/rule/ramunderprice"
+	_ "github.com/cavcrosby/rsb/rule/regexmatch"
+	_ "github.com/cavcrosby/rsb/rule/scoregain"
+	_ "github.com/cavcrosby/rsb/rule/sellonly"
+	_ "github.com/cavcrosby/rsb/rule/storageperprice"
+	_ "github.com/cavcrosby/rsb/rule/subredditmatch"
 )
@@ -0,0 +1,81 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package register
+
+import (
+	"testing"
+
+	"github.com/cavcrosby/rsb/rule"
+)
+
+func TestRegisteredRules(t *testing.T) {
+	tests := []string{
+		"authorblock",
+		"categories",
+		"cpuunderprice",
+		"domainmatch",
+		"externalonly",
+		"flairmatch",
+		"gpuunderprice",
+		"keywordmatch",
+		"maxage",
+		"maxrank",
+		"mindiscount",
+		"minscore",
+		"perunitprice",
+		"pricerange",
+		"ramdeal",
+		"ramunder100",
+		"ramunderprice",
+		"regexmatch",
+		"scoregain",
+		"sellonly",
+		"storageperprice",
+		"subredditmatch",
+	}
+
+	for _, ruleName := range tests {
+		r, err := rule.RuleInRuleRegistry(ruleName)
+		if err != nil {
+			t.Errorf("RuleInRuleRegistry(%v) returned an error: %v", ruleName, err)
+			continue
+		}
+		if r.Name() != ruleName {
+			t.Errorf("RuleInRuleRegistry(%v).Name() = %v, want %v", ruleName, r.Name(), ruleName)
+		}
+	}
+
+	if got := len(rule.GetAllRegisteredRules()); got != len(tests) {
+		t.Errorf("GetAllRegisteredRules() has %v rules, want %v", got, len(tests))
+	}
+}
+
+func TestRuleDescriptions(t *testing.T) {
+	descriptions := make(map[string]string)
+	for _, r := range rule.GetAllRegisteredRules() {
+		if r.Description() == "" {
+			t.Errorf("%v has an empty description", r.Name())
+		}
+		if other, ok := descriptions[r.Description()]; ok {
+			t.Errorf("%v and %v have the same description: %q", other, r.Name(), r.Description())
+		}
+		descriptions[r.Description()] = r.Name()
+	}
+}
@@ -20,28 +20,45 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"io/fs"
 	"io/ioutil"
-	"log"
 	"net/smtp"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"regexp"
 	"sort"
 	"strconv"
 	"strings"
+	"syscall"
+	"time"
 
+	"github.com/cavcrosby/rsb/engine"
+	"github.com/cavcrosby/rsb/logging"
+	"github.com/cavcrosby/rsb/notify"
 	_ "github.com/cavcrosby/rsb/register"
 	"github.com/cavcrosby/rsb/rule"
-	"github.com/turnage/graw"
+	"github.com/cavcrosby/rsb/store"
 	"github.com/turnage/graw/reddit"
 	"github.com/urfave/cli/v2"
 )
 
 const (
-	progName = "rsb"
+	progName               = "rsb"
+	partialFailureExitCode = 2
+)
+
+const (
+	emailNotifier   = "email"
+	socketNotifier  = "socket"
+	webhookNotifier = "webhook"
+	discordNotifier = "discord"
+	defaultNotifier = emailNotifier
 )
 
 const (
@@ -70,32 +87,92 @@ const (
 )
 
 var (
-	defaultAgentPath            = strings.Join([]string{"./", progName, ".agent"}, "")
-	defaultPostThreshold        = 5
-	errfoundPost         error  = errors.New("found a reddit post")
-	progConfig           string = strings.Join([]string{progName, ".json"}, "")
+	defaultAgentPath     = strings.Join([]string{"./", progName, agentFileExt}, "")
+	defaultPostThreshold = 5
+	defaultPrefilters    = []prefilter{notDistinguished, notHidden}
+	defaultEvaluateSince = "7d"
+	defaultSubreddits    = []string{"buildapcsales"}
+	knownNotifiers       = []string{emailNotifier, socketNotifier, webhookNotifier, discordNotifier}
+	defaultPollInterval  = time.Minute
+	defaultSeenRetention = 7 * 24 * time.Hour
+	agentFileExt         = ".agent"
+	errHistoryNeeded     = errors.New("rules comparing posts with when they were first seen (e.g. scoregain) need the post history, enable history in the configuration file")
+	historyFileExt       = ".history.jsonl"
+	progConfigExt        = ".json"
+	progConfigDirPerms   = os.ModeDir | (OS_USER_R | OS_USER_W | OS_USER_X | OS_GROUP_R | OS_GROUP_X | OS_OTH_R | OS_OTH_X)
+	progConfigPerms      = os.FileMode(OS_USER_R | OS_USER_W | OS_GROUP_R | OS_OTH_R)
+	reInstanceName       = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+	seenFileExt          = ".seen.json"
 )
 
 // A custom callback handler in the event improper cli flag/flag arguments or
 // arguments are passed in.
 var CustomOnUsageErrorFunc cli.OnUsageErrorFunc = func(context *cli.Context, err error, isSubcommand bool) error {
 	cli.ShowAppHelp(context)
-	log.Panic(err)
 	return err
 }
 
-// A type that represents a post handler for graw. Mainly meant to store posts
-// received from the 'subreddit' event stream.
+// A type that represents a check a post must pass before it is queued for
+// matching against the rules.
+type prefilter func(p *reddit.Post) bool
+
+// Exclude posts that are distinguished by moderators or admins, these are never
+// deals (e.g. subreddit announcements).
+func notDistinguished(p *reddit.Post) bool {
+	return p.Distinguished == ""
+}
+
+// Exclude posts the bot's account has hidden on reddit, these were already
+// dismissed by the user.
+func notHidden(p *reddit.Post) bool {
+	return !p.Hidden
+}
+
+// A type that represents a post handler. Mainly meant to store posts received
+// from polling a subreddit.
+//
+// Posts are marked as seen once matched. If rematch is set, only posts that
+// matched are marked as seen, posts that did not match being matched again when
+// seen again (e.g. for rules comparing posts with when they were first seen).
 type postGather struct {
 	bot             reddit.Bot
+	postContexts    map[string]rule.PostContext
 	postQueue       []*reddit.Post
 	postThreshold   int
+	prefilters      []prefilter
+	rematch         bool
+	seen            store.SeenStore
 	stickyPostQueue map[string]string
 }
 
+// Determine if the post passes every prefilter.
+func (g *postGather) passesPrefilters(p *reddit.Post) bool {
+	for _, pf := range g.prefilters {
+		if !pf(p) {
+			return false
+		}
+	}
+
+	return true
+}
+
 // Empty out the post queue.
 func (g *postGather) flushPostQueue() {
 	g.postQueue = nil
+	g.postContexts = nil
+}
+
+// Record the context of a post, to be used when matching the post.
+func (g *postGather) setPostContext(id string, pctx rule.PostContext) {
+	if g.postContexts == nil {
+		g.postContexts = make(map[string]rule.PostContext)
+	}
+	g.postContexts[id] = pctx
+}
+
+// Return the contexts of the posts in the post queue.
+func (g *postGather) getPostContexts() map[string]rule.PostContext {
+	return g.postContexts
 }
 
 // Return the post queue.
@@ -108,26 +185,142 @@ func (g *postGather) atPostThreshold() bool {
 	return len(g.postQueue) >= g.postThreshold
 }
 
+// Determine if the post is in the post queue.
+func (g *postGather) inPostQueue(id string) bool {
+	for _, p := range g.postQueue {
+		if p.ID == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Mark the posts as seen, this being done once the posts have been matched so
+// posts still in the post queue are not lost if the program stops.
+func (g *postGather) markSeen(posts []*reddit.Post) {
+	if g.seen == nil {
+		return
+	}
+
+	for _, p := range posts {
+		g.seen.Mark(p.ID)
+	}
+}
+
+// Mark the posts of the post queue that were matched as seen, or every post if
+// posts are not matched again.
+func (g *postGather) markMatched(postQueue []*reddit.Post, matches []*engine.PostMatch) {
+	if !g.rematch {
+		g.markSeen(postQueue)
+		return
+	}
+
+	for _, match := range matches {
+		g.markSeen([]*reddit.Post{match.Post})
+	}
+}
+
+// Set when the posts were first seen (and their score back then) in their
+// contexts, from the post history. The posts seen for the first time are
+// returned.
+func (g *postGather) recallFirstSeen(history *store.PostHistory, posts []*reddit.Post) ([]*reddit.Post, error) {
+	firstSeen, err := history.FirstSeen(posts)
+	if err != nil {
+		return posts, err
+	}
+
+	var firstSightings []*reddit.Post
+	for _, p := range posts {
+		record, ok := firstSeen[p.ID]
+		if !ok {
+			firstSightings = append(firstSightings, p)
+			continue
+		}
+
+		pctx := g.postContexts[p.ID]
+		pctx.FirstSeen, pctx.FirstScore = record.SeenAt, record.Post.Score
+		g.setPostContext(p.ID, pctx)
+	}
+
+	return firstSightings, nil
+}
+
 func (g *postGather) Post(p *reddit.Post) error {
-	if _, ok := g.stickyPostQueue[p.ID]; !p.Stickied || !ok {
+	if (g.seen != nil && g.seen.Has(p.ID)) || g.inPostQueue(p.ID) {
+		return nil
+	}
+
+	if _, ok := g.stickyPostQueue[p.ID]; (!p.Stickied || !ok) && g.passesPrefilters(p) {
 		g.postQueue = append(g.postQueue, p)
+	} else {
+		// posts left out by the prefilters are done with
+		g.markSeen([]*reddit.Post{p})
 	}
 
-	return errfoundPost
+	return nil
 }
 
 // A type used to represent the configuration file of the program.
 //
 // Example (includes RuleConfig(s)):
 // {
+//     "version": 2,
 //     "sendmail_from": "foo@bar.com",
 //     "sendmail_to": "baz@bar.com",
 //     "password": "foobarbaz",
 //     "smtp_addr": "smtp.bar.com",
 //     "smtp_port": "1234",
+//     "subreddits": ["buildapcsales", "hardwareswap"],
+//     "trustedDomains": ["newegg.com"],
+//     "trustBoost": 5,
+//     "collapseReposts": true,
+//     "matchMode": "any",
+//     "denoiseTitles": true,
+//     "workers": 4,
+//     "pollInterval": {
+//         "base": "1m",
+//         "max": "15m",
+//         "multiplier": 2
+//     },
+//     "seenStore": {
+//         "backend": "file",
+//         "path": "/home/foo/.config/rsb/seen.json"
+//     },
+//     "history": {
+//         "enabled": true,
+//         "path": "/home/foo/.config/rsb/history.jsonl"
+//     },
+//     "socket": {
+//         "path": "/tmp/rsb.sock"
+//     },
+//     "webhook": {
+//         "url": "https://example.com/rsb",
+//         "timeout": "10s"
+//     },
+//     "discord": {
+//         "url": "https://discord.com/api/webhooks/123/abc"
+//     },
+//     "notifyRetry": {
+//         "maxAttempts": 3,
+//         "baseDelay": "1s",
+//         "maxDelay": "30s"
+//     },
+//     "fetchRetry": {
+//         "maxAttempts": 3,
+//         "baseDelay": "5s",
+//         "maxDelay": "1m"
+//     },
+//     "rateLimit": {
+//         "minInterval": "2s",
+//         "maxAttempts": 5,
+//         "baseDelay": "2s",
+//         "maxDelay": "1m"
+//     },
 //     "rules": [
 //         {
 //             "id": "ramunderprice",
+//             "notifier": "email",
 //             "configs": {
 //                 "price": 100
 //             }
@@ -135,36 +328,285 @@ func (g *postGather) Post(p *reddit.Post) error {
 //     ]
 // }
 //
+// The same tree can be written as YAML in a file with a .yaml or .yml extension.
+// Environment variables take precedence over the file (e.g. RSB_SUBREDDITS or
+// RSB_RULE_RAMUNDERPRICE_PRICE), see applyEnvOverrides. Command line flags take
+// precedence over both.
+//
+// Files of an older version are migrated to the current version when loaded,
+// see migrateConfig. The settings of how posts are matched (e.g. the rules) are
+// those of engine.Config, embedded so their keys stay at the top level.
+//
 type configTree struct {
-	SendMailFrom string       `json:"sendmail_from"`
-	SendMailTo   string       `json:"sendmail_to"`
-	Password     string       `json:"password"`
-	SmtpAddr     string       `json:"smtp_addr"`
-	SmtpPort     string       `json:"smtp_port"`
-	RuleConfigs  []RuleConfig `json:"rules"`
+	Version      int             `json:"version"`
+	SendMailFrom string          `json:"sendmail_from"`
+	SendMailTo   string          `json:"sendmail_to"`
+	Password     string          `json:"password"`
+	SmtpAddr     string          `json:"smtp_addr"`
+	SmtpPort     string          `json:"smtp_port"`
+	Subreddits   []string        `json:"subreddits"`
+	PollInterval pollConfig      `json:"pollInterval"`
+	SeenStore    seenConfig      `json:"seenStore"`
+	History      historyConfig   `json:"history"`
+	Socket       socketConfig    `json:"socket"`
+	Webhook      webhookConfig   `json:"webhook"`
+	Discord      webhookConfig   `json:"discord"`
+	NotifyRetry  retryConfig     `json:"notifyRetry"`
+	FetchRetry   retryConfig     `json:"fetchRetry"`
+	RateLimit    rateLimitConfig `json:"rateLimit"`
+	engine.Config
+}
+
+// A type used to configure the socket notifier, which writes matches as JSON
+// records to the Unix domain socket at the path. The notifier is only available
+// if the path is set.
+type socketConfig struct {
+	Path string `json:"path"`
+}
+
+// A type used to configure the webhook notifier, which POSTs each match as a
+// JSON record to the URL, or the discord notifier, which sends each match to the
+// discord webhook URL as an embed. Either notifier is only available if its URL
+// is set. The timeout is a duration (e.g. "10s").
+type webhookConfig struct {
+	URL     string `json:"url"`
+	Timeout string `json:"timeout"`
+}
+
+// Get the timeout of a request to the webhook, defaulting to the notifier's
+// default. The key is the webhook's key in the configTree, used in errors.
+func (wc webhookConfig) timeout(key string) (time.Duration, error) {
+	if wc.Timeout == "" {
+		return notify.DefaultWebhookTimeout, nil
+	}
+
+	timeout, err := time.ParseDuration(wc.Timeout)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %v timeout: %v", key, err)
+	} else if timeout <= 0 {
+		return 0, fmt.Errorf("%v timeout must be positive", key)
+	}
+
+	return timeout, nil
+}
+
+// A type used to configure how failed notifications (or subreddit fetches) are
+// retried. Delays are durations (e.g. "1s", "500ms").
+type retryConfig struct {
+	MaxAttempts int    `json:"maxAttempts"`
+	BaseDelay   string `json:"baseDelay"`
+	MaxDelay    string `json:"maxDelay"`
 }
 
-// A type used to serve as a frontend to allow certain rules to be selected
-// for use and to modify the rule's behavior to some extent through custom
-// configurations. This configuration is made available through configTree.
-type RuleConfig struct {
-	ID      string                 `json:"id"`
-	Configs map[string]interface{} `json:"configs"`
+// Get the maximum number of attempts and the delays from the retry
+// configurations, using the defaults passed in for configurations that are not
+// set. The key is the retry's key in the configTree, used in errors.
+func (rc retryConfig) settings(key string, maxAttempts int, baseDelay, maxDelay time.Duration) (int, time.Duration, time.Duration, error) {
+	var err error
+	if rc.MaxAttempts > 0 {
+		maxAttempts = rc.MaxAttempts
+	}
+	if rc.BaseDelay != "" {
+		if baseDelay, err = time.ParseDuration(rc.BaseDelay); err != nil {
+			return 0, 0, 0, fmt.Errorf("invalid %v baseDelay: %v", key, err)
+		}
+	}
+	if rc.MaxDelay != "" {
+		if maxDelay, err = time.ParseDuration(rc.MaxDelay); err != nil {
+			return 0, 0, 0, fmt.Errorf("invalid %v maxDelay: %v", key, err)
+		}
+	}
+
+	return maxAttempts, baseDelay, maxDelay, nil
+}
+
+// Create the retrying of failed subreddit fetches from the configTree.
+func (ct configTree) fetchRetry() (fetchRetry, error) {
+	maxAttempts, baseDelay, maxDelay, err := ct.FetchRetry.settings(
+		"fetchRetry",
+		defaultFetchAttempts,
+		defaultFetchBaseDelay,
+		defaultFetchMaxDelay,
+	)
+	if err != nil {
+		return fetchRetry{}, err
+	} else if baseDelay <= 0 {
+		return fetchRetry{}, errors.New("fetchRetry baseDelay must be positive")
+	} else if maxDelay < baseDelay {
+		return fetchRetry{}, errors.New("fetchRetry maxDelay must not be less than the baseDelay")
+	}
+
+	return fetchRetry{maxAttempts: maxAttempts, baseDelay: baseDelay, maxDelay: maxDelay}, nil
+}
+
+// A type used to configure how requests to reddit are rate limited. Requests are
+// spaced at least the minimum interval apart, and requests reddit rate limits (or
+// fails with a 5xx response) are retried with exponential backoff up to the
+// maximum number of attempts. Intervals and delays are durations (e.g. "2s").
+type rateLimitConfig struct {
+	MinInterval string `json:"minInterval"`
+	MaxAttempts int    `json:"maxAttempts"`
+	BaseDelay   string `json:"baseDelay"`
+	MaxDelay    string `json:"maxDelay"`
+}
+
+// Create a lister rate limiting the lister passed in from the rate limit
+// configurations, using the defaults for configurations that are not set.
+func (rc rateLimitConfig) rateLimit(l lister) (*rateLimitedLister, error) {
+	var err error
+	minInterval := defaultMinRequestInterval
+	if rc.MinInterval != "" {
+		if minInterval, err = time.ParseDuration(rc.MinInterval); err != nil {
+			return nil, fmt.Errorf("invalid rateLimit minInterval: %v", err)
+		}
+	}
+
+	maxAttempts := defaultRateLimitAttempts
+	if rc.MaxAttempts != 0 {
+		maxAttempts = rc.MaxAttempts
+	}
+
+	baseDelay := defaultRateLimitBaseDelay
+	if rc.BaseDelay != "" {
+		if baseDelay, err = time.ParseDuration(rc.BaseDelay); err != nil {
+			return nil, fmt.Errorf("invalid rateLimit baseDelay: %v", err)
+		}
+	}
+
+	maxDelay := defaultRateLimitMaxDelay
+	if rc.MaxDelay != "" {
+		if maxDelay, err = time.ParseDuration(rc.MaxDelay); err != nil {
+			return nil, fmt.Errorf("invalid rateLimit maxDelay: %v", err)
+		}
+	}
+
+	if minInterval < 0 {
+		return nil, errors.New("rateLimit minInterval must not be negative")
+	} else if maxAttempts < 1 {
+		return nil, errors.New("rateLimit maxAttempts must be at least 1")
+	} else if baseDelay <= 0 {
+		return nil, errors.New("rateLimit baseDelay must be positive")
+	} else if maxDelay < baseDelay {
+		return nil, errors.New("rateLimit maxDelay must not be less than the baseDelay")
+	}
+
+	return newRateLimitedLister(l, minInterval, maxAttempts, baseDelay, maxDelay), nil
+}
+
+// A type used to configure the interval between polls. The interval starts at
+// the base and is multiplied by the multiplier (up to the max) after each poll
+// without a match, resetting to the base after a poll with a match. Intervals are
+// durations (e.g. "1m", "30s").
+type pollConfig struct {
+	Base       string  `json:"base"`
+	Max        string  `json:"max"`
+	Multiplier float64 `json:"multiplier"`
+}
+
+// Create the poll interval from the poll configurations, defaulting to a fixed
+// interval.
+func (pc pollConfig) pollInterval() (*pollInterval, error) {
+	var err error
+	base := defaultPollInterval
+	if pc.Base != "" {
+		if base, err = time.ParseDuration(pc.Base); err != nil {
+			return nil, fmt.Errorf("invalid pollInterval base: %v", err)
+		}
+	}
+
+	max := base
+	if pc.Max != "" {
+		if max, err = time.ParseDuration(pc.Max); err != nil {
+			return nil, fmt.Errorf("invalid pollInterval max: %v", err)
+		}
+	}
+
+	multiplier := 1.0
+	if pc.Multiplier != 0 {
+		multiplier = pc.Multiplier
+	}
+
+	if base <= 0 {
+		return nil, errors.New("pollInterval base must be positive")
+	} else if max < base {
+		return nil, errors.New("pollInterval max must not be less than the base")
+	} else if multiplier < 1 {
+		return nil, errors.New("pollInterval multiplier must be at least 1")
+	}
+
+	return newPollInterval(base, max, multiplier), nil
+}
+
+// A type used to configure which backend stores the posts already seen, so a post
+// is only reported once across runs. The backend is either "file" (the default)
+// or "memory", the path defaulting to a file next to the program configuration
+// file.
+type seenConfig struct {
+	Backend string `json:"backend"`
+	Path    string `json:"path"`
+}
+
+// A type used to configure the recording of every post seen, so posts can be
+// replayed later (see the evaluate command). The path defaults to a file next to
+// the program configuration file.
+type historyConfig struct {
+	Enabled bool   `json:"enabled"`
+	Path    string `json:"path"`
+}
+
+// Get the path of the post history file, using the default path if no path is
+// configured.
+func (hc historyConfig) historyPath(defaultPath string) string {
+	if hc.Path != "" {
+		return hc.Path
+	}
+
+	return defaultPath
 }
 
 // A type used to store command flag argument values and argument values.
 type progConfigs struct {
 	agentPath        string
 	altConfigPath    string
+	colorMode        string
+	diffConfig       bool
+	diffConfigPaths  []string
+	dryRun           bool
+	evaluate         bool
+	evaluateSince    string
+	explain          bool
+	explainOpts      explainOptions
 	exportConfig     bool
+	fetchLimit       int
+	format           string
+	healthAddr       string
 	helpFlagPassedIn bool
+	httpAddr         string
+	initConfig       bool
+	initOpts         initOptions
+	instance         string
+	listRules        bool
+	listRulesJson    bool
+	logLevel         string
+	metricsAddr      string
+	outputPath       string
+	pidFilePath      string
+	resetSeen        bool
 	showConfigPath   bool
-	subredditName    string
+	sort             string
+	strict           bool
+	subredditNames   []string
+	timeWindow       string
+	trace            bool
+	validateConfig   bool
+	version          bool
+	watch            bool
+	webhookUrl       string
 }
 
 // Interpret the command arguments passed in. Saving particular flag/flag arguments
 // of interest into 'pconfs'.
-func (pconfs *progConfigs) parseCmdArgs() {
+func (pconfs *progConfigs) parseCmdArgs() error {
 	var localOsArgs []string = os.Args
 
 	for i, val := range localOsArgs {
@@ -180,11 +622,16 @@ func (pconfs *progConfigs) parseCmdArgs() {
 	app := &cli.App{
 		Name:            progName,
 		Usage:           "searches Reddit posts and matches posts that meet known rules",
-		UsageText:       strings.Join([]string{progName, " [global options] SUBREDDIT_NAME"}, ""),
+		UsageText:       strings.Join([]string{progName, " [global options] [SUBREDDIT_NAME...]"}, ""),
 		Description:     strings.Join([]string{progName, " - A (for) Reddit Search Bot"}, ""),
 		HideHelpCommand: true,
 		OnUsageError:    CustomOnUsageErrorFunc,
 		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:        "dry-run",
+				Usage:       "prints the rules and subreddits that would be used, without contacting reddit",
+				Destination: &pconfs.dryRun,
+			},
 			&cli.BoolFlag{
 				Name:        "export-config",
 				Aliases:     []string{"e"},
@@ -199,90 +646,337 @@ func (pconfs *progConfigs) parseCmdArgs() {
 			},
 			&cli.PathFlag{
 				Name:        "config-path",
-				Aliases:     []string{"c"},
+				Aliases:     []string{"c", "config"},
 				Value:       pconfs.altConfigPath,
-				Usage:       "alternative `PATH` for the program's configuration file",
+				Usage:       "alternative `PATH` for the program's configuration file, created if it does not exist",
 				Destination: &pconfs.altConfigPath,
 			},
 			&cli.PathFlag{
 				Name:        "agent-path",
-				Aliases:     []string{"a"},
+				Aliases:     []string{"a", "agent-file"},
 				Value:       defaultAgentPath,
-				Usage:       "alternative `PATH` for agent configuration file",
+				Usage:       "alternative `PATH` for agent configuration file (defaults to the working directory's, then the configuration directory's)",
 				Destination: &pconfs.agentPath,
 			},
+			&cli.StringFlag{
+				Name:        "instance",
+				Aliases:     []string{"i"},
+				Usage:       "run as the instance `NAME`, namespacing the program's files (e.g. " + progName + "-NAME" + progConfigExt + ")",
+				Destination: &pconfs.instance,
+			},
+			&cli.StringFlag{
+				Name:        "color",
+				Value:       colorAuto,
+				Usage:       "colors matches printed to the terminal, `WHEN` is auto, always or never",
+				Destination: &pconfs.colorMode,
+			},
+			&cli.BoolFlag{
+				Name:        "trace",
+				Usage:       "writes how every post was evaluated against every rule as JSON to stderr",
+				Destination: &pconfs.trace,
+			},
+			&cli.BoolFlag{
+				Name:        "watch",
+				Usage:       "stream new posts as they are posted vs polling, matching each post as it arrives",
+				Destination: &pconfs.watch,
+			},
+			&cli.BoolFlag{
+				Name:        "reset-seen",
+				Usage:       "forget the posts already seen before polling, so they can be matched again",
+				Destination: &pconfs.resetSeen,
+			},
+			&cli.IntFlag{
+				Name:        "fetch-limit",
+				Aliases:     []string{"limit", "n"},
+				Value:       defaultFetchLimit,
+				Usage:       "fetch at most `N` posts from each subreddit each poll",
+				Destination: &pconfs.fetchLimit,
+			},
+			&cli.StringFlag{
+				Name:        "sort",
+				Value:       defaultSort,
+				Usage:       "poll each subreddit's listing in `SORT` order, this being new, hot, top or rising",
+				Destination: &pconfs.sort,
+			},
+			&cli.StringFlag{
+				Name:        "time",
+				Value:       defaultTimeWindow,
+				Usage:       "the `WINDOW` of the top sort, this being hour, day, week, month, year or all",
+				Destination: &pconfs.timeWindow,
+			},
+			&cli.StringFlag{
+				Name:        "log-level",
+				Value:       logging.DefaultLevel.String(),
+				Usage:       "log messages at or above `LEVEL`, this being debug, info, warn or error",
+				Destination: &pconfs.logLevel,
+			},
+			&cli.PathFlag{
+				Name:        "pidfile",
+				Usage:       "write the program's process id to `PATH`",
+				Destination: &pconfs.pidFilePath,
+			},
+			&cli.StringSliceFlag{
+				Name:    "subreddit",
+				Aliases: []string{"r"},
+				Usage:   "watch the subreddit `NAME`, can be passed more than once (defaults to the configuration file's subreddits)",
+			},
+			&cli.StringFlag{
+				Name:        "output",
+				Aliases:     []string{"o"},
+				Usage:       "append the matches to the file at `PATH`, as JSON or CSV depending on the file extension",
+				Destination: &pconfs.outputPath,
+			},
+			&cli.StringFlag{
+				Name:        "format",
+				Usage:       "write the output file as `FORMAT`, this being json or csv (overrides the file extension)",
+				Destination: &pconfs.format,
+			},
+			&cli.BoolFlag{
+				Name:        "version",
+				Aliases:     []string{"v"},
+				Usage:       "print the version of the program",
+				Destination: &pconfs.version,
+			},
+			&cli.StringFlag{
+				Name:        "webhook",
+				Usage:       "POST the matches of rules using the webhook notifier to `URL` (defaults to the configuration file's webhook url)",
+				Destination: &pconfs.webhookUrl,
+			},
+			&cli.StringFlag{
+				Name:        "health-addr",
+				Usage:       "serve the program's health over http at `ADDR` (e.g. :8081/healthz)",
+				Destination: &pconfs.healthAddr,
+			},
+			&cli.StringFlag{
+				Name:        "http-addr",
+				Usage:       "serve the recent matches and the rules as JSON over http at `ADDR` (e.g. :8080)",
+				Destination: &pconfs.httpAddr,
+			},
+			&cli.StringFlag{
+				Name:        "metrics-addr",
+				Usage:       "serve the program's metrics in the Prometheus format over http at `ADDR` (e.g. :9090/metrics)",
+				Destination: &pconfs.metricsAddr,
+			},
+		},
+		Commands: []*cli.Command{
+			{
+				Name:      "diff-config",
+				Usage:     "shows the rules added, removed and changed between two configuration files",
+				ArgsUsage: "OLD_CONFIG_PATH NEW_CONFIG_PATH",
+				Action: func(context *cli.Context) error {
+					if context.NArg() != 2 {
+						cli.ShowCommandHelp(context, "diff-config")
+						return errors.New("OLD_CONFIG_PATH and NEW_CONFIG_PATH arguments are required")
+					}
+
+					pconfs.diffConfig = true
+					pconfs.diffConfigPaths = context.Args().Slice()
+					return nil
+				},
+			},
+			{
+				Name:  "evaluate",
+				Usage: "replays the posts in the post history against the program's configuration",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:        "since",
+						Value:       defaultEvaluateSince,
+						Usage:       "replay posts seen within `DURATION` (e.g. 7d, 12h)",
+						Destination: &pconfs.evaluateSince,
+					},
+				},
+				Action: func(context *cli.Context) error {
+					pconfs.evaluate = true
+					return nil
+				},
+			},
+			{
+				Name:  "explain",
+				Usage: "runs the rules against a post with the title, printing each rule's verdict",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:        "title",
+						Usage:       "the `TITLE` of the post",
+						Required:    true,
+						Destination: &pconfs.explainOpts.title,
+					},
+					&cli.StringFlag{
+						Name:        "subreddit",
+						Usage:       "the subreddit `NAME` the post is in",
+						Destination: &pconfs.explainOpts.subreddit,
+					},
+					&cli.StringFlag{
+						Name:        "author",
+						Usage:       "the `NAME` of the post's author",
+						Destination: &pconfs.explainOpts.author,
+					},
+					&cli.StringFlag{
+						Name:        "price",
+						Usage:       "append `PRICE` (e.g. 59.99 or €59,99) to the title",
+						Destination: &pconfs.explainOpts.price,
+					},
+				},
+				Action: func(context *cli.Context) error {
+					pconfs.explain = true
+					return nil
+				},
+			},
+			{
+				Name:  "init",
+				Usage: "scaffolds the program's configuration file, prompting for what is not passed in",
+				Flags: []cli.Flag{
+					&cli.StringSliceFlag{
+						Name:    "subreddit",
+						Aliases: []string{"r"},
+						Usage:   "watch the subreddit `NAME`, can be passed more than once",
+					},
+					&cli.StringFlag{
+						Name:        "rule",
+						Usage:       "start with the rule `ID`",
+						Destination: &pconfs.initOpts.ruleID,
+					},
+					&cli.StringFlag{
+						Name:        "configs",
+						Usage:       "configure the starting rule with `JSON`",
+						Destination: &pconfs.initOpts.ruleConfigs,
+					},
+					&cli.BoolFlag{
+						Name:        "force",
+						Usage:       "overwrite the configuration file if it exists",
+						Destination: &pconfs.initOpts.force,
+					},
+				},
+				Action: func(context *cli.Context) error {
+					pconfs.initConfig = true
+					pconfs.initOpts.subreddits = context.StringSlice("subreddit")
+					return nil
+				},
+			},
+			{
+				Name:  "list-rules",
+				Usage: "lists the rules that can be used in the program's configuration file",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:        "json",
+						Usage:       "list the rules as JSON",
+						Destination: &pconfs.listRulesJson,
+					},
+				},
+				Action: func(context *cli.Context) error {
+					pconfs.listRules = true
+					return nil
+				},
+			},
+			{
+				Name:  "version",
+				Usage: "prints the version of the program",
+				Action: func(context *cli.Context) error {
+					pconfs.version = true
+					return nil
+				},
+			},
+			{
+				Name:  "validate-config",
+				Usage: "checks the program's configuration file for errors and warnings",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:        "strict",
+						Usage:       "treat warnings as errors",
+						Destination: &pconfs.strict,
+					},
+				},
+				Action: func(context *cli.Context) error {
+					pconfs.validateConfig = true
+					return nil
+				},
+			},
 		},
 		Action: func(context *cli.Context) error {
-			if context.NArg() < 1 && !pconfs.showConfigPath && !pconfs.exportConfig {
+			if pconfs.fetchLimit < 1 {
 				cli.ShowAppHelp(context)
-				log.Panic(errors.New("SUBREDDIT_NAME argument is required"))
+				return errors.New("fetch-limit must be at least 1")
+			}
+			if err := validateSort(pconfs.sort, pconfs.timeWindow); err != nil {
+				cli.ShowAppHelp(context)
+				return err
+			} else if pconfs.watch && pconfs.sort != newSort {
+				cli.ShowAppHelp(context)
+				return errors.New("watch only streams new posts, sort cannot be used with it")
 			}
 
-			pconfs.subredditName = context.Args().Get(0)
+			pconfs.subredditNames = append(context.StringSlice("subreddit"), context.Args().Slice()...)
 			return nil
 		},
 	}
 
 	sort.Sort(cli.FlagsByName(app.Flags))
-	app.Run(localOsArgs)
-	if pconfs.helpFlagPassedIn {
-		os.Exit(0)
-	}
+	return app.Run(localOsArgs)
 }
 
-// Look to see if the string is in the string array.
-func stringInArr(strArg string, arr []string) bool {
-	for _, val := range arr {
-		if val == strArg {
-			return true
+// Parse a duration that may also be expressed in days (e.g. "7d").
+func parseSince(since string) (time.Duration, error) {
+	if strings.HasSuffix(since, "d") {
+		days, err := strconv.ParseFloat(strings.TrimSuffix(since, "d"), 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration: %v", since)
 		}
+		return time.Duration(days * float64(24*time.Hour)), nil
 	}
 
-	return false
+	return time.ParseDuration(since)
 }
 
-// Retrieve the rules mentioned in the RuleConfigs, registering additional custom
-// configurations for each rule if specified. Configurations are specific to each
-// rule, meaning one configuration in one rule may not work in other rule.
-func getRules(rcs []RuleConfig) ([]rule.Rule, error) {
-	var rules []rule.Rule
-	for _, rc := range rcs {
-		if len(rc.Configs) > 0 {
-			if configsData, err := json.Marshal(rc.Configs); err != nil {
-				return rules, err
-			} else if rule, err := rule.RuleInRuleRegistry(rc.ID); err != nil {
-				return rules, err
-			} else if err := rule.RegisterConfigs(configsData); err != nil {
-				return rules, err
-			} else {
-				rules = append(rules, rule)
-			}
-		} else {
-			if rule, err := rule.RuleInRuleRegistry(rc.ID); err != nil {
-				return rules, err
-			} else {
-				rules = append(rules, rule)
-			}
+// Get the name used to namespace the program's files (e.g. configuration file),
+// this is the program name suffixed with the instance name (if any).
+func instanceName(instance string) (string, error) {
+	if instance == "" {
+		return progName, nil
+	} else if !reInstanceName.MatchString(instance) {
+		return "", fmt.Errorf("instance name may only contain letters, digits, '_' and '-': %v", instance)
+	}
+
+	return progName + "-" + instance, nil
+}
+
+// Find the agent file of the instance, looking in the working directory first and
+// then in the program's configuration directory (e.g. ~/.config/rsb/rsb.agent).
+func findAgentPath(progFileDirPath, instName string) (string, error) {
+	agentPaths := []string{
+		strings.Join([]string{"./", instName, agentFileExt}, ""),
+		filepath.Join(progFileDirPath, instName+agentFileExt),
+	}
+	for _, agentPath := range agentPaths {
+		if _, err := os.Stat(agentPath); err == nil {
+			return agentPath, nil
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			return "", err
 		}
 	}
 
-	return rules, nil
+	return "", fmt.Errorf("no agent file was found at %v, pass --agent-path to use another file", strings.Join(agentPaths, " or "))
 }
 
-// Test each reddit post passed in to see if a post matches any of the rules passed
-// in. If a post matches any rule, then said post will be aggregated with others
-// that match a rule.
-func matchPosts(rules []rule.Rule, posts []*reddit.Post) map[string]*reddit.Post {
-	var matches = make(map[string]*reddit.Post)
-	for _, post := range posts {
-		for _, rule := range rules {
-			if rule.Match(post) {
-				matches[rule.Name()] = post
-			}
+// Get the agent file to use, finding the instance's agent file unless another
+// agent file was passed in.
+func resolveAgentPath(agentPath, progFileDirPath, instName string) (string, error) {
+	if agentPath == defaultAgentPath {
+		return findAgentPath(progFileDirPath, instName)
+	} else if _, err := os.Stat(agentPath); errors.Is(err, fs.ErrNotExist) {
+		return "", fmt.Errorf("the agent file %v does not exist", agentPath)
+	}
+
+	return agentPath, nil
+}
+
+// Look to see if the string is in the string array.
+func stringInArr(strArg string, arr []string) bool {
+	for _, val := range arr {
+		if val == strArg {
+			return true
 		}
 	}
 
-	return matches
+	return false
 }
 
 // Send a test email to the intended recipient to ensure smtp is functional.
@@ -310,161 +1004,1023 @@ func initSmtp(ct configTree) (smtp.Auth, error) {
 	return auth, nil
 }
 
-// Creates the default program configuration file.
-func createDefaultProgConfig(progConfigDirPath, progConfig string) error {
-	if _, err := os.Stat(progConfigDirPath); errors.Is(err, fs.ErrNotExist) {
-		os.MkdirAll(progConfigDirPath, os.ModeDir|(OS_USER_R|OS_USER_W|OS_USER_X|OS_GROUP_R|OS_GROUP_X|OS_OTH_R|OS_OTH_X))
+// Create the notifiers used to send reports keyed by their name, retrying failed
+// notifications as configured in the configTree.
+func newNotifiers(ct configTree, smtpAuth smtp.Auth) (map[string]notify.Notifier, error) {
+	maxAttempts, baseDelay, maxDelay, err := ct.NotifyRetry.settings(
+		"notifyRetry",
+		notify.DefaultMaxAttempts,
+		notify.DefaultBaseDelay,
+		notify.DefaultMaxDelay,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %v", progName, err)
 	}
 
-	defaultConfigTree := &configTree{RuleConfigs: []RuleConfig{
-		{
-			ID:      "",
-			Configs: map[string]interface{}{},
-		},
-	}}
-
-	// use 4 spaces vs a tab character for indenting
-	if defaultConfigTreeBytes, err := json.MarshalIndent(defaultConfigTree, "", "    "); err != nil {
-		return err
-	} else if err := ioutil.WriteFile(
-		filepath.Join(progConfigDirPath, progConfig),
-		defaultConfigTreeBytes,
-		os.ModeDir|(OS_USER_R|OS_USER_W|OS_USER_X|OS_GROUP_R|OS_GROUP_X|OS_OTH_R|OS_OTH_X),
-	); err != nil {
-		return err
+	email := &notify.Email{
+		Addr:     ct.SmtpAddr + ":" + ct.SmtpPort,
+		Auth:     smtpAuth,
+		From:     ct.SendMailFrom,
+		To:       ct.SendMailTo,
+		ProgName: progName,
 	}
 
-	return nil
-}
-
-// Start the main program execution.
-func main() {
-	pconfs := &progConfigs{}
-	pconfs.parseCmdArgs()
-
-	configDirPath, err := os.UserConfigDir()
-	if err != nil {
-		log.Panic(err)
+	notifiers := map[string]notify.Notifier{
+		emailNotifier: notify.NewRetry(email, maxAttempts, baseDelay, maxDelay),
 	}
-
-	var progConfigPath string = filepath.Join(configDirPath, progName, progConfig)
-	if _, err := os.Stat(progConfigPath); errors.Is(err, fs.ErrNotExist) {
-		if err := createDefaultProgConfig(
-			filepath.Join(configDirPath, progName),
-			progConfig,
-		); err != nil {
-			log.Panic(err)
-		}
+	if ct.Socket.Path != "" {
+		notifiers[socketNotifier] = notify.NewSocket(ct.Socket.Path)
 	}
-
-	switch {
-	case pconfs.exportConfig:
-		progConfigFd, err := os.Open(progConfigPath)
+	if ct.Webhook.URL != "" {
+		timeout, err := ct.Webhook.timeout("webhook")
 		if err != nil {
-			log.Panic(err)
-		}
-		defer progConfigFd.Close()
-
-		progConfigBytes, err := ioutil.ReadAll(progConfigFd)
-		if err != nil {
-			log.Panic(err)
+			return nil, err
 		}
 
-		fmt.Println(string(progConfigBytes))
-	case pconfs.showConfigPath:
-		fmt.Println(progConfigPath)
-	default:
-		if pconfs.altConfigPath != "" {
-			progConfigPath = pconfs.altConfigPath
+		// each match is retried on its own, so matches already sent are not sent again
+		notifiers[webhookNotifier] = &notify.PerMatch{
+			Notifier: notify.NewRetry(notify.NewWebhook(ct.Webhook.URL, timeout), maxAttempts, baseDelay, maxDelay),
 		}
-		progConfigFd, err := os.Open(progConfigPath)
+	}
+	if ct.Discord.URL != "" {
+		timeout, err := ct.Discord.timeout("discord")
 		if err != nil {
-			log.Panic(err)
+			return nil, err
 		}
-		defer progConfigFd.Close()
 
-		progConfigBytes, err := ioutil.ReadAll(progConfigFd)
-		if err != nil {
-			log.Panic(err)
+		notifiers[discordNotifier] = &notify.PerMatch{
+			Notifier: notify.NewRetry(notify.NewDiscord(ct.Discord.URL, timeout), maxAttempts, baseDelay, maxDelay),
 		}
+	}
 
-		var ct configTree
-		if err := json.Unmarshal(progConfigBytes, &ct); err != nil {
-			log.Panic(err)
-		}
-		smtpAuth, err := initSmtp(ct)
-		if err != nil {
-			log.Panic(fmt.Errorf("%v: failed to initialize smtp: %v", progName, err))
-		}
+	return notifiers, nil
+}
 
-		rules, err := getRules(ct.RuleConfigs)
-		if err != nil {
-			log.Panic(err)
-		}
+// Create the report of the posts gathered from the subreddits and the posts that
+// matched.
+func newReport(subredditNames []string, posts []*reddit.Post, matches []*engine.PostMatch) *notify.Report {
+	report := &notify.Report{
+		Subreddit: strings.Join(subredditNames, ", "),
+		Posts:     posts,
+	}
+	for _, match := range matches {
+		report.Matches = append(report.Matches, notify.Match{
+			Post:    match.Post,
+			Rules:   match.Rules,
+			Reasons: match.Reasons,
+			Count:   match.Count,
+		})
+	}
 
-		bot, err := reddit.NewBotFromAgentFile(pconfs.agentPath, 0)
-		if err != nil {
-			log.Panic(fmt.Errorf("%v: failed to create bot handle: %v", progName, err))
-		}
+	return report
+}
 
-		// DISCUSS(cavcrosby): each subreddit might require a different polling strategy
-		// than from another. Look into implementing this per subreddit.
-		cfg := graw.Config{Subreddits: []string{pconfs.subredditName}}
-		handler := &postGather{
-			bot:           bot,
-			postThreshold: defaultPostThreshold,
+// Map the name of each rule built from the RuleConfigs to the notifier of its
+// RuleConfig. Rules are keyed by the name they were built with, so an unnamed
+// composite rule (e.g. "or(cpuunderprice,gpuunderprice)") is routed like its
+// matches are named.
+func getRuleNotifiers(rcs []engine.RuleConfig, rules []rule.Rule) map[string]string {
+	ruleNotifiers := make(map[string]string)
+	for i, rc := range engine.BuiltRuleConfigs(rcs) {
+		if i < len(rules) {
+			ruleNotifiers[rules[i].Name()] = rc.Notifier
 		}
+	}
 
-		to := []string{ct.SendMailTo}
-		for {
-			if _, wait, err := graw.Run(handler, bot, cfg); err != nil {
-				log.Panic(fmt.Errorf("%v: graw run failed", progName))
-			} else if err := wait(); err != errfoundPost {
-				log.Panic(fmt.Errorf("%v: an error occurred for the graw post handler: %v", progName, err))
-			}
-
-			if handler.atPostThreshold() {
-				postQueue := handler.getPostQueue()
-				handler.flushPostQueue()
-				var postUrls []string
-				for i, post := range postQueue {
-					postUrls = append(postUrls, strconv.Itoa(i+1)+". "+post.URL)
-				}
+	return ruleNotifiers
+}
 
-				msgStr := strings.Join(
-					append(
-						[]string{
-							fmt.Sprintf("To: %v", ct.SendMailTo),
-							fmt.Sprintf("Subject: %v Report: \"%v\"", progName, pconfs.subredditName),
-							"",
-							"Posts:",
-						},
-						postUrls...,
-					),
-					"\r\n",
-				)
-
-				matches := matchPosts(rules, handler.getPostQueue())
-				var matchUrls []string
-				var matchCounter int = 1
-				for ruleId, post := range matches {
-					matchUrls = append(matchUrls, strconv.Itoa(matchCounter)+"("+ruleId+"). "+post.URL)
-					matchCounter += 1
-				}
+// Route each match to the notifiers named by the rules it matched. Matches of
+// rules without a notifier (or with an unknown one) are routed to the default
+// notifier, which is always routed to even if no matches are routed to it.
+func routeMatches(matches []*engine.PostMatch, ruleNotifiers map[string]string, notifiers map[string]notify.Notifier) map[string][]*engine.PostMatch {
+	routes := map[string][]*engine.PostMatch{defaultNotifier: nil}
+	for _, match := range matches {
+		routed := make(map[string]bool)
+		for _, ruleName := range match.Rules {
+			notifierName := ruleNotifiers[ruleName]
+			if _, ok := notifiers[notifierName]; !ok {
+				notifierName = defaultNotifier
+			}
 
-				msg := []byte(msgStr + strings.Join(
-					append(
-						[]string{
-							"Matches:",
-						},
-						matchUrls...,
-					),
-					"\r\n",
-				))
-				if err := smtp.SendMail(ct.SmtpAddr+":"+ct.SmtpPort, smtpAuth, ct.SendMailFrom, to, msg); err != nil {
-					log.Panic(err)
-				}
+			if !routed[notifierName] {
+				routes[notifierName] = append(routes[notifierName], match)
+				routed[notifierName] = true
 			}
 		}
 	}
+
+	return routes
+}
+
+// Read and parse the program configuration file, either JSON or YAML depending
+// on the file extension.
+func loadConfigTree(progConfigPath string) (configTree, error) {
+	var ct configTree
+	progConfigFd, err := os.Open(progConfigPath)
+	if err != nil {
+		return ct, err
+	}
+	defer progConfigFd.Close()
+
+	progConfigBytes, err := ioutil.ReadAll(progConfigFd)
+	if err != nil {
+		return ct, err
+	}
+
+	if err := unmarshalConfigTree(progConfigBytes, engine.ConfigFormat(progConfigPath), &ct); err != nil {
+		return ct, err
+	}
+
+	return ct, nil
+}
+
+// Read and parse the program configuration file, applying the overrides set in
+// the environment.
+func loadProgConfig(progConfigPath string) (configTree, error) {
+	ct, err := loadConfigTree(progConfigPath)
+	if err != nil {
+		return ct, err
+	}
+
+	if err := applyEnvOverrides(&ct, os.Environ()); err != nil {
+		return ct, err
+	}
+
+	return ct, nil
+}
+
+// Check the configTree for problems. Problems that prevent the program from
+// running are returned as errors, other problems are returned as warnings. Every
+// problem found is returned, not just the first.
+func validateConfigTree(ct configTree) ([]string, []error) {
+	var warnings []string
+	var errs []error
+	if len(ct.RuleConfigs) == 0 {
+		warnings = append(warnings, "no rules are configured, no posts will match")
+	}
+
+	ruleNames := make(map[string]bool)
+	for _, rc := range ct.RuleConfigs {
+		// disabled rules are not built, so they do not clash with other rules
+		if !rc.Disabled() {
+			if rc.RuleName() != "" && ruleNames[rc.RuleName()] {
+				warnings = append(warnings, fmt.Sprintf("rule %v is configured more than once under the same name, set name to tell the rules apart", rc.RuleName()))
+			}
+			ruleNames[rc.RuleName()] = true
+		}
+
+		if rc.Notifier != "" && !stringInArr(rc.Notifier, knownNotifiers) {
+			warnings = append(warnings, fmt.Sprintf("rule %v uses an unknown notifier %v, the %v notifier is used instead", rc.ID, rc.Notifier, defaultNotifier))
+		}
+	}
+
+	matchAll, err := engine.MatchAllRules(ct.MatchMode)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	// in the any match mode a rule that only filters posts out matches on its own
+	if !matchAll {
+		for _, rc := range ct.RuleConfigs {
+			if onlyFiltersPosts(rc) {
+				warnings = append(warnings, fmt.Sprintf("rule %v only filters posts out, it matches nearly every post on its own, combine it with other rules in an and rule or use the all match mode", rc.RuleName()))
+			}
+		}
+	}
+
+	if ct.Workers < 0 {
+		errs = append(errs, errors.New("workers must not be negative"))
+	}
+
+	if _, err := ct.PollInterval.pollInterval(); err != nil {
+		errs = append(errs, err)
+	}
+
+	if _, err := ct.RateLimit.rateLimit(nil); err != nil {
+		errs = append(errs, err)
+	}
+
+	if _, err := ct.fetchRetry(); err != nil {
+		errs = append(errs, err)
+	}
+
+	if _, err := ct.Webhook.timeout("webhook"); err != nil {
+		errs = append(errs, err)
+	}
+
+	if _, err := ct.Discord.timeout("discord"); err != nil {
+		errs = append(errs, err)
+	}
+
+	switch ct.SeenStore.Backend {
+	case "", store.MemoryBackend, store.FileBackend:
+	default:
+		errs = append(errs, fmt.Errorf("the following seen store backend is not known: %v", ct.SeenStore.Backend))
+	}
+
+	var usesHistory bool
+	for _, rc := range ct.RuleConfigs {
+		if rc.Blank() {
+			warnings = append(warnings, "a rule without an id is configured, it is skipped")
+			continue
+		}
+
+		ruleWarnings, ruleErrs := validateRuleConfig(rc)
+		warnings = append(warnings, ruleWarnings...)
+		errs = append(errs, ruleErrs...)
+		usesHistory = usesHistory || ruleConfigUsesHistory(rc)
+	}
+
+	if usesHistory && !ct.History.Enabled {
+		errs = append(errs, errHistoryNeeded)
+	}
+
+	return warnings, errs
+}
+
+// Determine if the engine.RuleConfig builds a rule that only filters posts out,
+// thus matching nearly every post on its own. Negated rules and composed rules
+// are not considered.
+func onlyFiltersPosts(rc engine.RuleConfig) bool {
+	if rc.Disabled() || rc.Negate || rc.Op != "" {
+		return false
+	}
+
+	r, err := rule.RuleInRuleRegistry(rc.ID)
+	if err != nil {
+		return false
+	}
+
+	fr, ok := r.(rule.FilterRule)
+	return ok && fr.FiltersOnly()
+}
+
+// Determine if the engine.RuleConfig (or a RuleConfig it composes) builds a rule
+// that uses the post history. Disabled RuleConfigs build no rule.
+func ruleConfigUsesHistory(rc engine.RuleConfig) bool {
+	if rc.Disabled() {
+		return false
+	}
+
+	for _, childRc := range rc.Rules {
+		if ruleConfigUsesHistory(childRc) {
+			return true
+		}
+	}
+	if rc.Rule != nil && ruleConfigUsesHistory(*rc.Rule) {
+		return true
+	} else if rc.Op != "" {
+		return false
+	}
+
+	r, err := rule.RuleInRuleRegistry(rc.ID)
+	return err == nil && rule.UsesHistory(r)
+}
+
+// Check the engine.RuleConfig (and the RuleConfigs it composes) for problems, in the
+// same way as validateConfigTree.
+func validateRuleConfig(rc engine.RuleConfig) ([]string, []error) {
+	var warnings []string
+	var errs []error
+	switch rc.Op {
+	case "":
+	case rule.AndOp, rule.OrOp:
+		var enabledRules int
+		for _, childRc := range rc.Rules {
+			if !childRc.Disabled() {
+				enabledRules++
+			}
+		}
+
+		if len(rc.Rules) == 0 {
+			errs = append(errs, fmt.Errorf("the %v rule %v has no rules", rc.Op, rc.ID))
+		} else if enabledRules == 0 {
+			errs = append(errs, fmt.Errorf("the %v rule %v has no enabled rules", rc.Op, rc.ID))
+		}
+		for _, childRc := range rc.Rules {
+			childWarnings, childErrs := validateRuleConfig(childRc)
+			warnings = append(warnings, childWarnings...)
+			errs = append(errs, childErrs...)
+		}
+		return warnings, errs
+	case rule.NotOp:
+		if rc.Rule == nil {
+			errs = append(errs, fmt.Errorf("the not rule %v has no rule", rc.ID))
+			return warnings, errs
+		} else if rc.Rule.Disabled() {
+			errs = append(errs, fmt.Errorf("the not rule %v has no enabled rule", rc.ID))
+		}
+
+		childWarnings, childErrs := validateRuleConfig(*rc.Rule)
+		return append(warnings, childWarnings...), append(errs, childErrs...)
+	default:
+		errs = append(errs, fmt.Errorf("the following rule op is not known: %v", rc.Op))
+		return warnings, errs
+	}
+
+	r, err := rule.RuleInRuleRegistry(rc.ID)
+	if err != nil {
+		errs = append(errs, err)
+		return warnings, errs
+	}
+
+	if configsData, err := rc.ConfigsJson(); err != nil {
+		errs = append(errs, fmt.Errorf("rule %v: %v", rc.ID, err))
+		return warnings, errs
+	} else if err := r.RegisterConfigs(configsData); err != nil {
+		errs = append(errs, fmt.Errorf("rule %v: invalid configs: %v", rc.ID, err))
+		return warnings, errs
+	}
+
+	if sc, ok := r.(rule.SanityChecker); ok {
+		for _, warning := range sc.Sanity() {
+			warnings = append(warnings, fmt.Sprintf("%v: %v", r.Name(), warning))
+		}
+	}
+
+	return warnings, errs
+}
+
+// Creates the default program configuration file, written as YAML if the file
+// has a YAML extension. An existing configuration file is left as is.
+func createDefaultProgConfig(progConfigDirPath, progConfig string) error {
+	if err := os.MkdirAll(progConfigDirPath, progConfigDirPerms); err != nil {
+		return fmt.Errorf("failed to create configuration directory %v: %v", progConfigDirPath, err)
+	}
+
+	defaultConfigTree := &configTree{
+		Version:    configVersion,
+		Subreddits: defaultSubreddits,
+		Config: engine.Config{
+			RuleConfigs: []engine.RuleConfig{
+				{
+					ID:      "",
+					Configs: map[string]interface{}{},
+				},
+			},
+		},
+	}
+
+	progConfigPath := filepath.Join(progConfigDirPath, progConfig)
+	defaultConfigTreeBytes, err := marshalConfigTree(defaultConfigTree, engine.ConfigFormat(progConfigPath))
+	if err != nil {
+		return err
+	}
+
+	progConfigFd, err := os.OpenFile(progConfigPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, progConfigPerms)
+	if errors.Is(err, fs.ErrExist) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to create configuration file %v: %v", progConfigPath, err)
+	}
+	defer progConfigFd.Close()
+
+	if _, err := progConfigFd.Write(defaultConfigTreeBytes); err != nil {
+		return fmt.Errorf("failed to write configuration file %v: %v", progConfigPath, err)
+	}
+
+	return progConfigFd.Close()
+}
+
+// Determine if the configuration tree has yet to be configured, this being the
+// case for the default configuration file (e.g. on a first run).
+func unconfigured(ct configTree) bool {
+	for _, rc := range ct.RuleConfigs {
+		if !rc.Blank() {
+			return false
+		}
+	}
+
+	return true
+}
+
+// A type that represents a rule as listed by the list-rules command.
+type ruleListing struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// Get the listings of the registered rules, sorted by name.
+func listRules() []ruleListing {
+	var listings []ruleListing
+	for _, r := range rule.GetAllRegisteredRules() {
+		listings = append(listings, ruleListing{Name: r.Name(), Description: r.Description()})
+	}
+
+	return listings
+}
+
+// Print guidance on configuring the program for the first time.
+func printOnboarding(w io.Writer, progConfigPath string) {
+	fmt.Fprintf(w, "%v: no rules are configured yet in %v\n", progName, progConfigPath)
+	fmt.Fprintf(w, "\nAdd at least one rule to the configuration file's \"rules\", run '%v list-rules'\n", progName)
+	fmt.Fprintf(w, "to see the available rules. Run '%v --export-config' to print the current\n", progName)
+	fmt.Fprintf(w, "configuration file and '%v validate-config' to check it once edited.\n", progName)
+}
+
+// Print the rules and subreddits the program would use when run, for the dry-run
+// flag.
+func printPlan(w io.Writer, ct configTree, rules []rule.Rule, subredditNames []string) {
+	matchMode := ct.MatchMode
+	if matchMode == "" {
+		matchMode = "any"
+	}
+
+	fmt.Fprintf(w, "rules (match mode %v):\n", matchMode)
+	for _, r := range rules {
+		fmt.Fprintf(w, "    %v\n", r.Name())
+	}
+	fmt.Fprintln(w, "subreddits:")
+	for _, subredditName := range subredditNames {
+		fmt.Fprintf(w, "    %v\n", subredditName)
+	}
+}
+
+// Create a context that is cancelled when the program is interrupted (SIGINT) or
+// terminated (SIGTERM), so the program can stop polling and run its deferred
+// cleanup (e.g. flushing the seen store). Notifications being sent when the
+// signal arrives are allowed to finish. A second signal exits immediately.
+func shutdownContext() (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		defer signal.Stop(sigs)
+		select {
+		case sig := <-sigs:
+			logging.Infof("%v: received %v, shutting down", progName, sig)
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	return ctx, cancel
+}
+
+// Write the program's process id to the pid file.
+func writePidFile(pidFilePath string) error {
+	return ioutil.WriteFile(
+		pidFilePath,
+		[]byte(strconv.Itoa(os.Getpid())+"\n"),
+		OS_USER_R|OS_USER_W|OS_GROUP_R|OS_OTH_R,
+	)
+}
+
+// A type that represents an error of a run that only partly failed (e.g. some of
+// the subreddits could not be polled), the program exiting with its own exit
+// code so it can be told apart from a run that failed entirely.
+type partialFailureError struct {
+	err error
+}
+
+func (e *partialFailureError) Error() string {
+	return e.err.Error()
+}
+
+func (e *partialFailureError) Unwrap() error {
+	return e.err
+}
+
+// Get the code the program exits with after a run that returned the error.
+func exitCode(err error) int {
+	var perr *partialFailureError
+	if err == nil {
+		return 0
+	} else if errors.As(err, &perr) {
+		return partialFailureExitCode
+	}
+
+	return 1
+}
+
+// Start the main program execution.
+func main() {
+	if err := run(); err != nil {
+		fmt.Fprintf(os.Stderr, "%v: error: %v\n", progName, err)
+		os.Exit(exitCode(err))
+	}
+}
+
+// Run the program, returning any error that stops it.
+func run() (runErr error) {
+	pconfs := &progConfigs{}
+	if err := pconfs.parseCmdArgs(); err != nil {
+		return err
+	} else if pconfs.helpFlagPassedIn {
+		return nil
+	} else if pconfs.version {
+		fmt.Println(versionString())
+		return nil
+	}
+
+	logLevel, err := logging.ParseLevel(pconfs.logLevel)
+	if err != nil {
+		return err
+	}
+	logging.SetLevel(logLevel)
+
+	configDirPath, err := os.UserConfigDir()
+	if err != nil {
+		return err
+	}
+
+	instName, err := instanceName(pconfs.instance)
+	if err != nil {
+		return err
+	}
+
+	var progFileDirPath string = filepath.Join(configDirPath, progName)
+	progConfigPath, err := findProgConfigPath(progFileDirPath, instName)
+	if err != nil {
+		return err
+	}
+	if pconfs.altConfigPath != "" {
+		progConfigPath = pconfs.altConfigPath
+	}
+	if _, err := os.Stat(progConfigPath); errors.Is(err, fs.ErrNotExist) && !pconfs.initConfig {
+		if err := createDefaultProgConfig(
+			filepath.Dir(progConfigPath),
+			filepath.Base(progConfigPath),
+		); err != nil {
+			return err
+		}
+	}
+
+	color, err := colorEnabled(pconfs.colorMode, os.Stdout)
+	if err != nil {
+		return err
+	}
+	printer := &matchPrinter{w: os.Stdout, color: color}
+
+	switch {
+	case pconfs.initConfig:
+		if _, err := os.Stat(progConfigPath); err == nil && !pconfs.initOpts.force {
+			return fmt.Errorf("the configuration file %v already exists, pass --force to overwrite it", progConfigPath)
+		}
+
+		pconfs.initOpts.complete(os.Stdin, os.Stdout, isTerminal(os.Stdin))
+		ct, warnings, err := newInitConfigTree(pconfs.initOpts)
+		for _, warning := range warnings {
+			fmt.Fprintf(os.Stderr, "%v: warning: %v\n", progName, warning)
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := writeInitConfig(progConfigPath, ct, pconfs.initOpts.force); err != nil {
+			return err
+		}
+		fmt.Printf("%v: wrote the configuration file %v\n", progName, progConfigPath)
+	case pconfs.exportConfig:
+		// the file is printed as is, keeping the format the active config uses
+		progConfigFd, err := os.Open(progConfigPath)
+		if err != nil {
+			return err
+		}
+		defer progConfigFd.Close()
+
+		progConfigBytes, err := ioutil.ReadAll(progConfigFd)
+		if err != nil {
+			return err
+		}
+
+		fmt.Println(string(progConfigBytes))
+	case pconfs.showConfigPath:
+		fmt.Println(progConfigPath)
+	case pconfs.listRules:
+		listings := listRules()
+		if pconfs.listRulesJson {
+			listingsBytes, err := json.MarshalIndent(listings, "", "    ")
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(listingsBytes))
+			break
+		}
+
+		for _, listing := range listings {
+			fmt.Printf("%-16v %v\n", listing.Name, listing.Description)
+		}
+	case pconfs.diffConfig:
+		oldCt, err := loadConfigTree(pconfs.diffConfigPaths[0])
+		if err != nil {
+			return err
+		}
+
+		newCt, err := loadConfigTree(pconfs.diffConfigPaths[1])
+		if err != nil {
+			return err
+		}
+
+		for _, line := range diffConfigTrees(oldCt, newCt) {
+			fmt.Println(line)
+		}
+	case pconfs.evaluate:
+		ct, err := loadProgConfig(progConfigPath)
+		if err != nil {
+			return err
+		}
+
+		if unconfigured(ct) {
+			printOnboarding(os.Stdout, progConfigPath)
+			return nil
+		}
+
+		since, err := parseSince(pconfs.evaluateSince)
+		if err != nil {
+			return err
+		}
+
+		rules, err := engine.BuildRules(ct.RuleConfigs)
+		if err != nil {
+			return err
+		}
+
+		ms, err := engine.NewSettings(ct.Config)
+		if err != nil {
+			return err
+		}
+		if pconfs.trace {
+			ms.Tracer = engine.NewTracer(os.Stderr)
+		}
+		heuristic := engine.NewHeuristic(rules).WithSettings(ms)
+
+		records, err := store.NewPostHistory(ct.History.historyPath(filepath.Join(progFileDirPath, instName+historyFileExt))).Since(time.Now().Add(-since))
+		if err != nil {
+			return err
+		}
+
+		var posts []*reddit.Post
+		for _, record := range records {
+			posts = append(posts, record.Post)
+		}
+
+		matches := heuristic.AppliedTo(posts, nil)
+		for i, match := range matches {
+			printer.print(i+1, match)
+		}
+		fmt.Printf("%v of %v posts would match\n", len(matches), len(posts))
+	case pconfs.explain:
+		ct, err := loadProgConfig(progConfigPath)
+		if err != nil {
+			return err
+		}
+
+		if unconfigured(ct) {
+			printOnboarding(os.Stdout, progConfigPath)
+			return nil
+		}
+
+		post, err := pconfs.explainOpts.post()
+		if err != nil {
+			return err
+		}
+
+		rules, err := engine.BuildRules(ct.RuleConfigs)
+		if err != nil {
+			return err
+		}
+
+		ms, err := engine.NewSettings(ct.Config)
+		if err != nil {
+			return err
+		}
+
+		verdicts, matched := engine.NewHeuristic(rules).WithSettings(ms).Explain(post, rule.PostContext{})
+		printVerdicts(os.Stdout, verdicts, matched)
+	case pconfs.validateConfig:
+		ct, err := loadProgConfig(progConfigPath)
+		if err != nil {
+			return err
+		}
+
+		warnings, errs := validateConfigTree(ct)
+		for _, warning := range warnings {
+			fmt.Fprintf(os.Stderr, "%v: warning: %v\n", progName, warning)
+		}
+		for _, err := range errs {
+			fmt.Fprintf(os.Stderr, "%v: error: %v\n", progName, err)
+		}
+		if len(errs) > 0 {
+			return fmt.Errorf("the configuration file has %v error(s)", len(errs))
+		} else if pconfs.strict && len(warnings) > 0 {
+			return fmt.Errorf("the configuration file has %v warning(s), treated as errors", len(warnings))
+		}
+	default:
+		ct, err := loadProgConfig(progConfigPath)
+		if err != nil {
+			return err
+		}
+
+		if unconfigured(ct) {
+			printOnboarding(os.Stdout, progConfigPath)
+			return nil
+		}
+
+		subredditNames := pconfs.subredditNames
+		if len(subredditNames) == 0 {
+			subredditNames = ct.Subreddits
+		}
+		if len(subredditNames) == 0 {
+			return errors.New("no subreddits to watch, pass SUBREDDIT_NAME or set subreddits in the configuration file")
+		}
+
+		rules, err := engine.BuildRules(ct.RuleConfigs)
+		if err != nil {
+			return err
+		}
+
+		usesHistory := engine.UsesHistory(rules)
+		if usesHistory && !ct.History.Enabled {
+			return errHistoryNeeded
+		}
+
+		ms, err := engine.NewSettings(ct.Config)
+		if err != nil {
+			return err
+		}
+		if pconfs.trace {
+			ms.Tracer = engine.NewTracer(os.Stderr)
+		}
+		heuristic := engine.NewHeuristic(rules).WithSettings(ms)
+
+		var output *notify.File
+		if pconfs.outputPath != "" {
+			if output, err = notify.NewFile(pconfs.outputPath, pconfs.format); err != nil {
+				return err
+			}
+		}
+
+		if pconfs.dryRun {
+			printPlan(os.Stdout, ct, rules, subredditNames)
+			return nil
+		}
+
+		smtpAuth, err := initSmtp(ct)
+		if err != nil {
+			return fmt.Errorf("failed to initialize smtp: %v", err)
+		}
+
+		if pconfs.pidFilePath != "" {
+			if err := writePidFile(pconfs.pidFilePath); err != nil {
+				return fmt.Errorf("failed to write pid file: %v", err)
+			}
+			defer os.Remove(pconfs.pidFilePath)
+		}
+
+		pi, err := ct.PollInterval.pollInterval()
+		if err != nil {
+			return err
+		}
+
+		ctx, cancel := shutdownContext()
+		defer cancel()
+
+		// a server failing stops the program, the failure being returned from run
+		serverErrs := make(chan error, 3)
+		defer func() {
+			select {
+			case err := <-serverErrs:
+				runErr = err
+			default:
+			}
+		}()
+		startServer := func(name string, serve func(ctx context.Context) error) {
+			go func() {
+				if err := serve(ctx); err != nil {
+					serverErrs <- fmt.Errorf("failed to serve %v: %v", name, err)
+					cancel()
+				}
+			}()
+		}
+
+		health := newHealthState(healthThreshold(pi))
+		if pconfs.healthAddr != "" {
+			startServer("health", func(ctx context.Context) error {
+				return serveHealth(ctx, pconfs.healthAddr, health)
+			})
+		}
+
+		metrics := newMetrics()
+		if pconfs.metricsAddr != "" {
+			startServer("metrics", func(ctx context.Context) error {
+				return serveMetrics(ctx, pconfs.metricsAddr, metrics)
+			})
+		}
+
+		if pconfs.agentPath, err = resolveAgentPath(pconfs.agentPath, progFileDirPath, instName); err != nil {
+			return err
+		}
+		// the bot also spaces its own requests (e.g. when watching) by the interval
+		rateLimiter, err := ct.RateLimit.rateLimit(nil)
+		if err != nil {
+			return err
+		}
+		bot, err := reddit.NewBotFromAgentFile(pconfs.agentPath, rateLimiter.minInterval)
+		if err != nil {
+			return fmt.Errorf("failed to create bot handle: %v", err)
+		}
+		rateLimiter.lister = bot
+
+		// DISCUSS(cavcrosby): each subreddit might require a different polling strategy
+		// than from another. Look into implementing this per subreddit.
+		var subredditPollers []*poller
+		for _, subredditName := range subredditNames {
+			subredditPollers = append(subredditPollers, &poller{
+				lister:     rateLimiter,
+				subreddit:  subredditName,
+				fetchLimit: pconfs.fetchLimit,
+				sort:       pconfs.sort,
+				timeWindow: pconfs.timeWindow,
+			})
+		}
+		seenPath := ct.SeenStore.Path
+		if seenPath == "" {
+			seenPath = filepath.Join(progFileDirPath, instName+seenFileExt)
+		}
+		seen, err := store.OpenSeenStore(ct.SeenStore.Backend, seenPath)
+		if err != nil {
+			return fmt.Errorf("failed to open seen store: %v", err)
+		}
+		defer seen.Close()
+		if pconfs.resetSeen {
+			logging.Infof("%v: forgetting the posts already seen", progName)
+			seen.Reset()
+		}
+
+		var history *store.PostHistory
+		if ct.History.Enabled {
+			history = store.NewPostHistory(ct.History.historyPath(filepath.Join(progFileDirPath, instName+historyFileExt)))
+		}
+
+		handler := &postGather{
+			bot:           bot,
+			postThreshold: defaultPostThreshold,
+			prefilters:    defaultPrefilters,
+			rematch:       usesHistory,
+			seen:          seen,
+		}
+
+		if pconfs.webhookUrl != "" {
+			ct.Webhook.URL = pconfs.webhookUrl
+		}
+		notifiers, err := newNotifiers(ct, smtpAuth)
+		if err != nil {
+			return err
+		}
+
+		ruleNotifiers := getRuleNotifiers(ct.RuleConfigs, rules)
+
+		// recent matches are only kept for the api
+		var matchStore *store.MatchStore
+		if pconfs.httpAddr != "" {
+			matchStore = store.NewMatchStore(defaultRecentMatches)
+		}
+
+		// match the posts against the rules, printing and sending reports of the
+		// matches, returning whether any post matched
+		reportMatches := func(posts []*reddit.Post, pctxs map[string]rule.PostContext) []*engine.PostMatch {
+			matches := heuristic.AppliedTo(posts, pctxs)
+			if ct.CollapseReposts {
+				matches = engine.CollapseReposts(matches)
+			}
+
+			if matchStore != nil {
+				matchStore.Record(newReport(subredditNames, posts, matches).MatchRecords(), time.Now())
+			}
+
+			metrics.recordMatches(posts, matches)
+			for i, match := range matches {
+				printer.print(i+1, match)
+			}
+
+			// posts are reported one at a time when watching, so reports are only
+			// sent for posts that matched rather than for every post streamed
+			if pconfs.watch && len(matches) == 0 {
+				return matches
+			}
+
+			if output != nil {
+				if err := output.Notify(newReport(subredditNames, posts, matches)); err != nil {
+					logging.Errorf("%v: failed to write matches to %v: %v", progName, pconfs.outputPath, err)
+				}
+			}
+
+			for notifierName, routedMatches := range routeMatches(matches, ruleNotifiers, notifiers) {
+				if err := notifiers[notifierName].Notify(newReport(subredditNames, posts, routedMatches)); err != nil {
+					logging.Errorf("%v: failed to send report with %v notifier: %v", progName, notifierName, err)
+				}
+			}
+
+			return matches
+		}
+
+		if pconfs.httpAddr != "" {
+			startServer("the api", func(ctx context.Context) error {
+				return serveHttp(ctx, pconfs.httpAddr, newApiHandler(matchStore))
+			})
+		}
+
+		// prune and save the posts seen
+		flushSeen := func() {
+			seen.Prune(time.Now().Add(-defaultSeenRetention))
+			if flusher, ok := seen.(store.Flusher); ok {
+				if err := flusher.Flush(); err != nil {
+					logging.Errorf("%v: failed to flush seen store: %v", progName, err)
+				}
+			}
+		}
+
+		if pconfs.watch {
+			stream := newPostStream(seen, history, func(p *reddit.Post) {
+				reportMatches([]*reddit.Post{p}, nil)
+			}, flushSeen)
+			return watchSubreddits(ctx, bot, subredditNames, stream, pi, health)
+		}
+
+		fr, err := ct.fetchRetry()
+		if err != nil {
+			return err
+		}
+
+		// match the posts in the post queue, marking them as seen once matched
+		reportQueue := func() bool {
+			postQueue := handler.getPostQueue()
+			pctxs := handler.getPostContexts()
+			handler.flushPostQueue()
+			matches := reportMatches(postQueue, pctxs)
+			handler.markMatched(postQueue, matches)
+			return len(matches) > 0
+		}
+
+		var matched bool
+		for {
+			matched = false
+			// the posts queued by this poll are the posts not seen before, as the
+			// post handler leaves out posts already seen
+			queued := len(handler.getPostQueue())
+			var polled bool
+			var skipped []string
+			for _, subredditPoller := range subredditPollers {
+				posts, err := fr.poll(ctx, subredditPoller)
+				if err != nil {
+					logging.Errorf("%v: skipping subreddit %v, failed to poll it: %v", progName, subredditPoller.subreddit, err)
+					skipped = append(skipped, subredditPoller.subreddit)
+					metrics.fetchErrors.add(subredditPoller.subreddit, 1)
+					continue
+				}
+				polled = true
+
+				// posts are polled newest first, queue them in the order they were posted
+				for i := len(posts) - 1; i >= 0; i-- {
+					handler.setPostContext(posts[i].ID, rule.PostContext{Rank: i + 1})
+					handler.Post(posts[i])
+				}
+			}
+			if polled {
+				health.pollSucceeded()
+			}
+
+			if history != nil {
+				newPosts := handler.getPostQueue()[queued:]
+				if usesHistory {
+					// posts seen again are only recorded when first seen
+					if newPosts, err = handler.recallFirstSeen(history, newPosts); err != nil {
+						logging.Errorf("%v: failed to read post history: %v", progName, err)
+					}
+				}
+
+				if err := history.Record(newPosts, time.Now()); err != nil {
+					logging.Errorf("%v: failed to record post history: %v", progName, err)
+				}
+			}
+
+			if handler.atPostThreshold() {
+				matched = reportQueue()
+			}
+			flushSeen()
+
+			select {
+			case <-ctx.Done():
+				// posts still in the post queue are matched before stopping, as they
+				// were not marked as seen and would otherwise be dropped
+				if len(handler.getPostQueue()) > 0 {
+					reportQueue()
+					flushSeen()
+				}
+
+				// the exit reflects whether the last poll reached every subreddit
+				if !polled && len(skipped) > 0 {
+					return errors.New("failed to poll every subreddit")
+				} else if len(skipped) > 0 {
+					return &partialFailureError{fmt.Errorf("failed to poll the following subreddits: %v", strings.Join(skipped, ", "))}
+				}
+				return nil
+			case <-time.After(pi.next(matched)):
+			}
+		}
+	}
+
+	return nil
 }
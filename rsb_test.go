@@ -0,0 +1,1167 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/cavcrosby/rsb/engine"
+	"github.com/cavcrosby/rsb/notify"
+	"github.com/cavcrosby/rsb/rule"
+	"github.com/cavcrosby/rsb/store"
+	"github.com/turnage/graw/reddit"
+)
+
+// A type that represents a rule matching every post, used to test how matches are
+// gathered independently of the rules themselves.
+type matchAllRule struct {
+	name string
+}
+
+func (m *matchAllRule) Name() string {
+	return m.name
+}
+
+func (m *matchAllRule) Description() string {
+	return "matches every post"
+}
+
+func (m *matchAllRule) RegisterConfigs(configs []byte) error {
+	return nil
+}
+
+func (m *matchAllRule) Match(post *reddit.Post) bool {
+	return true
+}
+
+func TestWritePidFile(t *testing.T) {
+	pidFilePath := filepath.Join(t.TempDir(), "rsb.pid")
+	if err := writePidFile(pidFilePath); err != nil {
+		t.Fatalf("writePidFile returned an error: %v", err)
+	}
+
+	data, err := ioutil.ReadFile(pidFilePath)
+	if err != nil {
+		t.Fatalf("failed to read pid file: %v", err)
+	}
+	if got, want := strings.TrimSpace(string(data)), strconv.Itoa(os.Getpid()); got != want {
+		t.Errorf("pid file contains %q, want %q", got, want)
+	}
+}
+
+func TestPostGatherPrefilters(t *testing.T) {
+	tests := []struct {
+		name       string
+		post       *reddit.Post
+		wantQueued bool
+	}{
+		{"normal post", &reddit.Post{ID: "normal", Title: "[RAM] Corsair 16GB $49.99"}, true},
+		{"moderator post", &reddit.Post{ID: "moderator", Title: "Weekly discussion thread", Distinguished: "moderator"}, false},
+		{"admin post", &reddit.Post{ID: "admin", Title: "Site announcement", Distinguished: "admin"}, false},
+		{"hidden post", &reddit.Post{ID: "hidden", Title: "[RAM] Corsair 16GB $49.99", Hidden: true}, false},
+	}
+
+	for _, tt := range tests {
+		g := &postGather{prefilters: defaultPrefilters}
+		g.Post(tt.post)
+		if queued := len(g.getPostQueue()) == 1; queued != tt.wantQueued {
+			t.Errorf("%v: post queued = %v, want %v", tt.name, queued, tt.wantQueued)
+		}
+	}
+}
+
+// Parse the arguments as the program's command arguments.
+func parseArgs(t *testing.T, args ...string) (*progConfigs, error) {
+	t.Helper()
+	osArgs := os.Args
+	defer func() { os.Args = osArgs }()
+
+	os.Args = append([]string{progName}, args...)
+	pconfs := &progConfigs{}
+	return pconfs, pconfs.parseCmdArgs()
+}
+
+// Write the configuration file contents to a temporary file, returning its path.
+func writeTestConfig(t *testing.T, name, contents string) string {
+	t.Helper()
+	progConfigPath := filepath.Join(t.TempDir(), name)
+	if err := ioutil.WriteFile(progConfigPath, []byte(contents), 0o600); err != nil {
+		t.Fatalf("failed to write configuration file: %v", err)
+	}
+
+	return progConfigPath
+}
+
+// Run the program with the arguments, its configuration directory being a
+// temporary directory.
+func runArgs(t *testing.T, args ...string) error {
+	t.Helper()
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	osArgs := os.Args
+	defer func() { os.Args = osArgs }()
+
+	os.Args = append([]string{progName}, args...)
+	return run()
+}
+
+// Run the program with the arguments, returning what it wrote to stdout.
+func runArgsOutput(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("failed to create pipe: %v", err)
+	}
+
+	output := make(chan string)
+	go func() {
+		outputBytes, _ := ioutil.ReadAll(r)
+		output <- string(outputBytes)
+	}()
+
+	stdout := os.Stdout
+	os.Stdout = w
+	runErr := runArgs(t, args...)
+	os.Stdout = stdout
+	w.Close()
+
+	return <-output, runErr
+}
+
+func TestValidateConfigTree(t *testing.T) {
+	// the rule is configured twice under the same name, which is only a warning
+	progConfigPath := writeTestConfig(t, "rsb.json", `{
+		"rules": [
+			{"id": "ramunderprice", "configs": {"price": 100}},
+			{"id": "ramunderprice", "configs": {"price": 50}}
+		]
+	}`)
+	cleanConfigPath := writeTestConfig(t, "clean.json", `{
+		"rules": [{"id": "ramunderprice", "configs": {"price": 100}}]
+	}`)
+	unknownRuleConfigPath := writeTestConfig(t, "unknown.json", `{
+		"rules": [{"id": "notarule"}]
+	}`)
+	// every problem is reported, not just the first
+	problemsConfigPath := writeTestConfig(t, "problems.json", `{
+		"matchMode": "most",
+		"rules": [{"id": "notarule"}, {"id": "ramunderprice", "configs": {"price": "cheap"}}]
+	}`)
+
+	tests := []struct {
+		progConfigPath string
+		wantWarnings   bool
+		wantErrs       []string
+	}{
+		{progConfigPath, true, nil},
+		{cleanConfigPath, false, nil},
+		{unknownRuleConfigPath, false, []string{"notarule"}},
+		{problemsConfigPath, false, []string{"most", "notarule", "rule ramunderprice: invalid configs"}},
+	}
+
+	for _, tt := range tests {
+		ct, err := loadConfigTree(tt.progConfigPath)
+		if err != nil {
+			t.Fatalf("loadConfigTree(%q) returned an error: %v", tt.progConfigPath, err)
+		}
+
+		warnings, errs := validateConfigTree(ct)
+		if (len(warnings) > 0) != tt.wantWarnings || len(errs) != len(tt.wantErrs) {
+			t.Errorf("validateConfigTree(%q) = %v, %v, want warnings %v, %v errors", tt.progConfigPath, warnings, errs, tt.wantWarnings, len(tt.wantErrs))
+			continue
+		}
+		for i, wantErr := range tt.wantErrs {
+			if !strings.Contains(errs[i].Error(), wantErr) {
+				t.Errorf("validateConfigTree(%q) error %v = %v, want it to mention %q", tt.progConfigPath, i, errs[i], wantErr)
+			}
+		}
+	}
+}
+
+func TestParseSince(t *testing.T) {
+	tests := []struct {
+		since   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"30m", 30 * time.Minute, false},
+		{"36h", 36 * time.Hour, false},
+		{"7d", 7 * 24 * time.Hour, false},
+		{"0.5d", 12 * time.Hour, false},
+		{"d", 0, true},
+		{"week", 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseSince(tt.since)
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("parseSince(%q) = %v, %v, want %v, error %v", tt.since, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestRouteMatches(t *testing.T) {
+	notifiers := map[string]notify.Notifier{
+		emailNotifier: nil,
+		"webhook":     nil,
+		"socket":      nil,
+	}
+	ruleNotifiers := map[string]string{
+		"ramunderprice": "webhook",
+		"gpuunderprice": "socket",
+		"cpuunderprice": "",
+		"keywordmatch":  "pager",
+	}
+
+	ram := &engine.PostMatch{Post: &reddit.Post{ID: "ram"}, Rules: []string{"ramunderprice"}}
+	gpu := &engine.PostMatch{Post: &reddit.Post{ID: "gpu"}, Rules: []string{"gpuunderprice"}}
+	cpu := &engine.PostMatch{Post: &reddit.Post{ID: "cpu"}, Rules: []string{"cpuunderprice", "keywordmatch"}}
+	both := &engine.PostMatch{Post: &reddit.Post{ID: "both"}, Rules: []string{"ramunderprice", "gpuunderprice", "cpuunderprice"}}
+
+	tests := []struct {
+		name    string
+		matches []*engine.PostMatch
+		want    map[string][]string
+	}{
+		{"no matches", nil, map[string][]string{emailNotifier: nil}},
+		{
+			"each rule's notifier",
+			[]*engine.PostMatch{ram, gpu},
+			map[string][]string{emailNotifier: nil, "webhook": {"ram"}, "socket": {"gpu"}},
+		},
+		{
+			// rules without a notifier, or with an unknown one, use the default
+			"default notifier once",
+			[]*engine.PostMatch{cpu},
+			map[string][]string{emailNotifier: {"cpu"}},
+		},
+		{
+			"every notifier of the match",
+			[]*engine.PostMatch{both},
+			map[string][]string{emailNotifier: {"both"}, "webhook": {"both"}, "socket": {"both"}},
+		},
+	}
+
+	for _, tt := range tests {
+		got := make(map[string][]string)
+		for notifierName, routedMatches := range routeMatches(tt.matches, ruleNotifiers, notifiers) {
+			got[notifierName] = nil
+			for _, match := range routedMatches {
+				got[notifierName] = append(got[notifierName], match.Post.ID)
+			}
+		}
+
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%v: routeMatches = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestGetRuleNotifiers(t *testing.T) {
+	disabled := false
+	keywords := func(keywords ...interface{}) map[string]interface{} {
+		return map[string]interface{}{"keywords": keywords}
+	}
+	rcs := []engine.RuleConfig{
+		{ID: "keywordmatch", Enabled: &disabled, Notifier: "socket", Configs: keywords("ssd")},
+		{Op: "or", Notifier: "webhook", Rules: []engine.RuleConfig{
+			{ID: "keywordmatch", Configs: keywords("ram")},
+			{ID: "keywordmatch", Name: "gpu", Configs: keywords("gpu")},
+		}},
+		{ID: "keywordmatch", Name: "cpu", Notifier: "socket", Configs: keywords("cpu")},
+	}
+	rules, err := engine.BuildRules(rcs)
+	if err != nil {
+		t.Fatalf("BuildRules returned an error: %v", err)
+	}
+
+	ruleNotifiers := getRuleNotifiers(rcs, rules)
+	want := map[string]string{"or(keywordmatch,gpu)": "webhook", "cpu": "socket"}
+	if !reflect.DeepEqual(ruleNotifiers, want) {
+		t.Errorf("getRuleNotifiers = %v, want %v", ruleNotifiers, want)
+	}
+
+	// matches of the unnamed or rule are routed to its notifier
+	notifiers := map[string]notify.Notifier{emailNotifier: nil, "webhook": nil, "socket": nil}
+	matches := engine.NewHeuristic(rules).AppliedTo([]*reddit.Post{{ID: "ram", Title: "[RAM] Corsair Vengeance 16GB $49.99"}}, nil)
+	routes := routeMatches(matches, ruleNotifiers, notifiers)
+	if len(routes["webhook"]) != 1 || len(routes[emailNotifier]) != 0 {
+		t.Errorf("routeMatches routed the unnamed or rule's match to %v, want only webhook", routes)
+	}
+}
+
+func TestInstanceName(t *testing.T) {
+	tests := []struct {
+		instance string
+		want     string
+		wantErr  bool
+	}{
+		{"", "rsb", false},
+		{"work", "rsb-work", false},
+		{"work_2-b", "rsb-work_2-b", false},
+		{"a/b", "", true},
+		{"../work", "", true},
+		{"work space", "", true},
+	}
+	for _, tt := range tests {
+		got, err := instanceName(tt.instance)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("instanceName(%q) error = %v, wantErr %v", tt.instance, err, tt.wantErr)
+		} else if got != tt.want {
+			t.Errorf("instanceName(%q) = %q, want %q", tt.instance, got, tt.want)
+		}
+	}
+}
+
+func TestPollConfigPollInterval(t *testing.T) {
+	tests := []struct {
+		pc       pollConfig
+		wantBase time.Duration
+		wantMax  time.Duration
+		wantMult float64
+		wantErr  bool
+	}{
+		{pollConfig{}, defaultPollInterval, defaultPollInterval, 1, false},
+		{pollConfig{Base: "30s"}, 30 * time.Second, 30 * time.Second, 1, false},
+		{pollConfig{Base: "30s", Max: "5m", Multiplier: 1.5}, 30 * time.Second, 5 * time.Minute, 1.5, false},
+		{pollConfig{Base: "soon"}, 0, 0, 0, true},
+		{pollConfig{Base: "0s"}, 0, 0, 0, true},
+		{pollConfig{Base: "1m", Max: "30s"}, 0, 0, 0, true},
+		{pollConfig{Base: "1m", Max: "5m", Multiplier: 0.5}, 0, 0, 0, true},
+	}
+
+	for _, tt := range tests {
+		pi, err := tt.pc.pollInterval()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%+v.pollInterval() error = %v, wantErr %v", tt.pc, err, tt.wantErr)
+			continue
+		} else if err != nil {
+			continue
+		}
+
+		if pi.base != tt.wantBase || pi.max != tt.wantMax || pi.multiplier != tt.wantMult {
+			t.Errorf(
+				"%+v.pollInterval() = base %v, max %v, multiplier %v, want base %v, max %v, multiplier %v",
+				tt.pc, pi.base, pi.max, pi.multiplier, tt.wantBase, tt.wantMax, tt.wantMult,
+			)
+		}
+	}
+}
+
+func TestRecallFirstSeen(t *testing.T) {
+	seenAt := time.Now().Add(-time.Hour).Truncate(time.Second)
+	history := store.NewPostHistory(filepath.Join(t.TempDir(), "rsb.history.jsonl"))
+	if err := history.Record([]*reddit.Post{{ID: "baseline", Score: 10}}, seenAt); err != nil {
+		t.Fatalf("failed to seed post history: %v", err)
+	}
+
+	g := &postGather{}
+	posts := []*reddit.Post{{ID: "baseline", Score: 35}, {ID: "new", Score: 50}}
+	for i, p := range posts {
+		g.setPostContext(p.ID, rule.PostContext{Rank: i + 1})
+	}
+
+	firstSightings, err := g.recallFirstSeen(history, posts)
+	if err != nil {
+		t.Fatalf("recallFirstSeen returned an error: %v", err)
+	}
+	if len(firstSightings) != 1 || firstSightings[0].ID != "new" {
+		t.Errorf("recallFirstSeen returned %v first sightings, want only the new post", len(firstSightings))
+	}
+
+	tests := []struct {
+		id   string
+		want rule.PostContext
+	}{
+		{"baseline", rule.PostContext{Rank: 1, FirstSeen: seenAt, FirstScore: 10}},
+		{"new", rule.PostContext{Rank: 2}},
+	}
+
+	pctxs := g.getPostContexts()
+	for _, tt := range tests {
+		got := pctxs[tt.id]
+		if got.Rank != tt.want.Rank || !got.FirstSeen.Equal(tt.want.FirstSeen) || got.FirstScore != tt.want.FirstScore {
+			t.Errorf("context of post %v = %+v, want %+v", tt.id, got, tt.want)
+		}
+	}
+}
+
+func TestPrintOnboarding(t *testing.T) {
+	var buf bytes.Buffer
+	printOnboarding(&buf, "rsb.json")
+
+	for _, want := range []string{"no rules are configured yet in rsb.json", "run 'rsb list-rules'"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("printOnboarding() printed %q, want it to contain %q", buf.String(), want)
+		}
+	}
+}
+
+func TestListRules(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"text", []string{"list-rules"}},
+		{"json", []string{"list-rules", "--json"}},
+	}
+
+	for _, tt := range tests {
+		output, err := runArgsOutput(t, tt.args...)
+		if err != nil {
+			t.Fatalf("%v: run() returned an error: %v", tt.name, err)
+		}
+
+		var listings []ruleListing
+		if tt.name == "json" {
+			if err := json.Unmarshal([]byte(output), &listings); err != nil {
+				t.Fatalf("%v: output is not valid JSON: %v", tt.name, err)
+			}
+		} else {
+			for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+				fields := strings.Fields(line)
+				if len(fields) < 2 {
+					t.Errorf("%v: line %q does not have a name and a description", tt.name, line)
+					continue
+				}
+				listings = append(listings, ruleListing{Name: fields[0], Description: strings.Join(fields[1:], " ")})
+			}
+		}
+
+		want := listRules()
+		if len(want) == 0 {
+			t.Fatalf("%v: no rules are registered", tt.name)
+		}
+		if !reflect.DeepEqual(listings, want) {
+			t.Errorf("%v: listed %v, want %v", tt.name, listings, want)
+		}
+	}
+}
+
+func TestUnconfigured(t *testing.T) {
+	tests := []struct {
+		name string
+		rcs  []engine.RuleConfig
+		want bool
+	}{
+		{"no rules", nil, true},
+		{"default rule", []engine.RuleConfig{{ID: "", Configs: map[string]interface{}{}}}, true},
+		{"configured rule", []engine.RuleConfig{{ID: "ramunderprice", Configs: map[string]interface{}{"price": 100.0}}}, false},
+	}
+
+	for _, tt := range tests {
+		var ct configTree
+		ct.RuleConfigs = tt.rcs
+		if got := unconfigured(ct); got != tt.want {
+			t.Errorf("%v: unconfigured() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestGetRulesSkipsRulesWithoutID(t *testing.T) {
+	tests := []struct {
+		name      string
+		rcs       []engine.RuleConfig
+		wantRules int
+	}{
+		{"default rule", []engine.RuleConfig{{ID: "", Configs: map[string]interface{}{}}}, 0},
+		{"blank id", []engine.RuleConfig{{ID: "  "}}, 0},
+		{"default and configured rules", []engine.RuleConfig{{ID: ""}, {ID: "sellonly"}}, 1},
+	}
+
+	for _, tt := range tests {
+		rules, err := engine.BuildRules(tt.rcs)
+		if err != nil {
+			t.Errorf("%v: BuildRules returned an error: %v", tt.name, err)
+			continue
+		}
+		if len(rules) != tt.wantRules {
+			t.Errorf("%v: BuildRules returned %v rules, want %v", tt.name, len(rules), tt.wantRules)
+		}
+
+		var ct configTree
+		ct.RuleConfigs = tt.rcs
+		if _, errs := validateConfigTree(ct); len(errs) > 0 {
+			t.Errorf("%v: validateConfigTree returned errors: %v", tt.name, errs)
+		}
+	}
+}
+
+func TestValidateConfigTreeFilterRules(t *testing.T) {
+	authorBlock := engine.RuleConfig{ID: "authorblock", Configs: map[string]interface{}{"blocked": []interface{}{"scalper99"}}}
+	ramRule := engine.RuleConfig{ID: "ramunderprice", Configs: map[string]interface{}{"price": 100.0}}
+
+	tests := []struct {
+		name        string
+		matchMode   string
+		rcs         []engine.RuleConfig
+		wantWarning bool
+	}{
+		{"alone in the any match mode", "", []engine.RuleConfig{authorBlock}, true},
+		{"with other rules in the any match mode", "any", []engine.RuleConfig{ramRule, authorBlock}, true},
+		{"in the all match mode", "all", []engine.RuleConfig{ramRule, authorBlock}, false},
+		{"in an and rule", "", []engine.RuleConfig{{ID: "ramdeals", Op: "and", Rules: []engine.RuleConfig{ramRule, authorBlock}}}, false},
+		{"negated", "", []engine.RuleConfig{ramRule, {ID: "authorblock", Negate: true, Configs: authorBlock.Configs}}, false},
+	}
+
+	for _, tt := range tests {
+		var ct configTree
+		ct.Subreddits = defaultSubreddits
+		ct.MatchMode = tt.matchMode
+		ct.RuleConfigs = tt.rcs
+
+		warnings, errs := validateConfigTree(ct)
+		if len(errs) > 0 {
+			t.Fatalf("%v: validateConfigTree returned errors: %v", tt.name, errs)
+		}
+
+		var gotWarning bool
+		for _, warning := range warnings {
+			if strings.Contains(warning, "only filters posts out") {
+				gotWarning = true
+			}
+		}
+		if gotWarning != tt.wantWarning {
+			t.Errorf("%v: validateConfigTree warned = %v, want %v, warnings %q", tt.name, gotWarning, tt.wantWarning, warnings)
+		}
+	}
+}
+
+func TestGetRulesCompositionErrors(t *testing.T) {
+	disabled := false
+	ramRule := engine.RuleConfig{ID: "keywordmatch", Configs: map[string]interface{}{"keywords": []interface{}{"ram"}}}
+
+	tests := []struct {
+		name string
+		rc   engine.RuleConfig
+	}{
+		{"and rule without rules", engine.RuleConfig{ID: "deals", Op: "and"}},
+		{"or rule without enabled rules", engine.RuleConfig{ID: "deals", Op: "or", Rules: []engine.RuleConfig{{ID: "keywordmatch", Enabled: &disabled}}}},
+		{"not rule without a rule", engine.RuleConfig{ID: "deals", Op: "not"}},
+		{"unknown op", engine.RuleConfig{ID: "deals", Op: "xor", Rules: []engine.RuleConfig{ramRule}}},
+		{"unknown child rule", engine.RuleConfig{ID: "deals", Op: "and", Rules: []engine.RuleConfig{ramRule, {ID: "notarule"}}}},
+	}
+
+	for _, tt := range tests {
+		if _, err := engine.BuildRules([]engine.RuleConfig{tt.rc}); err == nil {
+			t.Errorf("%v: BuildRules returned no error", tt.name)
+		}
+		var ct configTree
+		ct.RuleConfigs = []engine.RuleConfig{tt.rc}
+		if _, errs := validateConfigTree(ct); len(errs) == 0 {
+			t.Errorf("%v: validateConfigTree returned no errors", tt.name)
+		}
+	}
+}
+
+func TestParseCmdArgsConfigFlag(t *testing.T) {
+	osArgs := os.Args
+	defer func() { os.Args = osArgs }()
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"--config", "custom.json"}, "custom.json"},
+		{[]string{"--config-path", "custom.json"}, "custom.json"},
+		{[]string{"-c", "custom.json", "validate-config"}, "custom.json"},
+		{nil, ""},
+	}
+
+	for _, tt := range tests {
+		os.Args = append([]string{progName}, tt.args...)
+		var pconfs progConfigs
+		if err := pconfs.parseCmdArgs(); err != nil {
+			t.Errorf("parseCmdArgs(%q) returned an error: %v", tt.args, err)
+		} else if pconfs.altConfigPath != tt.want {
+			t.Errorf("parseCmdArgs(%q) config path = %q, want %q", tt.args, pconfs.altConfigPath, tt.want)
+		}
+	}
+}
+
+func TestPrintPlan(t *testing.T) {
+	rules := []rule.Rule{&matchAllRule{name: "ramunderprice"}, &matchAllRule{name: "authorblock"}}
+	tests := []struct {
+		matchMode      string
+		subredditNames []string
+		want           string
+	}{
+		{
+			"all",
+			[]string{"buildapcsales"},
+			"rules (match mode all):\n    ramunderprice\n    authorblock\nsubreddits:\n    buildapcsales\n",
+		},
+		{
+			"",
+			[]string{"hardwareswap", "buildapcsalescanada"},
+			"rules (match mode any):\n    ramunderprice\n    authorblock\nsubreddits:\n    hardwareswap\n    buildapcsalescanada\n",
+		},
+	}
+
+	for _, tt := range tests {
+		var ct configTree
+		ct.MatchMode = tt.matchMode
+
+		var buf bytes.Buffer
+		printPlan(&buf, ct, rules, tt.subredditNames)
+		if got := buf.String(); got != tt.want {
+			t.Errorf("printPlan(%q, %v) printed %q, want %q", tt.matchMode, tt.subredditNames, got, tt.want)
+		}
+	}
+}
+
+func TestRunReturnsErrors(t *testing.T) {
+	invalidConfigPath := writeTestConfig(t, "invalid.json", `{"subreddits": [`)
+	unknownRuleConfigPath := writeTestConfig(t, "unknown.json", `{
+		"subreddits": ["buildapcsales"],
+		"rules": [{"id": "notarule"}]
+	}`)
+
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"config in a file", []string{"--config", filepath.Join(invalidConfigPath, "rsb.json")}},
+		{"invalid config", []string{"--config", invalidConfigPath}},
+		{"unknown rule", []string{"--config", unknownRuleConfigPath, "--dry-run"}},
+		{"invalid instance", []string{"--instance", "a/b"}},
+	}
+
+	for _, tt := range tests {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					t.Errorf("%v: run(%q) panicked: %v", tt.name, tt.args, r)
+				}
+			}()
+
+			if err := runArgs(t, tt.args...); err == nil {
+				t.Errorf("%v: run(%q) returned no error", tt.name, tt.args)
+			}
+		}()
+	}
+}
+
+func TestValidateConfigStrict(t *testing.T) {
+	// the rule is configured twice under the same name, which is only a warning
+	progConfigPath := writeTestConfig(t, "rsb.json", `{
+		"subreddits": ["buildapcsales"],
+		"rules": [
+			{"id": "ramunderprice", "configs": {"price": 100}},
+			{"id": "ramunderprice", "configs": {"price": 50}}
+		]
+	}`)
+	cleanConfigPath := writeTestConfig(t, "clean.json", `{
+		"subreddits": ["buildapcsales"],
+		"rules": [{"id": "ramunderprice", "configs": {"price": 100}}]
+	}`)
+
+	tests := []struct {
+		args    []string
+		wantErr bool
+	}{
+		{[]string{"--config", progConfigPath, "validate-config"}, false},
+		{[]string{"--config", progConfigPath, "validate-config", "--strict"}, true},
+		{[]string{"--config", cleanConfigPath, "validate-config", "--strict"}, false},
+	}
+
+	for _, tt := range tests {
+		if err := runArgs(t, tt.args...); (err != nil) != tt.wantErr {
+			t.Errorf("run(%v) returned error %v, want error %v", tt.args, err, tt.wantErr)
+		}
+	}
+}
+
+func TestEvaluateHistory(t *testing.T) {
+	historyPath := filepath.Join(t.TempDir(), "rsb.history.jsonl")
+	history := store.NewPostHistory(historyPath)
+	if err := history.Record([]*reddit.Post{
+		{ID: "old", Title: "[RAM] Corsair Vengeance 16GB DDR4 $39.99"},
+	}, time.Now().Add(-30*24*time.Hour)); err != nil {
+		t.Fatalf("failed to seed post history: %v", err)
+	}
+	if err := history.Record([]*reddit.Post{
+		{ID: "cheap", Title: "[RAM] Corsair Vengeance 16GB DDR4 $49.99"},
+		{ID: "pricey", Title: "[RAM] G.Skill Trident Z5 64GB DDR5 $189.99"},
+		{ID: "gpu", Title: "[GPU] RTX 4070 $549.99"},
+		{ID: "cheaper", Title: "[RAM] Crucial 8GB DDR4 $19.99"},
+	}, time.Now().Add(-time.Hour)); err != nil {
+		t.Fatalf("failed to seed post history: %v", err)
+	}
+
+	progConfigPath := writeTestConfig(t, "rsb.json", fmt.Sprintf(`{
+		"subreddits": ["buildapcsales"],
+		"history": {"enabled": true, "path": %q},
+		"rules": [{"id": "ramunderprice", "configs": {"price": 100}}]
+	}`, historyPath))
+
+	tests := []struct {
+		since string
+		want  string
+	}{
+		{"7d", "2 of 4 posts would match"},
+		{"60d", "3 of 5 posts would match"},
+		{"30m", "0 of 0 posts would match"},
+	}
+
+	for _, tt := range tests {
+		output, err := runArgsOutput(t, "--config", progConfigPath, "evaluate", "--since", tt.since)
+		if err != nil {
+			t.Fatalf("evaluate --since %v returned an error: %v", tt.since, err)
+		}
+		if !strings.Contains(output, tt.want) {
+			t.Errorf("evaluate --since %v printed %q, want it to contain %q", tt.since, output, tt.want)
+		}
+	}
+}
+
+func TestInstanceFilePaths(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"--show-config-path"}, filepath.Join(progName, "rsb.json")},
+		{[]string{"--instance", "work", "--show-config-path"}, filepath.Join(progName, "rsb-work.json")},
+	}
+	for _, tt := range tests {
+		output, err := runArgsOutput(t, tt.args...)
+		if err != nil {
+			t.Errorf("run(%q) error = %v", tt.args, err)
+			continue
+		}
+		if got := strings.TrimSpace(output); !strings.HasSuffix(got, string(filepath.Separator)+tt.want) {
+			t.Errorf("run(%q) printed %q, want a path ending in %q", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestFirstRunOnboarding(t *testing.T) {
+	configuredPath := writeTestConfig(t, "rsb.json", `{
+		"subreddits": ["buildapcsales"],
+		"rules": [{"id": "ramunderprice", "configs": {"price": 100}}]
+	}`)
+
+	tests := []struct {
+		name string
+		args []string
+		want bool
+	}{
+		// the default configuration file is created on the first run
+		{"first run", nil, true},
+		{"first evaluate", []string{"evaluate"}, true},
+		{"configured", []string{"--config", configuredPath, "--dry-run"}, false},
+	}
+
+	for _, tt := range tests {
+		output, err := runArgsOutput(t, tt.args...)
+		if err != nil {
+			t.Errorf("%v: run(%q) returned an error: %v", tt.name, tt.args, err)
+		}
+		if got := strings.Contains(output, "no rules are configured yet"); got != tt.want {
+			t.Errorf("%v: run(%q) printed onboarding = %v, want %v, output %q", tt.name, tt.args, got, tt.want, output)
+		}
+	}
+}
+
+func TestDefaultConfigRun(t *testing.T) {
+	// every run shares the configuration directory, so only the first run creates
+	// the default configuration file
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	osArgs := os.Args
+	defer func() { os.Args = osArgs }()
+
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr bool
+	}{
+		{"first run", nil, false},
+		{"second run", nil, false},
+		{"dry run", []string{"--dry-run"}, false},
+		{"evaluate", []string{"evaluate"}, false},
+		{"validate config", []string{"validate-config"}, false},
+	}
+
+	for _, tt := range tests {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					t.Errorf("%v: run(%q) panicked: %v", tt.name, tt.args, r)
+				}
+			}()
+
+			os.Args = append([]string{progName}, tt.args...)
+			if err := run(); (err != nil) != tt.wantErr {
+				t.Errorf("%v: run(%q) error = %v, wantErr %v", tt.name, tt.args, err, tt.wantErr)
+			}
+		}()
+	}
+}
+
+func TestConfigFlag(t *testing.T) {
+	contents := `{
+		"subreddits": ["buildapcsales"],
+		"rules": [{"id": "ramunderprice", "configs": {"price": 100}}]
+	}`
+	progConfigPath := writeTestConfig(t, "custom.json", contents)
+	missingConfigPath := filepath.Join(t.TempDir(), "missing.json")
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"show config path", []string{"--config", progConfigPath, "--show-config-path"}, progConfigPath + "\n"},
+		{"short flag", []string{"-c", progConfigPath, "--show-config-path"}, progConfigPath + "\n"},
+		{"long flag", []string{"--config-path", progConfigPath, "--show-config-path"}, progConfigPath + "\n"},
+		{"export config", []string{"--config", progConfigPath, "--export-config"}, contents + "\n"},
+		{"missing config", []string{"--config", missingConfigPath, "--show-config-path"}, missingConfigPath + "\n"},
+	}
+
+	for _, tt := range tests {
+		output, err := runArgsOutput(t, tt.args...)
+		if err != nil {
+			t.Errorf("%v: run(%q) returned an error: %v", tt.name, tt.args, err)
+		} else if output != tt.want {
+			t.Errorf("%v: run(%q) printed %q, want %q", tt.name, tt.args, output, tt.want)
+		}
+	}
+
+	// a configuration file that does not exist is created at the path
+	if _, err := os.Stat(missingConfigPath); err != nil {
+		t.Errorf("configuration file was not created at %v: %v", missingConfigPath, err)
+	}
+}
+
+func TestDryRun(t *testing.T) {
+	// there is no agent file or smtp server, so a run that gets as far as creating
+	// the bot fails
+	progConfigPath := writeTestConfig(t, "rsb.json", `{
+		"subreddits": ["buildapcsales"],
+		"matchMode": "all",
+		"rules": [
+			{"id": "ramunderprice", "configs": {"price": 100}},
+			{"id": "authorblock", "configs": {"blocked": ["scalper99"]}}
+		]
+	}`)
+
+	tests := []struct {
+		name    string
+		args    []string
+		want    string
+		wantErr bool
+	}{
+		{
+			"dry run",
+			[]string{"--config", progConfigPath, "--dry-run"},
+			"rules (match mode all):\n    ramunderprice\n    authorblock\nsubreddits:\n    buildapcsales\n",
+			false,
+		},
+		{
+			"dry run with subreddit flags",
+			[]string{"--config", progConfigPath, "--dry-run", "-r", "hardwareswap", "-r", "buildapcsalescanada"},
+			"rules (match mode all):\n    ramunderprice\n    authorblock\nsubreddits:\n    hardwareswap\n    buildapcsalescanada\n",
+			false,
+		},
+		{"run", []string{"--config", progConfigPath}, "", true},
+	}
+
+	for _, tt := range tests {
+		output, err := runArgsOutput(t, tt.args...)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%v: run(%q) error = %v, wantErr %v", tt.name, tt.args, err, tt.wantErr)
+		} else if output != tt.want {
+			t.Errorf("%v: run(%q) printed %q, want %q", tt.name, tt.args, output, tt.want)
+		}
+	}
+}
+
+func TestCreateDefaultProgConfig(t *testing.T) {
+	// a directory cannot be created under a regular file, even by root
+	notDirPath := writeTestConfig(t, "notadir", "")
+	existingConfigPath := writeTestConfig(t, "rsb.json", `{"subreddits": ["hardwareswap"]}`)
+
+	tests := []struct {
+		name              string
+		progConfigDirPath string
+		wantErr           string
+	}{
+		{"new directory", filepath.Join(t.TempDir(), "rsb"), ""},
+		{"unwritable directory", filepath.Join(notDirPath, "rsb"), "failed to create configuration directory " + filepath.Join(notDirPath, "rsb")},
+		{"existing configuration file", filepath.Dir(existingConfigPath), ""},
+	}
+
+	for _, tt := range tests {
+		err := createDefaultProgConfig(tt.progConfigDirPath, "rsb.json")
+		if tt.wantErr == "" && err != nil {
+			t.Errorf("%v: createDefaultProgConfig() returned an error: %v", tt.name, err)
+		} else if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+			t.Errorf("%v: createDefaultProgConfig() error = %v, want an error containing %q", tt.name, err, tt.wantErr)
+		}
+	}
+
+	// an existing configuration file is left as is
+	contents, err := ioutil.ReadFile(existingConfigPath)
+	if err != nil {
+		t.Fatalf("failed to read configuration file: %v", err)
+	}
+	if want := `{"subreddits": ["hardwareswap"]}`; string(contents) != want {
+		t.Errorf("existing configuration file = %q, want %q", contents, want)
+	}
+}
+
+func TestSeenDedupeAcrossRuns(t *testing.T) {
+	rules, err := engine.BuildRules([]engine.RuleConfig{
+		{ID: "ramunderprice", Configs: map[string]interface{}{"price": 100.0}},
+	})
+	if err != nil {
+		t.Fatalf("failed to build rules: %v", err)
+	}
+	h := engine.NewHeuristic(rules)
+	posts := []*reddit.Post{
+		{ID: "cheap", Title: "[RAM] Corsair Vengeance 16GB $49.99"},
+		{ID: "cheaper", Title: "[RAM] Crucial 8GB DDR4 $19.99"},
+		{ID: "gpu", Title: "[GPU] RTX 4070 $549.99"},
+	}
+	seenPath := filepath.Join(t.TempDir(), "rsb.seen.json")
+
+	// each run opens the seen store anew, as the program does when restarted
+	tests := []struct {
+		name        string
+		resetSeen   bool
+		wantMatches int
+	}{
+		{"first run", false, 2},
+		{"second run", false, 0},
+		{"run after resetting the seen posts", true, 2},
+		{"run after the reset", false, 0},
+	}
+
+	for _, tt := range tests {
+		seen, err := store.OpenSeenStore("", seenPath)
+		if err != nil {
+			t.Fatalf("%v: failed to open seen store: %v", tt.name, err)
+		}
+		if tt.resetSeen {
+			seen.Reset()
+		}
+
+		g := &postGather{prefilters: defaultPrefilters, seen: seen}
+		for _, p := range posts {
+			if err := g.Post(p); err != nil {
+				t.Fatalf("%v: Post returned an error: %v", tt.name, err)
+			}
+		}
+		matches := h.AppliedTo(g.getPostQueue(), g.getPostContexts())
+		g.markMatched(g.getPostQueue(), matches)
+		if err := seen.Close(); err != nil {
+			t.Fatalf("%v: failed to close seen store: %v", tt.name, err)
+		}
+
+		if len(matches) != tt.wantMatches {
+			t.Errorf("%v: matched %v posts, want %v", tt.name, len(matches), tt.wantMatches)
+		}
+	}
+}
+
+func TestShutdownContext(t *testing.T) {
+	tests := []struct {
+		name string
+		sig  syscall.Signal
+	}{
+		{"interrupted", syscall.SIGINT},
+		{"terminated", syscall.SIGTERM},
+	}
+
+	for _, tt := range tests {
+		seenPath := filepath.Join(t.TempDir(), "rsb.seen")
+		seen, err := store.NewFileSeenStore(seenPath)
+		if err != nil {
+			t.Fatalf("%v: NewFileSeenStore returned an error: %v", tt.name, err)
+		}
+		history := store.NewPostHistory(filepath.Join(t.TempDir(), "rsb.history"))
+
+		// the seen store is only saved by the cleanup run defers, not after each post
+		var reported []string
+		stream := newPostStream(seen, history, func(p *reddit.Post) { reported = append(reported, p.ID) }, nil)
+
+		ctx, cancel := shutdownContext()
+		bot := &failingBot{}
+		done := make(chan error, 1)
+		go func() {
+			pi := newPollInterval(time.Millisecond, time.Millisecond, 1)
+			done <- watchSubreddits(ctx, bot, []string{"buildapcsales"}, stream, pi, newHealthState(time.Minute))
+		}()
+
+		// wait for the loop to be underway before streaming a post and signaling
+		// the program
+		deadline := time.Now().Add(5 * time.Second)
+		for bot.numListings() == 0 && time.Now().Before(deadline) {
+			time.Sleep(time.Millisecond)
+		}
+		post := &reddit.Post{ID: "abc", Title: "[RAM] 32GB DDR5 $99", Subreddit: "buildapcsales"}
+		if err := stream.Post(post); err != nil {
+			t.Fatalf("%v: Post returned an error: %v", tt.name, err)
+		}
+		if _, err := os.Stat(seenPath); !os.IsNotExist(err) {
+			t.Fatalf("%v: seen store was saved before shutting down (%v)", tt.name, err)
+		}
+		if err := syscall.Kill(os.Getpid(), tt.sig); err != nil {
+			t.Fatalf("%v: failed to signal the program: %v", tt.name, err)
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(5 * time.Second):
+			t.Fatalf("%v: the context was not cancelled by %v", tt.name, tt.sig)
+		}
+		select {
+		case err := <-done:
+			if err != nil {
+				t.Errorf("%v: watchSubreddits() returned %v, want nil", tt.name, err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("%v: watchSubreddits() did not return after %v", tt.name, tt.sig)
+		}
+		cancel()
+
+		// run closes the seen store once watching returns
+		if err := seen.Close(); err != nil {
+			t.Fatalf("%v: Close returned an error: %v", tt.name, err)
+		}
+		saved, err := store.NewFileSeenStore(seenPath)
+		if err != nil {
+			t.Fatalf("%v: failed to reopen the seen store: %v", tt.name, err)
+		}
+		if !saved.Has(post.ID) {
+			t.Errorf("%v: seen store saved on shutdown is missing post %v", tt.name, post.ID)
+		}
+
+		records, err := history.Since(time.Time{})
+		if err != nil {
+			t.Fatalf("%v: Since returned an error: %v", tt.name, err)
+		}
+		if len(records) != 1 || records[0].Post.ID != post.ID {
+			t.Errorf("%v: history has %v records, want only post %v", tt.name, len(records), post.ID)
+		}
+		if !reflect.DeepEqual(reported, []string{post.ID}) {
+			t.Errorf("%v: reported %v, want %v", tt.name, reported, []string{post.ID})
+		}
+	}
+}
+
+func TestVersion(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"flag", []string{"--version"}},
+		{"command", []string{"version"}},
+	}
+
+	// the build information is set by the linker, so tests see the placeholders
+	want := "rsb dev (commit unknown, built unknown)\n"
+	for _, tt := range tests {
+		output, err := runArgsOutput(t, tt.args...)
+		if err != nil {
+			t.Fatalf("%v: run() returned an error: %v", tt.name, err)
+		}
+		if output != want {
+			t.Errorf("%v: printed %q, want %q", tt.name, output, want)
+		}
+	}
+}
+
+func TestFindAgentPath(t *testing.T) {
+	progFileDirPath := t.TempDir()
+	agentPath := filepath.Join(progFileDirPath, "rsb-work"+agentFileExt)
+	if err := ioutil.WriteFile(agentPath, []byte(""), 0o600); err != nil {
+		t.Fatalf("failed to write agent file: %v", err)
+	}
+
+	tests := []struct {
+		instName string
+		want     string
+		wantErr  bool
+	}{
+		{"rsb-work", agentPath, false},
+		{"rsb", "", true},
+		{"rsb-home", "", true},
+	}
+	for _, tt := range tests {
+		got, err := findAgentPath(progFileDirPath, tt.instName)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("findAgentPath(%q) error = %v, wantErr %v", tt.instName, err, tt.wantErr)
+		} else if got != tt.want {
+			t.Errorf("findAgentPath(%q) = %q, want %q", tt.instName, got, tt.want)
+		}
+	}
+}
+
+func TestResolveAgentPath(t *testing.T) {
+	progFileDirPath := t.TempDir()
+	instAgentPath := filepath.Join(progFileDirPath, "rsb-work"+agentFileExt)
+	customAgentPath := filepath.Join(t.TempDir(), "custom.agent")
+	for _, agentPath := range []string{instAgentPath, customAgentPath} {
+		if err := ioutil.WriteFile(agentPath, []byte(""), 0o600); err != nil {
+			t.Fatalf("failed to write agent file: %v", err)
+		}
+	}
+	missingAgentPath := filepath.Join(t.TempDir(), "missing.agent")
+
+	tests := []struct {
+		name      string
+		agentPath string
+		instName  string
+		want      string
+		wantErr   string
+	}{
+		{"instance agent file", defaultAgentPath, "rsb-work", instAgentPath, ""},
+		{"instance agent file missing", defaultAgentPath, "rsb", "", "no agent file was found at ./rsb.agent or " + filepath.Join(progFileDirPath, "rsb.agent")},
+		{"custom agent file", customAgentPath, "rsb", customAgentPath, ""},
+		{"custom agent file missing", missingAgentPath, "rsb-work", "", "the agent file " + missingAgentPath + " does not exist"},
+	}
+
+	for _, tt := range tests {
+		got, err := resolveAgentPath(tt.agentPath, progFileDirPath, tt.instName)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("%v: resolveAgentPath(%q) returned error %v, want error containing %q", tt.name, tt.agentPath, err, tt.wantErr)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%v: resolveAgentPath(%q) returned an error: %v", tt.name, tt.agentPath, err)
+		} else if got != tt.want {
+			t.Errorf("%v: resolveAgentPath(%q) = %q, want %q", tt.name, tt.agentPath, got, tt.want)
+		}
+	}
+}
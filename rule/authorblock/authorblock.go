@@ -0,0 +1,94 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package authorblock
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/cavcrosby/rsb/rule"
+	"github.com/turnage/graw/reddit"
+)
+
+// ensure the rule satisfies the rule interfaces at build time
+var (
+	_ rule.Rule       = (*AuthorBlock)(nil)
+	_ rule.FilterRule = (*AuthorBlock)(nil)
+)
+
+// A type that represents a rule that matches posts unless they are authored by a
+// blocked user (e.g. a known scalper). Usernames are case-insensitive and may be
+// prefixed with "u/". The rule matches nearly every post on its own, so it is
+// meant to be combined with other rules (e.g. in an and rule or with the "all"
+// match mode), validate-config warning otherwise.
+type AuthorBlock struct {
+	Blocked []string `json:"blocked"`
+	blocked map[string]bool
+}
+
+func (a *AuthorBlock) Name() string {
+	return "authorblock"
+}
+
+func (a *AuthorBlock) Description() string {
+	return "matches posts not authored by a blocked user"
+}
+
+func (a *AuthorBlock) FiltersOnly() bool {
+	return true
+}
+
+// Normalize a username for comparing against other usernames.
+func normalizeAuthor(author string) string {
+	author = strings.ToLower(strings.TrimSpace(author))
+	author = strings.TrimPrefix(author, "/")
+	return strings.TrimPrefix(author, "u/")
+}
+
+func (a *AuthorBlock) RegisterConfigs(configs []byte) error {
+	if err := json.Unmarshal(configs, a); err != nil {
+		return err
+	}
+
+	a.blocked = make(map[string]bool)
+	for _, author := range a.Blocked {
+		a.blocked[normalizeAuthor(author)] = true
+	}
+
+	return nil
+}
+
+func (a *AuthorBlock) Explain(post *reddit.Post) string {
+	if a.blocked[normalizeAuthor(post.Author)] {
+		return "author " + post.Author + " is blocked"
+	}
+
+	return "author " + post.Author + " is not blocked"
+}
+
+func (a *AuthorBlock) Match(post *reddit.Post) bool {
+	return !a.blocked[normalizeAuthor(post.Author)]
+}
+
+func init() {
+	rule.MustRegisterRule(func() rule.Rule {
+		return &AuthorBlock{}
+	})
+}
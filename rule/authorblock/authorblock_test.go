@@ -0,0 +1,51 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package authorblock
+
+import (
+	"testing"
+
+	"github.com/turnage/graw/reddit"
+)
+
+func TestMatch(t *testing.T) {
+	a := &AuthorBlock{}
+	if err := a.RegisterConfigs([]byte(`{"blocked": ["Scalper99", "u/flipper", "/u/Reseller"]}`)); err != nil {
+		t.Fatalf("RegisterConfigs returned an error: %v", err)
+	}
+
+	tests := []struct {
+		author string
+		want   bool
+	}{
+		{"scalper99", false},
+		{"SCALPER99", false},
+		{"flipper", false},
+		{"reseller", false},
+		{"dealhunter", true},
+		{"", true},
+	}
+
+	for _, tt := range tests {
+		if got := a.Match(&reddit.Post{Author: tt.author}); got != tt.want {
+			t.Errorf("Match(post by %q) = %v, want %v", tt.author, got, tt.want)
+		}
+	}
+}
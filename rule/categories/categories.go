@@ -0,0 +1,93 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package categories
+
+import (
+	"encoding/json"
+	"regexp"
+
+	"github.com/cavcrosby/rsb/rule"
+	"github.com/turnage/graw/reddit"
+)
+
+var (
+	defaultMinCategories int = 1
+)
+
+// ensure the rule satisfies the rule interface at build time
+var _ rule.Rule = (*Categories)(nil)
+
+// A type that represents a rule that matches posts whose titles mention a
+// minimum number of distinct keyword categories (e.g. a complete build deal
+// mentioning a CPU, GPU and RAM).
+type Categories struct {
+	Categories    map[string][]string `json:"categories"`
+	MinCategories int                 `json:"minCategories"`
+	reCategories  map[string][]*regexp.Regexp
+}
+
+func (c *Categories) Name() string {
+	return "categories"
+}
+
+func (c *Categories) Description() string {
+	return "matches titles mentioning a minimum number of keyword categories"
+}
+
+func (c *Categories) RegisterConfigs(configs []byte) error {
+	if err := json.Unmarshal(configs, c); err != nil {
+		return err
+	}
+
+	c.reCategories = make(map[string][]*regexp.Regexp)
+	for category, keywords := range c.Categories {
+		for _, keyword := range keywords {
+			re, err := regexp.Compile(`(?i)\b` + regexp.QuoteMeta(keyword) + `\b`)
+			if err != nil {
+				return err
+			}
+			c.reCategories[category] = append(c.reCategories[category], re)
+		}
+	}
+
+	return nil
+}
+
+func (c *Categories) Match(post *reddit.Post) bool {
+	var categoriesFound int
+	for _, reKeywords := range c.reCategories {
+		for _, reKeyword := range reKeywords {
+			if reKeyword.MatchString(post.Title) {
+				categoriesFound++
+				break
+			}
+		}
+	}
+
+	return categoriesFound > 0 && categoriesFound >= c.MinCategories
+}
+
+func init() {
+	rule.MustRegisterRule(func() rule.Rule {
+		return &Categories{
+			MinCategories: defaultMinCategories,
+		}
+	})
+}
@@ -0,0 +1,57 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package categories
+
+import (
+	"testing"
+
+	"github.com/turnage/graw/reddit"
+)
+
+func TestMatch(t *testing.T) {
+	c := &Categories{MinCategories: defaultMinCategories}
+	if err := c.RegisterConfigs([]byte(`{
+		"categories": {
+			"cpu": ["ryzen", "core i7"],
+			"gpu": ["rtx", "radeon"],
+			"ram": ["ddr4", "ddr5"]
+		},
+		"minCategories": 3
+	}`)); err != nil {
+		t.Fatalf("RegisterConfigs returned an error: %v", err)
+	}
+
+	tests := []struct {
+		title string
+		want  bool
+	}{
+		{"[Prebuilt] Ryzen 7 7800X3D, RTX 4070 Super, 32GB DDR5 - $1,499.99", true},
+		{"[Prebuilt] Core i7-14700F / Radeon RX 7800 XT / 16GB DDR4 $1,199", true},
+		{"[Prebuilt] Ryzen 5 7600, RTX 4060 - $899.99", false},
+		{"[RAM] G.Skill Trident Z5 32GB DDR5-6000 $94.99", false},
+		{"[Case] Lian Li O11 Dynamic EVO $139.99", false},
+	}
+
+	for _, tt := range tests {
+		if got := c.Match(&reddit.Post{Title: tt.title}); got != tt.want {
+			t.Errorf("Match(%q) = %v, want %v", tt.title, got, tt.want)
+		}
+	}
+}
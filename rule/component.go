@@ -0,0 +1,66 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package rule
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	RamComponent = "RAM"
+	CpuComponent = "CPU"
+	GpuComponent = "GPU"
+)
+
+var (
+	reComponentsInTitle = map[string]*regexp.Regexp{
+		RamComponent: regexp.MustCompile(`(?i)\bRAM\b`),
+		CpuComponent: regexp.MustCompile(`(?i)\b(?:CPU|Ryzen|Core\s?i[3579]|i[3579]-\d{4,5}|Threadripper|Xeon|Athlon|Pentium|Celeron)\b`),
+		GpuComponent: regexp.MustCompile(`(?i)\b(?:GPU|RTX\s?\d{4}|GTX\s?\d{3,4}|RX\s?\d{3,4}|Radeon|GeForce|Arc\s?[AB]\d{3})\b`),
+	}
+)
+
+// Get the names of the components that can be found in titles, in sorted order.
+func Components() []string {
+	var components []string
+	for component := range reComponentsInTitle {
+		components = append(components, component)
+	}
+	sort.Strings(components)
+
+	return components
+}
+
+// Determine if the component (e.g. "RAM") can be found in titles. Component names
+// are case-insensitive.
+func KnownComponent(component string) bool {
+	_, ok := reComponentsInTitle[strings.ToUpper(component)]
+	return ok
+}
+
+// Determine if the title mentions the component, either by name (e.g. "[CPU]") or
+// by a product line of the component (e.g. "Ryzen"). Unknown components are never
+// mentioned.
+func ComponentInTitle(component, title string) bool {
+	re, ok := reComponentsInTitle[strings.ToUpper(component)]
+	return ok && re.MatchString(title)
+}
@@ -0,0 +1,280 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package rule
+
+import (
+	"strings"
+
+	"github.com/turnage/graw/reddit"
+)
+
+const (
+	AndOp = "and"
+	OrOp  = "or"
+	NotOp = "not"
+)
+
+// Join the names of the rules, used to name composite rules that were not given
+// a name (e.g. "or(cpuunderprice,gpuunderprice)").
+func joinRuleNames(op string, rules []Rule) string {
+	var ruleNames []string
+	for _, r := range rules {
+		ruleNames = append(ruleNames, r.Name())
+	}
+
+	return op + "(" + strings.Join(ruleNames, ",") + ")"
+}
+
+// Join the names of the rules for describing composite rules (e.g. "cpuunderprice,
+// gpuunderprice").
+func describeRules(rules []Rule) string {
+	var ruleNames []string
+	for _, r := range rules {
+		ruleNames = append(ruleNames, r.Name())
+	}
+
+	return strings.Join(ruleNames, ", ")
+}
+
+// Join the explanations of the rules, each prefixed with the rule's name (e.g.
+// "cpuunderprice: $149.99 <= $150; keywordmatch: ...").
+func joinRuleExplanations(rules []Rule, post *reddit.Post) string {
+	var explanations []string
+	for _, r := range rules {
+		if explanation := ExplainRule(r, post); explanation != "" {
+			explanations = append(explanations, r.Name()+": "+explanation)
+		}
+	}
+
+	return strings.Join(explanations, "; ")
+}
+
+// A type that represents a rule that matches posts matched by all of its rules.
+type AndRule struct {
+	name  string
+	rules []Rule
+}
+
+// Create a rule matching posts matched by all of the rules. The name may be empty,
+// the rule then being named after its rules.
+func NewAndRule(name string, rules []Rule) *AndRule {
+	return &AndRule{name: name, rules: rules}
+}
+
+func (a *AndRule) Name() string {
+	if a.name != "" {
+		return a.name
+	}
+
+	return joinRuleNames(AndOp, a.rules)
+}
+
+func (a *AndRule) Description() string {
+	return "matches posts matched by all of: " + describeRules(a.rules)
+}
+
+// Configurations are registered on each of the rule's rules instead.
+func (a *AndRule) RegisterConfigs(configs []byte) error {
+	return nil
+}
+
+func (a *AndRule) Explain(post *reddit.Post) string {
+	return joinRuleExplanations(a.rules, post)
+}
+
+func (a *AndRule) Match(post *reddit.Post) bool {
+	matched, _ := EvaluateRule(a, post, PostContext{})
+	return matched
+}
+
+// A type that represents a rule that matches posts matched by any of its rules.
+type OrRule struct {
+	name  string
+	rules []Rule
+}
+
+// Create a rule matching posts matched by any of the rules. The name may be empty,
+// the rule then being named after its rules.
+func NewOrRule(name string, rules []Rule) *OrRule {
+	return &OrRule{name: name, rules: rules}
+}
+
+func (o *OrRule) Name() string {
+	if o.name != "" {
+		return o.name
+	}
+
+	return joinRuleNames(OrOp, o.rules)
+}
+
+func (o *OrRule) Description() string {
+	return "matches posts matched by any of: " + describeRules(o.rules)
+}
+
+// Configurations are registered on each of the rule's rules instead.
+func (o *OrRule) RegisterConfigs(configs []byte) error {
+	return nil
+}
+
+func (o *OrRule) Explain(post *reddit.Post) string {
+	return joinRuleExplanations(o.rules, post)
+}
+
+func (o *OrRule) Match(post *reddit.Post) bool {
+	matched, _ := EvaluateRule(o, post, PostContext{})
+	return matched
+}
+
+// A type that represents a rule that matches posts not matched by its rule.
+type NotRule struct {
+	name string
+	rule Rule
+}
+
+// Create a rule matching posts not matched by the rule. The name may be empty, the
+// rule then being named after the rule it negates.
+func NewNotRule(name string, r Rule) *NotRule {
+	return &NotRule{name: name, rule: r}
+}
+
+func (n *NotRule) Name() string {
+	if n.name != "" {
+		return n.name
+	}
+
+	return joinRuleNames(NotOp, []Rule{n.rule})
+}
+
+func (n *NotRule) Description() string {
+	return "matches posts not matched by: " + describeRules([]Rule{n.rule})
+}
+
+// Configurations are registered on the rule's rule instead.
+func (n *NotRule) RegisterConfigs(configs []byte) error {
+	return nil
+}
+
+func (n *NotRule) Explain(post *reddit.Post) string {
+	return joinRuleExplanations([]Rule{n.rule}, post)
+}
+
+func (n *NotRule) Match(post *reddit.Post) bool {
+	matched, _ := EvaluateRule(n, post, PostContext{})
+	return matched
+}
+
+// A type that represents a rule given another name, so the same rule can be
+// configured more than once (e.g. "ram-cheap" and "ram-bulk" for ramunderprice)
+// with each of its matches telling which configuration matched.
+type NamedRule struct {
+	name string
+	rule Rule
+}
+
+// Create a rule matching the same posts as the rule, under the name.
+func NewNamedRule(name string, r Rule) *NamedRule {
+	return &NamedRule{name: name, rule: r}
+}
+
+func (n *NamedRule) Name() string {
+	return n.name
+}
+
+func (n *NamedRule) Description() string {
+	return n.rule.Description()
+}
+
+func (n *NamedRule) RegisterConfigs(configs []byte) error {
+	return n.rule.RegisterConfigs(configs)
+}
+
+func (n *NamedRule) Explain(post *reddit.Post) string {
+	return ExplainRule(n.rule, post)
+}
+
+func (n *NamedRule) Match(post *reddit.Post) bool {
+	matched, _ := EvaluateRule(n, post, PostContext{})
+	return matched
+}
+
+// Determine if the rule matches the post. Rules that need the post's context are
+// given the context, rules whose matching can fail have their error returned and
+// composite rules (e.g. AndRule) evaluate their rules the same way.
+func EvaluateRule(r Rule, post *reddit.Post, pctx PostContext) (bool, error) {
+	switch r := r.(type) {
+	case *AndRule:
+		for _, child := range r.rules {
+			if matched, err := EvaluateRule(child, post, pctx); err != nil || !matched {
+				return false, err
+			}
+		}
+		return len(r.rules) > 0, nil
+	case *OrRule:
+		for _, child := range r.rules {
+			if matched, err := EvaluateRule(child, post, pctx); err != nil || matched {
+				return matched, err
+			}
+		}
+		return false, nil
+	case *NotRule:
+		matched, err := EvaluateRule(r.rule, post, pctx)
+		if err != nil {
+			return false, err
+		}
+		return !matched, nil
+	case *NamedRule:
+		return EvaluateRule(r.rule, post, pctx)
+	case ContextRule:
+		return r.MatchContext(post, pctx), nil
+	case FallibleRule:
+		return r.TryMatch(post)
+	default:
+		return r.Match(post), nil
+	}
+}
+
+// Determine if the rule, or any of the rules it is composed of, uses the post
+// history.
+func UsesHistory(r Rule) bool {
+	switch r := r.(type) {
+	case *AndRule:
+		for _, child := range r.rules {
+			if UsesHistory(child) {
+				return true
+			}
+		}
+		return false
+	case *OrRule:
+		for _, child := range r.rules {
+			if UsesHistory(child) {
+				return true
+			}
+		}
+		return false
+	case *NotRule:
+		return UsesHistory(r.rule)
+	case *NamedRule:
+		return UsesHistory(r.rule)
+	case HistoryRule:
+		return r.UsesHistory()
+	default:
+		return false
+	}
+}
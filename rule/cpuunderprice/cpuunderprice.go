@@ -0,0 +1,98 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package cpuunderprice
+
+import (
+	"encoding/json"
+
+	"github.com/cavcrosby/rsb/rule"
+	"github.com/turnage/graw/reddit"
+)
+
+var (
+	defaultPrice rule.Price = rule.Dollars(0)
+)
+
+// ensure the rule satisfies the rule interfaces at build time
+var (
+	_ rule.Rule         = (*CpuUnderPrice)(nil)
+	_ rule.Explainer    = (*CpuUnderPrice)(nil)
+	_ rule.FallibleRule = (*CpuUnderPrice)(nil)
+)
+
+// A type that represents a rule that matches CPU posts (e.g. "[CPU] Ryzen 5600X
+// $149.99") at or below a price. Costs above the maximum realistic price (e.g.
+// "$2000 total" of a build) are ignored, if set.
+type CpuUnderPrice struct {
+	Price             rule.Price `json:"price"`
+	MaxRealisticPrice rule.Price `json:"maxRealisticPrice"`
+}
+
+func (c *CpuUnderPrice) Name() string {
+	return "cpuunderprice"
+}
+
+func (c *CpuUnderPrice) Description() string {
+	return "matches CPUs at or below a price"
+}
+
+func (c *CpuUnderPrice) RegisterConfigs(configs []byte) error {
+	if err := json.Unmarshal(configs, c); err != nil {
+		return err
+	}
+
+	if err := rule.ValidatePrice("price", c.Price); err != nil {
+		return err
+	}
+
+	return rule.ValidateMaxRealisticPrice(c.MaxRealisticPrice, "price", c.Price)
+}
+
+// Determine if the post matches, along with the reason why.
+func (c *CpuUnderPrice) evaluate(post *reddit.Post) (bool, string, error) {
+	if !rule.ComponentInTitle(rule.CpuComponent, post.Title) {
+		return false, "no CPU in title", nil
+	}
+
+	return rule.UnderPrice(post.Title, c.Price, c.MaxRealisticPrice)
+}
+
+func (c *CpuUnderPrice) Explain(post *reddit.Post) string {
+	_, reason, _ := c.evaluate(post)
+	return reason
+}
+
+func (c *CpuUnderPrice) Match(post *reddit.Post) bool {
+	matched, _ := c.TryMatch(post)
+	return matched
+}
+
+func (c *CpuUnderPrice) TryMatch(post *reddit.Post) (bool, error) {
+	matched, _, err := c.evaluate(post)
+	return matched, err
+}
+
+func init() {
+	rule.MustRegisterRule(func() rule.Rule {
+		return &CpuUnderPrice{
+			Price: defaultPrice,
+		}
+	})
+}
@@ -0,0 +1,106 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package cpuunderprice
+
+import (
+	"testing"
+
+	"github.com/turnage/graw/reddit"
+)
+
+func TestMatch(t *testing.T) {
+	c := &CpuUnderPrice{Price: defaultPrice}
+	if err := c.RegisterConfigs([]byte(`{"price": 150}`)); err != nil {
+		t.Fatalf("RegisterConfigs returned an error: %v", err)
+	}
+
+	tests := []struct {
+		title      string
+		want       bool
+		wantReason string
+	}{
+		{"[CPU] Ryzen 5600X $149.99", true, "$149.99 <= $150"},
+		{"[CPU] AMD Ryzen 5 5600X 6-Core Processor - $129.99 ($149.99 - $20)", true, "$129.99 <= $150"},
+		{"[CPU] Intel Core i5-12600K $179.99", false, "$179.99 > $150"},
+		{"[Processor] Intel Core i3-12100F $89.99", true, "$89.99 <= $150"},
+		{"[CPU] Ryzen 5600X", false, "no cost in title"},
+		{"[RAM] Corsair Vengeance 16GB $49.99", false, "no CPU in title"},
+	}
+
+	for _, tt := range tests {
+		post := &reddit.Post{Title: tt.title}
+		if got := c.Match(post); got != tt.want {
+			t.Errorf("Match(%q) = %v, want %v", tt.title, got, tt.want)
+		}
+		if got := c.Explain(post); got != tt.wantReason {
+			t.Errorf("Explain(%q) = %q, want %q", tt.title, got, tt.wantReason)
+		}
+	}
+}
+
+func TestRegisterConfigs(t *testing.T) {
+	tests := []struct {
+		configs string
+		wantErr bool
+	}{
+		{`{"price": 150}`, false},
+		{`{"price": "€150"}`, false},
+		{`{}`, true},
+		{`{"price": -150}`, true},
+		{`{"price": 0}`, true},
+		{`{"price": "cheap"}`, true},
+	}
+
+	for _, tt := range tests {
+		c := &CpuUnderPrice{Price: defaultPrice}
+		if err := c.RegisterConfigs([]byte(tt.configs)); (err != nil) != tt.wantErr {
+			t.Errorf("RegisterConfigs(%v) error = %v, wantErr %v", tt.configs, err, tt.wantErr)
+		}
+	}
+}
+
+func TestMatchMaxRealisticPrice(t *testing.T) {
+	tests := []struct {
+		configs    string
+		title      string
+		want       bool
+		wantReason string
+	}{
+		{`{"price": 2500}`, "[CPU] Ryzen 7 7800X3D, build was $2000 total", true, "$2000 <= $2500"},
+		{`{"price": 2500, "maxRealisticPrice": 1500}`, "[CPU] Ryzen 7 7800X3D, build was $2000 total", false, "no cost in title"},
+		{`{"price": 2500, "maxRealisticPrice": 1500}`, "[CPU] Ryzen 7 7800X3D $349 (build was $2000 total)", true, "$349 <= $2500"},
+		{`{"price": 300, "maxRealisticPrice": 1500}`, "[CPU] Ryzen 7 7800X3D $349 (build was $2000 total)", false, "$349 > $300"},
+	}
+
+	for _, tt := range tests {
+		c := &CpuUnderPrice{Price: defaultPrice}
+		if err := c.RegisterConfigs([]byte(tt.configs)); err != nil {
+			t.Fatalf("RegisterConfigs(%v) returned an error: %v", tt.configs, err)
+		}
+
+		post := &reddit.Post{Title: tt.title}
+		if got := c.Match(post); got != tt.want {
+			t.Errorf("Match(%q) with %v = %v, want %v", tt.title, tt.configs, got, tt.want)
+		}
+		if got := c.Explain(post); got != tt.wantReason {
+			t.Errorf("Explain(%q) with %v = %q, want %q", tt.title, tt.configs, got, tt.wantReason)
+		}
+	}
+}
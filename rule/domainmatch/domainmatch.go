@@ -0,0 +1,105 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package domainmatch
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+
+	"github.com/cavcrosby/rsb/rule"
+	"github.com/turnage/graw/reddit"
+)
+
+// ensure the rule satisfies the rule interface at build time
+var _ rule.Rule = (*DomainMatch)(nil)
+
+// A type that represents a rule that only matches link posts whose URL is on one
+// of the domains (e.g. "amazon.com"). Subdomains of a domain also match (e.g.
+// "smile.amazon.com" matches "amazon.com"). Domains are case-insensitive and may
+// be prefixed with "www.". Self posts and posts without a URL do not match.
+type DomainMatch struct {
+	Domains []string `json:"domains"`
+	domains []string
+}
+
+func (d *DomainMatch) Name() string {
+	return "domainmatch"
+}
+
+func (d *DomainMatch) Description() string {
+	return "matches link posts to the domains"
+}
+
+// Normalize a domain for comparing against hosts.
+func normalizeDomain(domain string) string {
+	domain = strings.ToLower(strings.TrimSpace(domain))
+	domain = strings.TrimSuffix(domain, ".")
+	return strings.TrimPrefix(domain, "www.")
+}
+
+func (d *DomainMatch) RegisterConfigs(configs []byte) error {
+	if err := json.Unmarshal(configs, d); err != nil {
+		return err
+	}
+
+	d.domains = nil
+	for _, domain := range d.Domains {
+		if domain = normalizeDomain(domain); domain != "" {
+			d.domains = append(d.domains, domain)
+		}
+	}
+
+	return nil
+}
+
+func (d *DomainMatch) Sanity() []string {
+	if len(d.domains) == 0 {
+		return []string{"no domains are configured, no posts will match"}
+	}
+
+	return nil
+}
+
+func (d *DomainMatch) Match(post *reddit.Post) bool {
+	if post.IsSelf || post.URL == "" {
+		return false
+	}
+
+	u, err := url.Parse(post.URL)
+	if err != nil || u.Hostname() == "" {
+		return false
+	}
+
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	for _, domain := range d.domains {
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func init() {
+	rule.MustRegisterRule(func() rule.Rule {
+		return &DomainMatch{}
+	})
+}
@@ -0,0 +1,81 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package domainmatch
+
+import (
+	"testing"
+
+	"github.com/turnage/graw/reddit"
+)
+
+func TestMatch(t *testing.T) {
+	d := &DomainMatch{}
+	if err := d.RegisterConfigs([]byte(`{"domains": ["amazon.com", "WWW.Newegg.com", " bhphotovideo.com. "]}`)); err != nil {
+		t.Fatalf("RegisterConfigs returned an error: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		post *reddit.Post
+		want bool
+	}{
+		{"direct", &reddit.Post{URL: "https://amazon.com/dp/B08"}, true},
+		{"www prefix", &reddit.Post{URL: "https://www.amazon.com/dp/B08"}, true},
+		{"subdomain", &reddit.Post{URL: "https://smile.amazon.com/dp/B08"}, true},
+		{"uppercase host", &reddit.Post{URL: "https://WWW.NEWEGG.COM/p/N82E"}, true},
+		{"configured with www prefix", &reddit.Post{URL: "https://newegg.com/p/N82E"}, true},
+		{"trailing dot", &reddit.Post{URL: "https://bhphotovideo.com./c/product"}, true},
+		{"with port", &reddit.Post{URL: "https://amazon.com:443/dp/B08"}, true},
+		{"other domain", &reddit.Post{URL: "https://ebay.com/itm/123"}, false},
+		{"domain as suffix of another", &reddit.Post{URL: "https://notamazon.com/dp/B08"}, false},
+		{"domain in path", &reddit.Post{URL: "https://ebay.com/amazon.com"}, false},
+		{"self post", &reddit.Post{URL: "https://www.reddit.com/r/buildapcsales/comments/abc", IsSelf: true}, false},
+		{"no url", &reddit.Post{}, false},
+		{"invalid url", &reddit.Post{URL: "://amazon.com"}, false},
+	}
+
+	for _, tt := range tests {
+		if got := d.Match(tt.post); got != tt.want {
+			t.Errorf("%v: Match(%q) = %v, want %v", tt.name, tt.post.URL, got, tt.want)
+		}
+	}
+}
+
+func TestSanity(t *testing.T) {
+	tests := []struct {
+		configs  string
+		wantWarn bool
+	}{
+		{`{"domains": ["amazon.com"]}`, false},
+		{`{"domains": []}`, true},
+		{`{"domains": [" ", ""]}`, true},
+		{`{}`, true},
+	}
+
+	for _, tt := range tests {
+		d := &DomainMatch{}
+		if err := d.RegisterConfigs([]byte(tt.configs)); err != nil {
+			t.Fatalf("RegisterConfigs(%v) returned an error: %v", tt.configs, err)
+		}
+		if got := len(d.Sanity()) > 0; got != tt.wantWarn {
+			t.Errorf("Sanity() with %v warned = %v, want %v", tt.configs, got, tt.wantWarn)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package externalonly
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+
+	"github.com/cavcrosby/rsb/rule"
+	"github.com/turnage/graw/reddit"
+)
+
+var (
+	defaultAllowSelf bool = false
+	redditDomains         = []string{"reddit.com", "redd.it", "redditmedia.com"}
+)
+
+// ensure the rule satisfies the rule interface at build time
+var _ rule.Rule = (*ExternalOnly)(nil)
+
+// A type that represents a rule that only matches posts linking outside of
+// reddit (e.g. to a retailer). Self posts are matched only if allowed.
+type ExternalOnly struct {
+	AllowSelf bool `json:"allowSelf"`
+}
+
+func (e *ExternalOnly) Name() string {
+	return "externalonly"
+}
+
+func (e *ExternalOnly) Description() string {
+	return "matches posts linking outside of reddit"
+}
+
+func (e *ExternalOnly) RegisterConfigs(configs []byte) error {
+	if err := json.Unmarshal(configs, e); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (e *ExternalOnly) Match(post *reddit.Post) bool {
+	if post.IsSelf {
+		return e.AllowSelf
+	}
+
+	u, err := url.Parse(post.URL)
+	if err != nil || u.Hostname() == "" {
+		return false
+	}
+
+	host := strings.ToLower(u.Hostname())
+	for _, domain := range redditDomains {
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func init() {
+	rule.MustRegisterRule(func() rule.Rule {
+		return &ExternalOnly{
+			AllowSelf: defaultAllowSelf,
+		}
+	})
+}
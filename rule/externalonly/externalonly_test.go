@@ -0,0 +1,50 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package externalonly
+
+import (
+	"testing"
+
+	"github.com/turnage/graw/reddit"
+)
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		name      string
+		post      *reddit.Post
+		allowSelf bool
+		want      bool
+	}{
+		{"retailer link", &reddit.Post{URL: "https://www.newegg.com/p/N82E16820236682"}, false, true},
+		{"reddit link", &reddit.Post{URL: "https://www.reddit.com/r/buildapcsales/comments/abc123/"}, false, false},
+		{"reddit short link", &reddit.Post{URL: "https://redd.it/abc123"}, false, false},
+		{"reddit image", &reddit.Post{URL: "https://i.redditmedia.com/abc123.jpg"}, false, false},
+		{"no link", &reddit.Post{URL: ""}, false, false},
+		{"self post", &reddit.Post{IsSelf: true, URL: "https://www.reddit.com/r/buildapcsales/comments/abc123/"}, false, false},
+		{"allowed self post", &reddit.Post{IsSelf: true, URL: "https://www.reddit.com/r/buildapcsales/comments/abc123/"}, true, true},
+	}
+
+	for _, tt := range tests {
+		e := &ExternalOnly{AllowSelf: tt.allowSelf}
+		if got := e.Match(tt.post); got != tt.want {
+			t.Errorf("%v: Match(%q) = %v, want %v", tt.name, tt.post.URL, got, tt.want)
+		}
+	}
+}
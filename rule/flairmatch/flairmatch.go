@@ -0,0 +1,109 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package flairmatch
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/cavcrosby/rsb/rule"
+	"github.com/turnage/graw/reddit"
+)
+
+var (
+	noFlair string = "(none)"
+)
+
+// ensure the rule satisfies the rule interface at build time
+var _ rule.Rule = (*FlairMatch)(nil)
+
+// A type that represents a rule that matches posts whose link flair (e.g. "GPU")
+// is one of the flairs and not one of the excluded flairs (e.g. "Expired"). All
+// flairs are allowed if no flairs are configured. Posts without a flair are
+// referred to by the "(none)" flair. Flairs are case-insensitive.
+type FlairMatch struct {
+	Flairs  []string `json:"flairs"`
+	Exclude []string `json:"exclude"`
+	flairs  map[string]bool
+	exclude map[string]bool
+}
+
+func (f *FlairMatch) Name() string {
+	return "flairmatch"
+}
+
+func (f *FlairMatch) Description() string {
+	return "matches posts by their link flair"
+}
+
+// Normalize a flair for comparing against other flairs.
+func normalizeFlair(flair string) string {
+	flair = strings.ToLower(strings.TrimSpace(flair))
+	if flair == "" {
+		return noFlair
+	}
+
+	return flair
+}
+
+func (f *FlairMatch) RegisterConfigs(configs []byte) error {
+	if err := json.Unmarshal(configs, f); err != nil {
+		return err
+	}
+
+	f.flairs = make(map[string]bool)
+	for _, flair := range f.Flairs {
+		f.flairs[normalizeFlair(flair)] = true
+	}
+
+	f.exclude = make(map[string]bool)
+	for _, flair := range f.Exclude {
+		f.exclude[normalizeFlair(flair)] = true
+	}
+
+	return nil
+}
+
+func (f *FlairMatch) Explain(post *reddit.Post) string {
+	flair := normalizeFlair(post.LinkFlairText)
+	if f.exclude[flair] {
+		return fmt.Sprintf("flair %v is excluded", flair)
+	} else if len(f.flairs) > 0 && !f.flairs[flair] {
+		return fmt.Sprintf("flair %v is not allowed", flair)
+	}
+
+	return fmt.Sprintf("flair %v is allowed", flair)
+}
+
+func (f *FlairMatch) Match(post *reddit.Post) bool {
+	flair := normalizeFlair(post.LinkFlairText)
+	if f.exclude[flair] {
+		return false
+	}
+
+	return len(f.flairs) == 0 || f.flairs[flair]
+}
+
+func init() {
+	rule.MustRegisterRule(func() rule.Rule {
+		return &FlairMatch{}
+	})
+}
@@ -0,0 +1,63 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package flairmatch
+
+import (
+	"testing"
+
+	"github.com/turnage/graw/reddit"
+)
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		configs    string
+		flair      string
+		want       bool
+		wantReason string
+	}{
+		{`{"flairs": ["GPU", "CPU"]}`, "GPU", true, "flair gpu is allowed"},
+		{`{"flairs": ["GPU", "CPU"]}`, " cpu ", true, "flair cpu is allowed"},
+		{`{"flairs": ["GPU", "CPU"]}`, "RAM", false, "flair ram is not allowed"},
+		{`{"flairs": ["GPU", "CPU"]}`, "", false, "flair (none) is not allowed"},
+		{`{"exclude": ["Expired"]}`, "expired", false, "flair expired is excluded"},
+		{`{"exclude": ["Expired"]}`, "RAM", true, "flair ram is allowed"},
+		{`{"exclude": ["Expired"]}`, "", true, "flair (none) is allowed"},
+		{`{"exclude": ["(none)"]}`, "", false, "flair (none) is excluded"},
+		{`{"exclude": ["(none)"]}`, "GPU", true, "flair gpu is allowed"},
+		{`{"flairs": ["GPU", "(none)"], "exclude": ["GPU"]}`, "GPU", false, "flair gpu is excluded"},
+		{`{"flairs": ["GPU", "(none)"], "exclude": ["GPU"]}`, "", true, "flair (none) is allowed"},
+		{`{}`, "anything", true, "flair anything is allowed"},
+	}
+
+	for _, tt := range tests {
+		f := &FlairMatch{}
+		if err := f.RegisterConfigs([]byte(tt.configs)); err != nil {
+			t.Fatalf("RegisterConfigs(%v) returned an error: %v", tt.configs, err)
+		}
+
+		post := &reddit.Post{LinkFlairText: tt.flair}
+		if got := f.Match(post); got != tt.want {
+			t.Errorf("Match(flair %q) with %v = %v, want %v", tt.flair, tt.configs, got, tt.want)
+		}
+		if got := f.Explain(post); got != tt.wantReason {
+			t.Errorf("Explain(flair %q) with %v = %q, want %q", tt.flair, tt.configs, got, tt.wantReason)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package gpuunderprice
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/cavcrosby/rsb/logging"
+	"github.com/cavcrosby/rsb/rule"
+	"github.com/turnage/graw/reddit"
+)
+
+var (
+	defaultPrice  rule.Price = rule.Dollars(0)
+	reVramInTitle            = regexp.MustCompile(`(?i)\b(\d{1,2})\s?GB\b`)
+)
+
+// ensure the rule satisfies the rule interfaces at build time
+var (
+	_ rule.Rule          = (*GpuUnderPrice)(nil)
+	_ rule.SanityChecker = (*GpuUnderPrice)(nil)
+	_ rule.Explainer     = (*GpuUnderPrice)(nil)
+	_ rule.FallibleRule  = (*GpuUnderPrice)(nil)
+)
+
+// A type that represents a rule that matches GPU posts at or below a price, with
+// at least a minimum amount of VRAM (in GB) if set. Posts whose VRAM is not in the
+// title do not match when a minimum is set. Costs above the maximum realistic
+// price (e.g. "$2000 total" of a build) are ignored, if set.
+type GpuUnderPrice struct {
+	Price             rule.Price `json:"price"`
+	MinVram           int        `json:"minVram"`
+	MaxRealisticPrice rule.Price `json:"maxRealisticPrice"`
+}
+
+func (g *GpuUnderPrice) Name() string {
+	return "gpuunderprice"
+}
+
+func (g *GpuUnderPrice) Description() string {
+	return "matches GPUs at or below a price, with a minimum of VRAM"
+}
+
+func (g *GpuUnderPrice) RegisterConfigs(configs []byte) error {
+	if err := json.Unmarshal(configs, g); err != nil {
+		return err
+	}
+
+	if err := rule.ValidatePrice("price", g.Price); err != nil {
+		return err
+	}
+
+	return rule.ValidateMaxRealisticPrice(g.MaxRealisticPrice, "price", g.Price)
+}
+
+// Parse the VRAM (in GB) from the title, GPUs having at most a couple dozen GB of
+// VRAM (e.g. "RTX 4060 Ti 16GB").
+func vramInTitle(title string) (int, bool) {
+	submatches := reVramInTitle.FindStringSubmatch(title)
+	if submatches == nil {
+		return 0, false
+	}
+
+	vram, err := strconv.Atoi(submatches[1])
+	return vram, err == nil
+}
+
+func (g *GpuUnderPrice) Sanity() []string {
+	if g.MinVram < 0 {
+		return []string{fmt.Sprintf("minVram is %v, the VRAM check is skipped", g.MinVram)}
+	}
+
+	return nil
+}
+
+// Determine if the post matches, along with the reason why.
+func (g *GpuUnderPrice) evaluate(post *reddit.Post) (bool, string, error) {
+	if !rule.ComponentInTitle(rule.GpuComponent, post.Title) {
+		return false, "no GPU in title", nil
+	}
+
+	var reasons []string
+	if g.MinVram > 0 {
+		vram, ok := vramInTitle(post.Title)
+		if !ok {
+			logging.Debugf("%v: no VRAM in title of post %v, not matching", g.Name(), post.ID)
+			return false, "no VRAM in title", nil
+		} else if vram < g.MinVram {
+			return false, fmt.Sprintf("%vGB < %vGB", vram, g.MinVram), nil
+		}
+		reasons = append(reasons, fmt.Sprintf("%vGB >= %vGB", vram, g.MinVram))
+	}
+
+	matched, reason, err := rule.UnderPrice(post.Title, g.Price, g.MaxRealisticPrice)
+	if !matched {
+		return false, reason, err
+	}
+	reasons = append(reasons, reason)
+
+	return true, strings.Join(reasons, ", "), nil
+}
+
+func (g *GpuUnderPrice) Explain(post *reddit.Post) string {
+	_, reason, _ := g.evaluate(post)
+	return reason
+}
+
+func (g *GpuUnderPrice) Match(post *reddit.Post) bool {
+	matched, _ := g.TryMatch(post)
+	return matched
+}
+
+func (g *GpuUnderPrice) TryMatch(post *reddit.Post) (bool, error) {
+	matched, _, err := g.evaluate(post)
+	return matched, err
+}
+
+func init() {
+	rule.MustRegisterRule(func() rule.Rule {
+		return &GpuUnderPrice{
+			Price: defaultPrice,
+		}
+	})
+}
@@ -0,0 +1,103 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package gpuunderprice
+
+import (
+	"testing"
+
+	"github.com/turnage/graw/reddit"
+)
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		configs    string
+		title      string
+		want       bool
+		wantReason string
+	}{
+		{`{"price": 450, "minVram": 12}`, "[GPU] RTX 4070 12GB $429.99", true, "12GB >= 12GB, $429.99 <= $450"},
+		{`{"price": 450, "minVram": 12}`, "[GPU] RTX 4060 Ti 16GB $399.99", true, "16GB >= 12GB, $399.99 <= $450"},
+		{`{"price": 450, "minVram": 12}`, "[GPU] RTX 4060 8GB $289.99", false, "8GB < 12GB"},
+		{`{"price": 450, "minVram": 12}`, "[GPU] RTX 4070 Super $449.99", false, "no VRAM in title"},
+		{`{"price": 450, "minVram": 12}`, "[GPU] RX 7900 XTX 24GB $899.99", false, "$899.99 > $450"},
+		{`{"price": 450}`, "[GPU] RTX 4070 Super $449.99", true, "$449.99 <= $450"},
+		{`{"price": 450}`, "[GPU] RTX 4060 8GB $289.99", true, "$289.99 <= $450"},
+		{`{"price": 450}`, "[RAM] Corsair Vengeance 16GB $49.99", false, "no GPU in title"},
+	}
+
+	for _, tt := range tests {
+		g := &GpuUnderPrice{Price: defaultPrice}
+		if err := g.RegisterConfigs([]byte(tt.configs)); err != nil {
+			t.Fatalf("RegisterConfigs(%v) returned an error: %v", tt.configs, err)
+		}
+
+		post := &reddit.Post{Title: tt.title}
+		if got := g.Match(post); got != tt.want {
+			t.Errorf("Match(%q) with %v = %v, want %v", tt.title, tt.configs, got, tt.want)
+		}
+		if got := g.Explain(post); got != tt.wantReason {
+			t.Errorf("Explain(%q) with %v = %q, want %q", tt.title, tt.configs, got, tt.wantReason)
+		}
+	}
+}
+
+func TestRegisterConfigs(t *testing.T) {
+	tests := []struct {
+		configs string
+		wantErr bool
+	}{
+		{`{"price": 400}`, false},
+		{`{"price": "€400"}`, false},
+		{`{}`, true},
+		{`{"price": 0}`, true},
+		{`{"price": -400}`, true},
+		{`{"price": "cheap"}`, true},
+		{`{"price": 400, "maxRealisticPrice": -1}`, true},
+		{`{"price": 400, "maxRealisticPrice": "€2000"}`, true},
+	}
+
+	for _, tt := range tests {
+		g := &GpuUnderPrice{Price: defaultPrice}
+		if err := g.RegisterConfigs([]byte(tt.configs)); (err != nil) != tt.wantErr {
+			t.Errorf("RegisterConfigs(%v) error = %v, wantErr %v", tt.configs, err, tt.wantErr)
+		}
+	}
+}
+
+func TestVramInTitle(t *testing.T) {
+	tests := []struct {
+		title  string
+		want   int
+		wantOk bool
+	}{
+		{"RTX 4070 12GB", 12, true},
+		{"RTX 4060 Ti 16 GB", 16, true},
+		{"RX 7900 XTX 24gb", 24, true},
+		{"RTX 4070 Super", 0, false},
+		{"RTX 4070 with a 512GB SSD", 0, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := vramInTitle(tt.title)
+		if got != tt.want || ok != tt.wantOk {
+			t.Errorf("vramInTitle(%q) = %v, %v, want %v, %v", tt.title, got, ok, tt.want, tt.wantOk)
+		}
+	}
+}
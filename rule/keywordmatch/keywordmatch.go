@@ -0,0 +1,116 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package keywordmatch
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+
+	"github.com/cavcrosby/rsb/rule"
+	"github.com/turnage/graw/reddit"
+)
+
+var (
+	anyMode     string = "any"
+	allMode     string = "all"
+	defaultMode string = anyMode
+)
+
+// ensure the rule satisfies the rule interface at build time
+var _ rule.Rule = (*KeywordMatch)(nil)
+
+// A type that represents a rule that matches posts whose titles mention any (or
+// all) of the keywords. Keywords are matched case-insensitively as whole words
+// (e.g. "3080" does not match "13080"). If searchBody is set, the post's body
+// (selftext) is searched along with the title.
+type KeywordMatch struct {
+	Keywords   []string `json:"keywords"`
+	Mode       string   `json:"mode"`
+	SearchBody bool     `json:"searchBody"`
+	reKeywords []*regexp.Regexp
+}
+
+func (k *KeywordMatch) Name() string {
+	return "keywordmatch"
+}
+
+func (k *KeywordMatch) Description() string {
+	return "matches titles mentioning any (or all) of the keywords"
+}
+
+func (k *KeywordMatch) RegisterConfigs(configs []byte) error {
+	if err := json.Unmarshal(configs, k); err != nil {
+		return err
+	}
+
+	switch k.Mode {
+	case "":
+		k.Mode = defaultMode
+	case anyMode, allMode:
+	default:
+		return fmt.Errorf("the following mode is not known: %v", k.Mode)
+	}
+
+	k.reKeywords = nil
+	for _, keyword := range k.Keywords {
+		re, err := regexp.Compile(`(?i)\b` + regexp.QuoteMeta(keyword) + `\b`)
+		if err != nil {
+			return err
+		}
+		k.reKeywords = append(k.reKeywords, re)
+	}
+
+	return nil
+}
+
+func (k *KeywordMatch) Sanity() []string {
+	if len(k.Keywords) == 0 {
+		return []string{"no keywords are configured, no posts will match"}
+	}
+
+	return nil
+}
+
+func (k *KeywordMatch) Match(post *reddit.Post) bool {
+	if len(k.reKeywords) == 0 {
+		return false
+	}
+
+	text := rule.PostText(post, k.SearchBody)
+	for _, reKeyword := range k.reKeywords {
+		found := reKeyword.MatchString(text)
+		if found && k.Mode == anyMode {
+			return true
+		} else if !found && k.Mode == allMode {
+			return false
+		}
+	}
+
+	return k.Mode == allMode
+}
+
+func init() {
+	rule.MustRegisterRule(func() rule.Rule {
+		return &KeywordMatch{
+			Mode: defaultMode,
+		}
+	})
+}
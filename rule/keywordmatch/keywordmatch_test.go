@@ -0,0 +1,74 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package keywordmatch
+
+import (
+	"testing"
+
+	"github.com/turnage/graw/reddit"
+)
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		configs  string
+		title    string
+		selfText string
+		want     bool
+	}{
+		{`{"keywords": ["3080"]}`, "[GPU] EVGA RTX 3080 FTW3 $499", "", true},
+		{`{"keywords": ["3080"]}`, "[GPU] RTX 13080 $499", "", false},
+		{`{"keywords": ["rtx", "ftw3"], "mode": "all"}`, "[GPU] EVGA RTX 3080 FTW3 $499", "", true},
+		{`{"keywords": ["rtx", "xc3"], "mode": "all"}`, "[GPU] EVGA RTX 3080 FTW3 $499", "", false},
+		{`{"keywords": ["3080"]}`, "[GPU] Graphics card $499", "It is an RTX 3080", false},
+		{`{"keywords": ["3080"], "searchBody": true}`, "[GPU] Graphics card $499", "It is an RTX 3080", true},
+		{`{"keywords": ["3080"], "searchBody": true}`, "[GPU] Graphics card $499", "", false},
+		{`{"keywords": ["graphics", "3080"], "mode": "all", "searchBody": true}`, "[GPU] Graphics card $499", "It is an RTX 3080", true},
+		{`{"keywords": []}`, "[GPU] EVGA RTX 3080 FTW3 $499", "", false},
+	}
+
+	for _, tt := range tests {
+		k := &KeywordMatch{Mode: defaultMode}
+		if err := k.RegisterConfigs([]byte(tt.configs)); err != nil {
+			t.Fatalf("RegisterConfigs(%v) returned an error: %v", tt.configs, err)
+		}
+
+		if got := k.Match(&reddit.Post{Title: tt.title, SelfText: tt.selfText}); got != tt.want {
+			t.Errorf("Match(%q, %q) with %v = %v, want %v", tt.title, tt.selfText, tt.configs, got, tt.want)
+		}
+	}
+}
+
+func TestRegisterConfigsMode(t *testing.T) {
+	tests := []struct {
+		configs string
+		wantErr bool
+	}{
+		{`{"keywords": ["3080"]}`, false},
+		{`{"keywords": ["3080"], "mode": "all"}`, false},
+		{`{"keywords": ["3080"], "mode": "most"}`, true},
+	}
+
+	for _, tt := range tests {
+		k := &KeywordMatch{Mode: defaultMode}
+		if err := k.RegisterConfigs([]byte(tt.configs)); (err != nil) != tt.wantErr {
+			t.Errorf("RegisterConfigs(%v) error = %v, wantErr %v", tt.configs, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package maxage
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/cavcrosby/rsb/rule"
+	"github.com/turnage/graw/reddit"
+)
+
+var (
+	defaultMaxAgeMinutes int = 120
+)
+
+// ensure the rule satisfies the rule interface at build time
+var _ rule.Rule = (*MaxAge)(nil)
+
+// A type that represents a rule that only matches posts newer than a maximum age
+// (in minutes), so stale deals are ignored. A post's age is taken from when it was
+// created.
+type MaxAge struct {
+	MaxAgeMinutes int `json:"maxAgeMinutes"`
+	now           func() time.Time
+}
+
+func (m *MaxAge) Name() string {
+	return "maxage"
+}
+
+func (m *MaxAge) Description() string {
+	return "matches posts newer than a maximum age"
+}
+
+func (m *MaxAge) RegisterConfigs(configs []byte) error {
+	if err := json.Unmarshal(configs, m); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m *MaxAge) Sanity() []string {
+	if m.MaxAgeMinutes <= 0 {
+		return []string{fmt.Sprintf("maxAgeMinutes is %v, no posts will match", m.MaxAgeMinutes)}
+	}
+
+	return nil
+}
+
+// Get the age of the post.
+func (m *MaxAge) age(post *reddit.Post) time.Duration {
+	return m.now().Sub(time.Unix(int64(post.CreatedUTC), 0))
+}
+
+func (m *MaxAge) Explain(post *reddit.Post) string {
+	// the age is only rounded for display, so the reason agrees with Match
+	age := m.age(post)
+	if maxAge := time.Duration(m.MaxAgeMinutes) * time.Minute; age > maxAge {
+		return fmt.Sprintf("age %v > %v", age.Round(time.Minute), maxAge)
+	}
+
+	return fmt.Sprintf("age %v <= %v", age.Round(time.Minute), time.Duration(m.MaxAgeMinutes)*time.Minute)
+}
+
+func (m *MaxAge) Match(post *reddit.Post) bool {
+	return m.age(post) <= time.Duration(m.MaxAgeMinutes)*time.Minute
+}
+
+func init() {
+	rule.MustRegisterRule(func() rule.Rule {
+		return &MaxAge{
+			MaxAgeMinutes: defaultMaxAgeMinutes,
+			now:           time.Now,
+		}
+	})
+}
@@ -0,0 +1,57 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package maxage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/turnage/graw/reddit"
+)
+
+func TestMatch(t *testing.T) {
+	now := time.Date(2021, time.November, 26, 12, 0, 0, 0, time.UTC)
+	m := &MaxAge{MaxAgeMinutes: defaultMaxAgeMinutes, now: func() time.Time { return now }}
+	if err := m.RegisterConfigs([]byte(`{"maxAgeMinutes": 60}`)); err != nil {
+		t.Fatalf("RegisterConfigs returned an error: %v", err)
+	}
+
+	tests := []struct {
+		age        time.Duration
+		want       bool
+		wantReason string
+	}{
+		{0, true, "age 0s <= 1h0m0s"},
+		{15 * time.Minute, true, "age 15m0s <= 1h0m0s"},
+		{time.Hour, true, "age 1h0m0s <= 1h0m0s"},
+		{time.Hour + time.Second, false, "age 1h0m0s > 1h0m0s"},
+		{3 * time.Hour, false, "age 3h0m0s > 1h0m0s"},
+	}
+
+	for _, tt := range tests {
+		post := &reddit.Post{Title: "[RAM] Corsair Vengeance 16GB $49.99", CreatedUTC: uint64(now.Add(-tt.age).Unix())}
+		if got := m.Match(post); got != tt.want {
+			t.Errorf("Match(post aged %v) = %v, want %v", tt.age, got, tt.want)
+		}
+		if got := m.Explain(post); got != tt.wantReason {
+			t.Errorf("Explain(post aged %v) = %q, want %q", tt.age, got, tt.wantReason)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package maxrank
+
+import (
+	"encoding/json"
+
+	"github.com/cavcrosby/rsb/rule"
+	"github.com/turnage/graw/reddit"
+)
+
+var (
+	defaultMaxRank int = 25
+)
+
+// ensure the rule satisfies the rule interface at build time
+var _ rule.Rule = (*MaxRank)(nil)
+
+// A type that represents a rule that matches posts found near the top of the
+// listing they were fetched from, a cheap way to only match fresh posts. Posts of
+// an unknown rank do not match.
+type MaxRank struct {
+	MaxRank int `json:"maxRank"`
+}
+
+func (m *MaxRank) Name() string {
+	return "maxrank"
+}
+
+func (m *MaxRank) Description() string {
+	return "matches posts near the top of the listing they were fetched from"
+}
+
+func (m *MaxRank) RegisterConfigs(configs []byte) error {
+	if err := json.Unmarshal(configs, m); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m *MaxRank) Match(post *reddit.Post) bool {
+	return m.MatchContext(post, rule.PostContext{})
+}
+
+func (m *MaxRank) MatchContext(post *reddit.Post, pctx rule.PostContext) bool {
+	return pctx.Rank > 0 && pctx.Rank <= m.MaxRank
+}
+
+func init() {
+	rule.MustRegisterRule(func() rule.Rule {
+		return &MaxRank{
+			MaxRank: defaultMaxRank,
+		}
+	})
+}
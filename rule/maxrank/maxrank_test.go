@@ -0,0 +1,75 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package maxrank
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/cavcrosby/rsb/rule"
+	"github.com/turnage/graw/reddit"
+)
+
+func TestMatchContext(t *testing.T) {
+	m := &MaxRank{MaxRank: defaultMaxRank}
+	if err := m.RegisterConfigs([]byte(`{"maxRank": 3}`)); err != nil {
+		t.Fatalf("RegisterConfigs returned an error: %v", err)
+	}
+
+	tests := []struct {
+		rank int
+		want bool
+	}{
+		{0, false},
+		{1, true},
+		{2, true},
+		{3, true},
+		{4, false},
+		{100, false},
+	}
+
+	post := &reddit.Post{Title: "[RAM] Corsair Vengeance 32GB DDR5 $89.99"}
+	for _, tt := range tests {
+		if got := m.MatchContext(post, rule.PostContext{Rank: tt.rank}); got != tt.want {
+			t.Errorf("MatchContext(rank %v) = %v, want %v", tt.rank, got, tt.want)
+		}
+	}
+}
+
+func TestMatchListing(t *testing.T) {
+	m := &MaxRank{MaxRank: defaultMaxRank}
+
+	// ranks are assigned the way the poller does, the newest post being ranked 1
+	var matched int
+	for i := 0; i < 100; i++ {
+		post := &reddit.Post{ID: fmt.Sprintf("post%v", i)}
+		if m.MatchContext(post, rule.PostContext{Rank: i + 1}) {
+			matched++
+		}
+	}
+	if matched != defaultMaxRank {
+		t.Errorf("MatchContext matched %v posts of the listing, want %v", matched, defaultMaxRank)
+	}
+
+	// posts of an unknown rank (e.g. replayed from the post history) do not match
+	if m.Match(&reddit.Post{ID: "replayed"}) {
+		t.Errorf("Match(post of unknown rank) = true, want false")
+	}
+}
@@ -0,0 +1,107 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package mindiscount
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/cavcrosby/rsb/rule"
+	"github.com/turnage/graw/reddit"
+)
+
+var (
+	defaultMinPercentOff float64 = 0
+	rePercentOffInTitle          = []*regexp.Regexp{
+		regexp.MustCompile(`(?i)\b(\d{1,3}(?:\.\d+)?)\s?%\s?off\b`),
+		regexp.MustCompile(`(?i)\bsave\s+(\d{1,3}(?:\.\d+)?)\s?%`),
+	}
+)
+
+// ensure the rule satisfies the rule interface at build time
+var _ rule.Rule = (*MinDiscount)(nil)
+
+// A type that represents a rule that matches posts whose titles have a discount of
+// at least a minimum percentage (e.g. "40% off", "(25% Off)" or "save 50%"). Posts
+// without a discount in their title do not match.
+type MinDiscount struct {
+	MinPercentOff float64 `json:"minPercentOff"`
+}
+
+func (m *MinDiscount) Name() string {
+	return "mindiscount"
+}
+
+func (m *MinDiscount) Description() string {
+	return "matches posts with at least a percentage off"
+}
+
+func (m *MinDiscount) RegisterConfigs(configs []byte) error {
+	if err := json.Unmarshal(configs, m); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Parse the percentage off from the title, the largest being taken if the title
+// has more than one.
+func percentOffInTitle(title string) (float64, bool) {
+	var percentOff float64
+	var found bool
+	for _, re := range rePercentOffInTitle {
+		for _, submatches := range re.FindAllStringSubmatch(title, -1) {
+			if percent, err := strconv.ParseFloat(submatches[1], 64); err == nil && percent <= 100 {
+				if !found || percent > percentOff {
+					percentOff = percent
+				}
+				found = true
+			}
+		}
+	}
+
+	return percentOff, found
+}
+
+func (m *MinDiscount) Explain(post *reddit.Post) string {
+	percentOff, ok := percentOffInTitle(post.Title)
+	if !ok {
+		return "no percentage off in title"
+	} else if percentOff < m.MinPercentOff {
+		return fmt.Sprintf("%v%% off < %v%% off", percentOff, m.MinPercentOff)
+	}
+
+	return fmt.Sprintf("%v%% off >= %v%% off", percentOff, m.MinPercentOff)
+}
+
+func (m *MinDiscount) Match(post *reddit.Post) bool {
+	percentOff, ok := percentOffInTitle(post.Title)
+	return ok && percentOff >= m.MinPercentOff
+}
+
+func init() {
+	rule.MustRegisterRule(func() rule.Rule {
+		return &MinDiscount{
+			MinPercentOff: defaultMinPercentOff,
+		}
+	})
+}
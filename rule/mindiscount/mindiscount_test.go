@@ -0,0 +1,78 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package mindiscount
+
+import (
+	"testing"
+
+	"github.com/turnage/graw/reddit"
+)
+
+func TestPercentOffInTitle(t *testing.T) {
+	tests := []struct {
+		title  string
+		want   float64
+		wantOk bool
+	}{
+		{"[Monitor] LG 27GP850-B $299.99 40% off", 40, true},
+		{"[SSD] WD Black SN850X 2TB $129.99 (25% Off)", 25, true},
+		{"[PSU] Corsair RM850x $99.99 - save 50%", 50, true},
+		{"[Case] Lian Li O11 Dynamic 12.5% off", 12.5, true},
+		{"[Fans] Arctic P12 20% off, extra 35% off at checkout", 35, true},
+		{"[Bundle] 150% off", 0, false},
+		{"[RAM] Corsair Vengeance 16GB $49.99", 0, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := percentOffInTitle(tt.title)
+		if got != tt.want || ok != tt.wantOk {
+			t.Errorf("percentOffInTitle(%q) = %v, %v, want %v, %v", tt.title, got, ok, tt.want, tt.wantOk)
+		}
+	}
+}
+
+func TestMatch(t *testing.T) {
+	m := &MinDiscount{MinPercentOff: defaultMinPercentOff}
+	if err := m.RegisterConfigs([]byte(`{"minPercentOff": 30}`)); err != nil {
+		t.Fatalf("RegisterConfigs returned an error: %v", err)
+	}
+
+	tests := []struct {
+		title      string
+		want       bool
+		wantReason string
+	}{
+		{"[Monitor] LG 27GP850-B $299.99 40% off", true, "40% off >= 30% off"},
+		{"[SSD] WD Black SN850X 2TB $129.99 (25% Off)", false, "25% off < 30% off"},
+		{"[PSU] Corsair RM850x $99.99 - save 50%", true, "50% off >= 30% off"},
+		{"[Case] Lian Li O11 Dynamic 30% OFF", true, "30% off >= 30% off"},
+		{"[RAM] Corsair Vengeance 16GB $49.99", false, "no percentage off in title"},
+	}
+
+	for _, tt := range tests {
+		post := &reddit.Post{Title: tt.title}
+		if got := m.Match(post); got != tt.want {
+			t.Errorf("Match(%q) = %v, want %v", tt.title, got, tt.want)
+		}
+		if got := m.Explain(post); got != tt.wantReason {
+			t.Errorf("Explain(%q) = %q, want %q", tt.title, got, tt.wantReason)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package minscore
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/cavcrosby/rsb/rule"
+	"github.com/turnage/graw/reddit"
+)
+
+var (
+	defaultMinUpvotes int32 = 0
+)
+
+// ensure the rule satisfies the rule interface at build time
+var _ rule.Rule = (*MinScore)(nil)
+
+// A type that represents a rule that matches posts with at least a minimum score,
+// filtering out low-engagement (or possibly removed) deals. The post's Score is
+// used, being its upvotes minus its downvotes as reported by reddit.
+//
+// NOTE: posts are fetched as soon as they are new so their score is usually low,
+// the minimum should be set with that in mind.
+type MinScore struct {
+	MinUpvotes int32 `json:"minUpvotes"`
+}
+
+func (m *MinScore) Name() string {
+	return "minscore"
+}
+
+func (m *MinScore) Description() string {
+	return "matches posts with at least a minimum score"
+}
+
+func (m *MinScore) RegisterConfigs(configs []byte) error {
+	if err := json.Unmarshal(configs, m); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m *MinScore) Explain(post *reddit.Post) string {
+	if post.Score < m.MinUpvotes {
+		return fmt.Sprintf("score %v < %v", post.Score, m.MinUpvotes)
+	}
+
+	return fmt.Sprintf("score %v >= %v", post.Score, m.MinUpvotes)
+}
+
+func (m *MinScore) Match(post *reddit.Post) bool {
+	return post.Score >= m.MinUpvotes
+}
+
+func init() {
+	rule.MustRegisterRule(func() rule.Rule {
+		return &MinScore{
+			MinUpvotes: defaultMinUpvotes,
+		}
+	})
+}
@@ -0,0 +1,55 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package minscore
+
+import (
+	"testing"
+
+	"github.com/turnage/graw/reddit"
+)
+
+func TestMatch(t *testing.T) {
+	m := &MinScore{MinUpvotes: defaultMinUpvotes}
+	if err := m.RegisterConfigs([]byte(`{"minUpvotes": 10}`)); err != nil {
+		t.Fatalf("RegisterConfigs returned an error: %v", err)
+	}
+
+	tests := []struct {
+		score      int32
+		want       bool
+		wantReason string
+	}{
+		{-3, false, "score -3 < 10"},
+		{0, false, "score 0 < 10"},
+		{9, false, "score 9 < 10"},
+		{10, true, "score 10 >= 10"},
+		{250, true, "score 250 >= 10"},
+	}
+
+	for _, tt := range tests {
+		post := &reddit.Post{Title: "[RAM] Corsair Vengeance 16GB $49.99", Score: tt.score}
+		if got := m.Match(post); got != tt.want {
+			t.Errorf("Match(score %v) = %v, want %v", tt.score, got, tt.want)
+		}
+		if got := m.Explain(post); got != tt.wantReason {
+			t.Errorf("Explain(score %v) = %q, want %q", tt.score, got, tt.wantReason)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package perunitprice
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/cavcrosby/rsb/rule"
+	"github.com/turnage/graw/reddit"
+)
+
+var (
+	defaultMaxPerUnit rule.Price = rule.Dollars(0)
+	reQuantityInTitle            = []*regexp.Regexp{
+		regexp.MustCompile(`(?i)\b(\d+)\s*-?\s*(?:pack|pk)\b`),
+		regexp.MustCompile(`(?i)\b(?:set|pack) of (\d+)\b`),
+		regexp.MustCompile(`(?i)\b(\d+)\s?x\b`),
+	}
+)
+
+// ensure the rule satisfies the rule interfaces at build time
+var (
+	_ rule.Rule         = (*PerUnitPrice)(nil)
+	_ rule.Explainer    = (*PerUnitPrice)(nil)
+	_ rule.FallibleRule = (*PerUnitPrice)(nil)
+)
+
+// A type that represents a rule that matches multi-pack posts (e.g. "3-pack of
+// 120mm fans $30") whose sale price per unit is at or below a maximum, only costs
+// in the currency of the maximum being compared. Costs above the maximum
+// realistic price (e.g. "$2000 total" of a build) are ignored, if set.
+type PerUnitPrice struct {
+	MaxPerUnit        rule.Price `json:"maxPerUnit"`
+	MaxRealisticPrice rule.Price `json:"maxRealisticPrice"`
+}
+
+func (p *PerUnitPrice) Name() string {
+	return "perunitprice"
+}
+
+func (p *PerUnitPrice) Description() string {
+	return "matches multi-packs at or below a price per unit"
+}
+
+func (p *PerUnitPrice) RegisterConfigs(configs []byte) error {
+	if err := json.Unmarshal(configs, p); err != nil {
+		return err
+	}
+
+	if err := rule.ValidatePrice("maxPerUnit", p.MaxPerUnit); err != nil {
+		return err
+	}
+
+	return rule.ValidateMaxRealisticPrice(p.MaxRealisticPrice, "maxPerUnit", p.MaxPerUnit)
+}
+
+// Parse the pack quantity from the title, titles without a quantity are treated as
+// a single unit.
+func quantityInTitle(title string) int {
+	for _, re := range reQuantityInTitle {
+		if submatches := re.FindStringSubmatch(title); submatches != nil {
+			if quantity, err := strconv.Atoi(submatches[1]); err == nil && quantity > 0 {
+				return quantity
+			}
+		}
+	}
+
+	return 1
+}
+
+// Determine if the post matches, along with the reason why.
+func (p *PerUnitPrice) evaluate(post *reddit.Post) (bool, string, error) {
+	costs, err := rule.CostsInTitle(post.Title, p.MaxRealisticPrice)
+	if err != nil {
+		return false, fmt.Sprintf("costs could not be parsed: %v", err), err
+	}
+
+	cost, ok := rule.SalePrice(costs, p.MaxPerUnit.Currency)
+	if !ok {
+		return false, "no cost in title", nil
+	}
+
+	quantity := quantityInTitle(post.Title)
+	perUnit := rule.NewPrice(cost.Float()/float64(quantity), cost.Currency)
+	if cmp, err := perUnit.Cmp(p.MaxPerUnit); err != nil {
+		return false, fmt.Sprintf("costs could not be compared: %v", err), err
+	} else if cmp > 0 {
+		return false, fmt.Sprintf("%v/unit (%v for %v) > %v/unit", perUnit, cost, quantity, p.MaxPerUnit), nil
+	}
+
+	return true, fmt.Sprintf("%v/unit (%v for %v) <= %v/unit", perUnit, cost, quantity, p.MaxPerUnit), nil
+}
+
+func (p *PerUnitPrice) Explain(post *reddit.Post) string {
+	_, reason, _ := p.evaluate(post)
+	return reason
+}
+
+func (p *PerUnitPrice) Match(post *reddit.Post) bool {
+	matched, _ := p.TryMatch(post)
+	return matched
+}
+
+func (p *PerUnitPrice) TryMatch(post *reddit.Post) (bool, error) {
+	matched, _, err := p.evaluate(post)
+	return matched, err
+}
+
+func init() {
+	rule.MustRegisterRule(func() rule.Rule {
+		return &PerUnitPrice{
+			MaxPerUnit: defaultMaxPerUnit,
+		}
+	})
+}
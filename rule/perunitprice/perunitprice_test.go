@@ -0,0 +1,123 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package perunitprice
+
+import (
+	"testing"
+
+	"github.com/turnage/graw/reddit"
+)
+
+func TestMatch(t *testing.T) {
+	p := &PerUnitPrice{}
+	if err := p.RegisterConfigs([]byte(`{"maxPerUnit": 8}`)); err != nil {
+		t.Fatalf("RegisterConfigs returned an error: %v", err)
+	}
+
+	tests := []struct {
+		title      string
+		want       bool
+		wantReason string
+	}{
+		{"[Fans] Arctic P12 PWM 3-pack fans $30", false, "$10/unit ($30 for 3) > $8/unit"},
+		{"[Fans] Arctic P12 PWM 5x fans $30", true, "$6/unit ($30 for 5) <= $8/unit"},
+		{"[Fans] Noctua NF-A12x25 set of 4 $79.99", false, "$20/unit ($79.99 for 4) > $8/unit"},
+		{"[Fans] Lian Li UNI FAN 3 pk €21", false, "no cost in title"},
+		{"[Fans] Arctic P12 PWM $7.99", true, "$7.99/unit ($7.99 for 1) <= $8/unit"},
+		{"[Fans] Arctic P12 PWM 5x fans", false, "no cost in title"},
+	}
+
+	for _, tt := range tests {
+		post := &reddit.Post{Title: tt.title}
+		if got := p.Match(post); got != tt.want {
+			t.Errorf("Match(%q) = %v, want %v", tt.title, got, tt.want)
+		}
+		if got := p.Explain(post); got != tt.wantReason {
+			t.Errorf("Explain(%q) = %q, want %q", tt.title, got, tt.wantReason)
+		}
+	}
+}
+
+func TestRegisterConfigs(t *testing.T) {
+	tests := []struct {
+		configs string
+		wantErr bool
+	}{
+		{`{"maxPerUnit": 8}`, false},
+		{`{"maxPerUnit": "€8"}`, false},
+		{`{}`, true},
+		{`{"maxPerUnit": 0}`, true},
+		{`{"maxPerUnit": -8}`, true},
+		{`{"maxPerUnit": "cheap"}`, true},
+		{`{"maxPerUnit": 8, "maxRealisticPrice": -1}`, true},
+	}
+
+	for _, tt := range tests {
+		p := &PerUnitPrice{}
+		if err := p.RegisterConfigs([]byte(tt.configs)); (err != nil) != tt.wantErr {
+			t.Errorf("RegisterConfigs(%v) error = %v, wantErr %v", tt.configs, err, tt.wantErr)
+		}
+	}
+}
+
+func TestQuantityInTitle(t *testing.T) {
+	tests := []struct {
+		title string
+		want  int
+	}{
+		{"3-pack fans $30", 3},
+		{"3 pack fans $30", 3},
+		{"5pk fans $30", 5},
+		{"pack of 6 fans $30", 6},
+		{"5x fans $30", 5},
+		{"fans $30", 1},
+		{"0-pack fans $30", 1},
+	}
+
+	for _, tt := range tests {
+		if got := quantityInTitle(tt.title); got != tt.want {
+			t.Errorf("quantityInTitle(%q) = %v, want %v", tt.title, got, tt.want)
+		}
+	}
+}
+
+func TestMatchMaxRealisticPrice(t *testing.T) {
+	tests := []struct {
+		configs string
+		title   string
+		want    bool
+	}{
+		{`{"maxPerUnit": 800}`, "[Fans] Arctic P12 PWM 3-pack, build was $2000 total", true},
+		{`{"maxPerUnit": 800, "maxRealisticPrice": 1500}`, "[Fans] Arctic P12 PWM 3-pack, build was $2000 total", false},
+		{`{"maxPerUnit": 800, "maxRealisticPrice": 1500}`, "[Fans] Arctic P12 PWM 3-pack $30 (build was $2000 total)", true},
+		{`{"maxPerUnit": 8, "maxRealisticPrice": 1500}`, "[Fans] Arctic P12 PWM 3-pack $30 (build was $2000 total)", false},
+	}
+
+	for _, tt := range tests {
+		p := &PerUnitPrice{}
+		if err := p.RegisterConfigs([]byte(tt.configs)); err != nil {
+			t.Fatalf("RegisterConfigs(%v) returned an error: %v", tt.configs, err)
+		}
+
+		if got := p.Match(&reddit.Post{Title: tt.title}); got != tt.want {
+			t.Errorf("Match(%q) with %v = %v, want %v", tt.title, tt.configs, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,331 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package rule
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	USD = "USD"
+	CAD = "CAD"
+	EUR = "EUR"
+	GBP = "GBP"
+)
+
+const (
+	amountPattern          = `(?:\d{1,3}(?:[,.]\d{3})+|\d+)(?:[.,]\d+)?`
+	symbolPattern          = `CA\$|C\$|US\$|\$|€|£`
+	codePattern            = `USD|CAD|EUR|GBP`
+	discountAmountPattern  = `[$€£][\d,.]+`
+	discountKeywordPattern = `(?i)(?:off|mir|rebate|coupon|promo|discount)\b`
+)
+
+var (
+	reCostInTitle = regexp.MustCompile(
+		`(?i)(` + symbolPattern + `)\s?(` + amountPattern + `)(?:\s?\b(` + codePattern + `)\b)?` +
+			`|(` + amountPattern + `)\s?(€|£|\b(?:` + codePattern + `)\b)(?:$|\D)`,
+	)
+	reDiscountInTitle  = regexp.MustCompile(`(?:-\s*)?` + discountAmountPattern + `\s*` + discountKeywordPattern)
+	reDeductionInTitle = regexp.MustCompile(`(\([^()]*?` + discountAmountPattern + `\s*)-\s*` + discountAmountPattern)
+	rePrice            = regexp.MustCompile(
+		`(?i)^(` + symbolPattern + `)?\s?(` + amountPattern + `)\s?(€|£|` + codePattern + `)?$`,
+	)
+	currencySymbols = map[string]string{
+		"$":   USD,
+		"us$": USD,
+		"ca$": CAD,
+		"c$":  CAD,
+		"€":   EUR,
+		"£":   GBP,
+	}
+)
+
+// Get the currency of a currency symbol or code (e.g. "€" or "eur"), amounts
+// without either being in dollars.
+func currencyOf(symbolOrCode string) string {
+	if symbolOrCode == "" {
+		return USD
+	} else if currency, ok := currencySymbols[strings.ToLower(symbolOrCode)]; ok {
+		return currency
+	}
+
+	return strings.ToUpper(symbolOrCode)
+}
+
+// Parse an amount, which may use a decimal point or a decimal comma in any
+// currency (e.g. "1,299.99", "59,99" or "1.299,99"). The last separator is the
+// decimal separator unless exactly three digits follow it, in which case it is a
+// thousands separator (e.g. "1,299" or "1.299").
+func parseAmount(amount string) (float64, error) {
+	if i := strings.LastIndexAny(amount, ",."); i >= 0 && len(amount)-i-1 != 3 {
+		whole := strings.NewReplacer(",", "", ".", "").Replace(amount[:i])
+		amount = whole + "." + amount[i+1:]
+	} else {
+		amount = strings.NewReplacer(",", "", ".", "").Replace(amount)
+	}
+
+	return strconv.ParseFloat(amount, 64)
+}
+
+// A type that represents a price in a currency (e.g. "USD"). The amount is in the
+// currency's minor units (e.g. cents), so cents are not lost to rounding.
+//
+// In configurations a price is either a number of dollars (e.g. 100 or 59.99) or
+// a string, which may be in another currency (e.g. "$1,299.99", "€59,99" or
+// "49.99 GBP").
+type Price struct {
+	Amount   int64
+	Currency string
+}
+
+// Create a price of the amount (in major units, e.g. dollars) in the currency.
+func NewPrice(amount float64, currency string) Price {
+	return Price{Amount: int64(math.Round(amount * 100)), Currency: currency}
+}
+
+// Create a price of the amount in dollars.
+func Dollars(amount float64) Price {
+	return NewPrice(amount, USD)
+}
+
+// Parse a price from a string (e.g. "$1,299.99", "€59,99", "49.99 GBP" or
+// "59.99"). The currency is taken from the symbol or code, prices without either
+// being in dollars. Thousands separators are optional.
+func ParsePrice(s string) (Price, error) {
+	submatches := rePrice.FindStringSubmatch(strings.TrimSpace(s))
+	if submatches == nil {
+		return Price{}, fmt.Errorf("the following price could not be parsed: %v", s)
+	}
+
+	currency := currencyOf(submatches[1])
+	if submatches[3] != "" {
+		currency = currencyOf(submatches[3])
+	}
+
+	amount, err := parseAmount(submatches[2])
+	if err != nil {
+		return Price{}, err
+	}
+
+	return NewPrice(amount, currency), nil
+}
+
+// Get the currency of the price, prices without a currency being in dollars.
+func (p Price) currency() string {
+	if p.Currency == "" {
+		return USD
+	}
+
+	return p.Currency
+}
+
+// Determine if the price is in the same currency as another price.
+func (p Price) SameCurrency(other Price) bool {
+	return p.currency() == other.currency()
+}
+
+// Get the amount of the price in major units (e.g. dollars).
+func (p Price) Float() float64 {
+	return float64(p.Amount) / 100
+}
+
+// Compare the price to another price of the same currency, returning -1, 0 or 1
+// if the price is less than, equal to or greater than the other price. Prices in
+// different currencies cannot be compared.
+func (p Price) Cmp(other Price) (int, error) {
+	switch {
+	case !p.SameCurrency(other):
+		return 0, fmt.Errorf("cannot compare %v with %v, the currencies differ", p, other)
+	case p.Amount < other.Amount:
+		return -1, nil
+	case p.Amount > other.Amount:
+		return 1, nil
+	default:
+		return 0, nil
+	}
+}
+
+// Get the price as a string (e.g. "$59.99", "€100" or "59.99 CAD"), cents being
+// left out of whole amounts.
+func (p Price) String() string {
+	amount := strconv.FormatFloat(p.Float(), 'f', 2, 64)
+	if p.Amount%100 == 0 {
+		amount = strconv.FormatInt(p.Amount/100, 10)
+	}
+
+	switch p.currency() {
+	case USD:
+		return "$" + amount
+	case EUR:
+		return "€" + amount
+	case GBP:
+		return "£" + amount
+	default:
+		return amount + " " + p.currency()
+	}
+}
+
+func (p *Price) UnmarshalJSON(data []byte) error {
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	switch v := v.(type) {
+	case nil:
+		return nil
+	case float64:
+		*p = Dollars(v)
+	case string:
+		price, err := ParsePrice(v)
+		if err != nil {
+			return err
+		}
+		*p = price
+	default:
+		return fmt.Errorf("a price must be a number or a string, got %v", string(data))
+	}
+
+	return nil
+}
+
+func (p Price) MarshalJSON() ([]byte, error) {
+	if p.currency() == USD {
+		return json.Marshal(p.Float())
+	}
+
+	return json.Marshal(p.String())
+}
+
+// Parse the costs from anywhere in the title, ignoring those above the maximum
+// realistic price (if greater than 0, costs in other currencies being kept). Thousands
+// separators are allowed (e.g. "$1,299.00"). Only amounts with a currency symbol
+// (e.g. "$", "€", "£" or "CA$") or code (e.g. "59.99 CAD") are costs, which keeps
+// model numbers (e.g. "3200" of "DDR4-3200") from being mistaken for costs. A
+// symbol followed by an amount belongs to that amount, not the one before it
+// (e.g. "DDR4 €59,99" only costing €59.99).
+// Discounts are not costs either, these being amounts followed by a discount
+// keyword (e.g. "- $30 MIR" or "$20 off") and amounts deducted from another cost
+// in a parenthetical (e.g. the "$20" of "($109.99 - $20)"). Other amounts after a
+// dash are costs (e.g. "DDR4 3200 - $49.99").
+func CostsInTitle(title string, maxRealisticPrice Price) ([]Price, error) {
+	var allSubStrings int = -1
+	var costs []Price
+	title = reDiscountInTitle.ReplaceAllString(title, "")
+	title = reDeductionInTitle.ReplaceAllString(title, "${1}")
+	for _, submatches := range reCostInTitle.FindAllStringSubmatch(title, allSubStrings) {
+		amount, currency := submatches[2], currencyOf(submatches[1])
+		if submatches[3] != "" {
+			currency = currencyOf(submatches[3])
+		} else if submatches[4] != "" {
+			amount, currency = submatches[4], currencyOf(submatches[5])
+		}
+
+		amountValue, err := parseAmount(amount)
+		if err != nil {
+			return nil, err
+		}
+
+		cost := NewPrice(amountValue, currency)
+		if maxRealisticPrice.Amount > 0 && cost.SameCurrency(maxRealisticPrice) {
+			if cmp, err := cost.Cmp(maxRealisticPrice); err != nil {
+				return nil, err
+			} else if cmp > 0 {
+				continue
+			}
+		}
+		costs = append(costs, cost)
+	}
+
+	return costs, nil
+}
+
+// Get the sale price in the currency from the costs in a title. Titles can have
+// more than one cost (e.g. "$129.99 - $30 MIR = $99.99"), the lowest being taken
+// as the sale price once discounts are left out. Costs in other currencies are
+// left out, as they cannot be compared.
+func SalePrice(costs []Price, currency string) (Price, bool) {
+	want := Price{Currency: currency}
+	var price Price
+	var found bool
+	for _, cost := range costs {
+		if !cost.SameCurrency(want) {
+			continue
+		} else if !found || cost.Amount < price.Amount {
+			price, found = cost, true
+		}
+	}
+
+	return price, found
+}
+
+// Determine if the sale price in a title (see SalePrice) is at or below the price,
+// along with the reason why. Costs above the maximum realistic price (e.g. "$2000
+// total" of a build) are ignored, if set. An error is returned if the costs could
+// not be parsed or compared.
+func UnderPrice(title string, price, maxRealisticPrice Price) (bool, string, error) {
+	costs, err := CostsInTitle(title, maxRealisticPrice)
+	if err != nil {
+		return false, fmt.Sprintf("costs could not be parsed: %v", err), err
+	}
+
+	cost, ok := SalePrice(costs, price.Currency)
+	if !ok {
+		return false, "no cost in title", nil
+	}
+
+	if cmp, err := cost.Cmp(price); err != nil {
+		return false, fmt.Sprintf("costs could not be compared: %v", err), err
+	} else if cmp > 0 {
+		return false, fmt.Sprintf("%v > %v", cost, price), nil
+	}
+
+	return true, fmt.Sprintf("%v <= %v", cost, price), nil
+}
+
+// Check that a price configuration (e.g. "price") is greater than 0. A price of 0
+// (which is also what a missing price unmarshals to) would only match free items,
+// so it is rejected along with negative prices.
+func ValidatePrice(configName string, price Price) error {
+	if price.Amount <= 0 {
+		return fmt.Errorf("%v must be greater than 0, got %v", configName, price)
+	}
+
+	return nil
+}
+
+// Check that the maximum realistic price of a rule is not negative and, if set,
+// is in the same currency as the rule's price (e.g. "price") so the costs compared
+// with the price are also checked against it.
+func ValidateMaxRealisticPrice(maxRealisticPrice Price, configName string, price Price) error {
+	if maxRealisticPrice.Amount < 0 {
+		return fmt.Errorf("maxRealisticPrice must not be negative, got %v", maxRealisticPrice)
+	} else if maxRealisticPrice.Amount > 0 && !maxRealisticPrice.SameCurrency(price) {
+		return fmt.Errorf("maxRealisticPrice must be in the currency of %v (%v), got %v", configName, price.currency(), maxRealisticPrice)
+	}
+
+	return nil
+}
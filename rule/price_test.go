@@ -0,0 +1,410 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package rule
+
+import (
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+func TestCostsInTitleCents(t *testing.T) {
+	tests := []struct {
+		title string
+		want  []Price
+	}{
+		{"[RAM] Corsair Vengeance 16GB $100", []Price{Dollars(100)}},
+		{"[RAM] Corsair Vengeance 16GB $99.99", []Price{Dollars(99.99)}},
+		{"[RAM] Corsair Vengeance 16GB $1,299.00", []Price{Dollars(1299)}},
+		{"[RAM] Corsair Vengeance 16GB $100.", []Price{Dollars(100)}},
+		{"[RAM] Corsair Vengeance 16GB $0.99", []Price{Dollars(0.99)}},
+		{"[RAM] Corsair Vengeance 16GB $12,345,678.90", []Price{Dollars(12345678.90)}},
+	}
+
+	for _, tt := range tests {
+		got, err := CostsInTitle(tt.title, Price{})
+		if err != nil {
+			t.Errorf("CostsInTitle(%q) returned an error: %v", tt.title, err)
+		} else if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("CostsInTitle(%q) = %v, want %v", tt.title, got, tt.want)
+		}
+	}
+}
+
+func TestCostsInTitle(t *testing.T) {
+	tests := []struct {
+		title string
+		want  []Price
+	}{
+		{"[RAM] Corsair Vengeance LPX 16GB DDR4-3200 $49.99", []Price{Dollars(49.99)}},
+		{"[RAM] Corsair Vengeance 16GB ($59.99 - $10 = $49.99)", []Price{Dollars(59.99), Dollars(49.99)}},
+		{"[RAM] Corsair Vengeance 16GB $129.99 $30 MIR", []Price{Dollars(129.99)}},
+		{"[RAM] Corsair Vengeance 16GB DDR4 3200", nil},
+		{"[RAM] Corsair Vengeance LPX 16GB DDR4 3200 - $49.99", []Price{Dollars(49.99)}},
+		{"[RAM] G.Skill Ripjaws V 32GB (2x16GB) DDR4-3600 CL18 $79.99", []Price{Dollars(79.99)}},
+		{"[GPU] ASRock RX 7800 XT Challenger - $89.99 ($109.99 - $20)", []Price{Dollars(89.99), Dollars(109.99)}},
+		{"[SSD] WD Black SN850X 2TB $129.99 - $30 MIR = $99.99", []Price{Dollars(129.99), Dollars(99.99)}},
+		{"[CPU] Ryzen 5 5600X $149.99 ($20 off)", []Price{Dollars(149.99)}},
+		{"[Case] Lian Li O11 Dynamic", nil},
+	}
+
+	for _, tt := range tests {
+		got, err := CostsInTitle(tt.title, Price{})
+		if err != nil {
+			t.Errorf("CostsInTitle(%q) returned an error: %v", tt.title, err)
+		} else if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("CostsInTitle(%q) = %v, want %v", tt.title, got, tt.want)
+		}
+	}
+}
+
+func TestSalePrice(t *testing.T) {
+	tests := []struct {
+		costs  []Price
+		want   Price
+		wantOk bool
+	}{
+		{nil, Price{}, false},
+		{[]Price{Dollars(49.99)}, Dollars(49.99), true},
+		{[]Price{Dollars(129.99), Dollars(99.99)}, Dollars(99.99), true},
+	}
+
+	for _, tt := range tests {
+		if got, ok := SalePrice(tt.costs, USD); got != tt.want || ok != tt.wantOk {
+			t.Errorf("SalePrice(%v) = %v, %v, want %v, %v", tt.costs, got, ok, tt.want, tt.wantOk)
+		}
+	}
+}
+
+func TestUnderPrice(t *testing.T) {
+	tests := []struct {
+		title             string
+		price             Price
+		maxRealisticPrice Price
+		want              bool
+		wantReason        string
+	}{
+		{"[CPU] Ryzen 5 5600X $129.99 - $30 MIR = $99.99", Dollars(100), Price{}, true, "$99.99 <= $100"},
+		{"[CPU] Ryzen 5 5600X $100", Dollars(100), Price{}, true, "$100 <= $100"},
+		{"[CPU] Ryzen 5 5600X $149.99", Dollars(100), Price{}, false, "$149.99 > $100"},
+		{"[CPU] Ryzen 5 5600X €90", Dollars(100), Price{}, false, "no cost in title"},
+		{"[CPU] Ryzen 5 5600X €90", NewPrice(100, "EUR"), Price{}, true, "€90 <= €100"},
+		{"[CPU] Ryzen 5 5600X, build was $2000 total", Dollars(2500), Dollars(1500), false, "no cost in title"},
+	}
+
+	for _, tt := range tests {
+		got, reason, err := UnderPrice(tt.title, tt.price, tt.maxRealisticPrice)
+		if err != nil {
+			t.Errorf("UnderPrice(%q, %v) returned an error: %v", tt.title, tt.price, err)
+		} else if got != tt.want || reason != tt.wantReason {
+			t.Errorf("UnderPrice(%q, %v) = %v, %q, want %v, %q", tt.title, tt.price, got, reason, tt.want, tt.wantReason)
+		}
+	}
+}
+
+func TestCostsInTitleCurrencies(t *testing.T) {
+	tests := []struct {
+		title string
+		want  []Price
+	}{
+		{"[RAM] Corsair Vengeance 16GB DDR4 €59,99", []Price{NewPrice(59.99, EUR)}},
+		{"[RAM] Corsair Vengeance 16GB DDR4 59,99€", []Price{NewPrice(59.99, EUR)}},
+		{"[RAM] Corsair Vengeance 16GB DDR4 £49.99", []Price{NewPrice(49.99, GBP)}},
+		{"[RAM] Corsair Vengeance 16GB DDR4 $59.99", []Price{Dollars(59.99)}},
+		{"[RAM] Corsair Vengeance 16GB DDR4 $59,99", []Price{Dollars(59.99)}},
+		{"[GPU] Sapphire Pulse RX 7900 XTX €1.049,00", []Price{NewPrice(1049, EUR)}},
+		{"[RAM] Corsair Vengeance 16GB DDR4 $59.99 CAD", []Price{NewPrice(59.99, CAD)}},
+		{"[RAM] Corsair Vengeance 16GB DDR4 59.99 USD", []Price{Dollars(59.99)}},
+		{"[RAM] Corsair Vengeance 16GB DDR4 CA$79.99", []Price{NewPrice(79.99, CAD)}},
+	}
+
+	for _, tt := range tests {
+		got, err := CostsInTitle(tt.title, Price{})
+		if err != nil {
+			t.Errorf("CostsInTitle(%q) returned an error: %v", tt.title, err)
+		} else if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("CostsInTitle(%q) = %v, want %v", tt.title, got, tt.want)
+		}
+	}
+}
+
+func TestSalePriceCurrency(t *testing.T) {
+	costs := []Price{NewPrice(59.99, EUR), Dollars(49.99), NewPrice(44.99, GBP)}
+	tests := []struct {
+		currency string
+		want     Price
+		wantOk   bool
+	}{
+		{EUR, NewPrice(59.99, EUR), true},
+		{USD, Dollars(49.99), true},
+		{"", Dollars(49.99), true},
+		{GBP, NewPrice(44.99, GBP), true},
+		{CAD, Price{}, false},
+	}
+
+	for _, tt := range tests {
+		if got, ok := SalePrice(costs, tt.currency); ok != tt.wantOk || got != tt.want {
+			t.Errorf("SalePrice(%v, %q) = %v, %v, want %v, %v", costs, tt.currency, got, ok, tt.want, tt.wantOk)
+		}
+	}
+}
+
+func BenchmarkCostsInTitle(b *testing.B) {
+	title := "[RAM] G.Skill Ripjaws V 32GB (2x16GB) DDR4-3600 CL18 - $79.99 ($99.99 - $20)"
+	patterns := []*regexp.Regexp{reDiscountInTitle, reDeductionInTitle, reCostInTitle}
+
+	b.Run("precompiled", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			for _, re := range patterns {
+				re.FindAllString(title, -1)
+			}
+		}
+	})
+	b.Run("compiled per post", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			for _, re := range patterns {
+				regexp.MustCompile(re.String()).FindAllString(title, -1)
+			}
+		}
+	})
+	b.Run("CostsInTitle", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := CostsInTitle(title, Price{}); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func TestCostsInTitleMaxRealisticPrice(t *testing.T) {
+	tests := []struct {
+		title             string
+		maxRealisticPrice Price
+		want              []Price
+	}{
+		{"[CPU] Ryzen 7 7800X3D, build was $2000 total", Price{}, []Price{Dollars(2000)}},
+		{"[CPU] Ryzen 7 7800X3D, build was $2000 total", Dollars(1500), nil},
+		{"[CPU] Ryzen 7 7800X3D $349 (build was $2000 total)", Dollars(1500), []Price{Dollars(349)}},
+		{"[CPU] Ryzen 7 7800X3D $1500", Dollars(1500), []Price{Dollars(1500)}},
+		{"[CPU] Ryzen 7 7800X3D €2000", Dollars(1500), []Price{NewPrice(2000, "EUR")}},
+	}
+
+	for _, tt := range tests {
+		got, err := CostsInTitle(tt.title, tt.maxRealisticPrice)
+		if err != nil {
+			t.Errorf("CostsInTitle(%q, %v) returned an error: %v", tt.title, tt.maxRealisticPrice, err)
+		} else if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("CostsInTitle(%q, %v) = %v, want %v", tt.title, tt.maxRealisticPrice, got, tt.want)
+		}
+	}
+}
+
+func TestParseAmount(t *testing.T) {
+	tests := []struct {
+		amount  string
+		want    float64
+		wantErr bool
+	}{
+		{"100", 100, false},
+		{"99.99", 99.99, false},
+		{"1,299.00", 1299, false},
+		{"1,299", 1299, false},
+		{"1.299", 1299, false},
+		{"59,99", 59.99, false},
+		{"1.299,99", 1299.99, false},
+		{"", 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseAmount(tt.amount)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseAmount(%q) error = %v, wantErr %v", tt.amount, err, tt.wantErr)
+		} else if got != tt.want {
+			t.Errorf("parseAmount(%q) = %v, want %v", tt.amount, got, tt.want)
+		}
+	}
+}
+
+func TestValidatePrice(t *testing.T) {
+	tests := []struct {
+		price   Price
+		wantErr bool
+	}{
+		{Dollars(100), false},
+		{Price{Amount: 1, Currency: "EUR"}, false},
+		{Dollars(0), true},
+		{Price{}, true},
+		{Dollars(-50), true},
+	}
+
+	for _, tt := range tests {
+		if err := ValidatePrice("price", tt.price); (err != nil) != tt.wantErr {
+			t.Errorf("ValidatePrice(%v) error = %v, wantErr %v", tt.price, err, tt.wantErr)
+		}
+	}
+}
+
+func TestParsePrice(t *testing.T) {
+	tests := []struct {
+		s       string
+		want    Price
+		wantErr bool
+	}{
+		{"59.99", Dollars(59.99), false},
+		{"$59.99", Dollars(59.99), false},
+		{" $100 ", Dollars(100), false},
+		{"$1,299.99", Dollars(1299.99), false},
+		{"1299.99", Dollars(1299.99), false},
+		{"€59,99", NewPrice(59.99, EUR), false},
+		{"1.299,99€", NewPrice(1299.99, EUR), false},
+		{"£49.99", NewPrice(49.99, GBP), false},
+		{"49.99 GBP", NewPrice(49.99, GBP), false},
+		{"CA$79", NewPrice(79, CAD), false},
+		{"59.99 cad", NewPrice(59.99, CAD), false},
+		{"", Price{}, true},
+		{"cheap", Price{}, true},
+		{"$", Price{}, true},
+		{"-$50", Price{}, true},
+		{"$50 each", Price{}, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParsePrice(tt.s)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParsePrice(%q) error = %v, wantErr %v", tt.s, err, tt.wantErr)
+		} else if got != tt.want {
+			t.Errorf("ParsePrice(%q) = %#v, want %#v", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestPriceCmp(t *testing.T) {
+	tests := []struct {
+		p       Price
+		other   Price
+		want    int
+		wantErr bool
+	}{
+		{Dollars(59.99), Dollars(100), -1, false},
+		{Dollars(100), Dollars(59.99), 1, false},
+		{Dollars(59.99), Dollars(59.99), 0, false},
+		{Dollars(59.99), NewPrice(59.99, USD), 0, false},
+		{Price{Amount: 5999}, Dollars(59.99), 0, false},
+		{Dollars(59.99), NewPrice(59.991, USD), 0, false},
+		{NewPrice(49.99, EUR), NewPrice(50, EUR), -1, false},
+		{NewPrice(59.99, EUR), Dollars(59.99), 0, true},
+		{NewPrice(59.99, CAD), Dollars(100), 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := tt.p.Cmp(tt.other)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%v.Cmp(%v) error = %v, wantErr %v", tt.p, tt.other, err, tt.wantErr)
+		} else if got != tt.want {
+			t.Errorf("%v.Cmp(%v) = %v, want %v", tt.p, tt.other, got, tt.want)
+		}
+	}
+}
+
+func TestPriceString(t *testing.T) {
+	tests := []struct {
+		p    Price
+		want string
+	}{
+		{Dollars(59.99), "$59.99"},
+		{Dollars(100), "$100"},
+		{Dollars(0.5), "$0.50"},
+		{Price{Amount: 1000}, "$10"},
+		{NewPrice(59.99, EUR), "€59.99"},
+		{NewPrice(49, GBP), "£49"},
+		{NewPrice(79.99, CAD), "79.99 CAD"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.p.String(); got != tt.want {
+			t.Errorf("%#v.String() = %q, want %q", tt.p, got, tt.want)
+		}
+	}
+}
+
+func TestPriceUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		data    string
+		want    Price
+		wantErr bool
+	}{
+		{`100`, Dollars(100), false},
+		{`59.99`, Dollars(59.99), false},
+		{`"$1,299.99"`, Dollars(1299.99), false},
+		{`"€59,99"`, NewPrice(59.99, EUR), false},
+		{`"49.99 GBP"`, NewPrice(49.99, GBP), false},
+		{`null`, Dollars(10), false},
+		{`"cheap"`, Price{}, true},
+		{`true`, Price{}, true},
+		{`[100]`, Price{}, true},
+	}
+
+	for _, tt := range tests {
+		// null leaves the price as it was
+		got := Dollars(10)
+		err := json.Unmarshal([]byte(tt.data), &got)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Unmarshal(%v) error = %v, wantErr %v", tt.data, err, tt.wantErr)
+		} else if err == nil && got != tt.want {
+			t.Errorf("Unmarshal(%v) = %#v, want %#v", tt.data, got, tt.want)
+		}
+	}
+}
+
+func TestPriceJSONRoundTrip(t *testing.T) {
+	tests := []struct {
+		p    Price
+		want string
+	}{
+		{Dollars(100), `100`},
+		{Dollars(59.99), `59.99`},
+		{Price{Amount: 5999}, `59.99`},
+		{NewPrice(59.99, EUR), `"€59.99"`},
+		{NewPrice(49, GBP), `"£49"`},
+		{NewPrice(79.99, CAD), `"79.99 CAD"`},
+	}
+
+	for _, tt := range tests {
+		data, err := json.Marshal(tt.p)
+		if err != nil {
+			t.Fatalf("Marshal(%#v) returned an error: %v", tt.p, err)
+		}
+		if string(data) != tt.want {
+			t.Errorf("Marshal(%#v) = %s, want %s", tt.p, data, tt.want)
+		}
+
+		var got Price
+		if err := json.Unmarshal(data, &got); err != nil {
+			t.Fatalf("Unmarshal(%s) returned an error: %v", data, err)
+		}
+		if got.Amount != tt.p.Amount || !got.SameCurrency(tt.p) {
+			t.Errorf("Unmarshal(Marshal(%#v)) = %#v", tt.p, got)
+		}
+	}
+}
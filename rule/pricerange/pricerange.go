@@ -0,0 +1,137 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package pricerange
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/cavcrosby/rsb/rule"
+	"github.com/turnage/graw/reddit"
+)
+
+// ensure the rule satisfies the rule interfaces at build time
+var (
+	_ rule.Rule         = (*PriceRange)(nil)
+	_ rule.Explainer    = (*PriceRange)(nil)
+	_ rule.FallibleRule = (*PriceRange)(nil)
+)
+
+// A type that represents a rule that matches posts whose price is within a range
+// (inclusive), e.g. RAM between $40 and $80. Only posts that mention the
+// component (e.g. "RAM", "CPU" or "GPU") match, if set. Costs above the maximum
+// realistic price (e.g. "$2000 total" of a build) are ignored, if set.
+type PriceRange struct {
+	Min               rule.Price `json:"min"`
+	Max               rule.Price `json:"max"`
+	Component         string     `json:"component"`
+	MaxRealisticPrice rule.Price `json:"maxRealisticPrice"`
+}
+
+func (p *PriceRange) Name() string {
+	return "pricerange"
+}
+
+func (p *PriceRange) Description() string {
+	return "matches posts whose price is within a range"
+}
+
+func (p *PriceRange) RegisterConfigs(configs []byte) error {
+	if err := json.Unmarshal(configs, p); err != nil {
+		return err
+	}
+
+	// a min of 0 is in the currency of max, so costs can be compared with both
+	if p.Min.Amount == 0 {
+		p.Min.Currency = p.Max.Currency
+	}
+
+	if err := rule.ValidatePrice("max", p.Max); err != nil {
+		return err
+	} else if p.Min.Amount < 0 {
+		return fmt.Errorf("min must not be negative, got %v", p.Min)
+	} else if cmp, err := p.Min.Cmp(p.Max); err != nil {
+		return fmt.Errorf("min and max must be in the same currency, got %v and %v", p.Min, p.Max)
+	} else if cmp > 0 {
+		return fmt.Errorf("min must not be greater than max, got %v and %v", p.Min, p.Max)
+	} else if err := rule.ValidateMaxRealisticPrice(p.MaxRealisticPrice, "max", p.Max); err != nil {
+		return err
+	} else if p.Component != "" && !rule.KnownComponent(p.Component) {
+		return fmt.Errorf(
+			"the following component is not known: %v (known components: %v)",
+			p.Component,
+			strings.Join(rule.Components(), ", "),
+		)
+	}
+
+	return nil
+}
+
+// Determine if the post matches, along with the reason why.
+func (p *PriceRange) evaluate(post *reddit.Post) (bool, string, error) {
+	if p.Component != "" && !rule.ComponentInTitle(p.Component, post.Title) {
+		return false, fmt.Sprintf("no %v in title", strings.ToUpper(p.Component)), nil
+	}
+
+	costs, err := rule.CostsInTitle(post.Title, p.MaxRealisticPrice)
+	if err != nil {
+		return false, fmt.Sprintf("costs could not be parsed: %v", err), err
+	}
+
+	cost, ok := rule.SalePrice(costs, p.Max.Currency)
+	if !ok {
+		return false, "no cost in title", nil
+	}
+
+	if cmp, err := cost.Cmp(p.Min); err != nil {
+		return false, fmt.Sprintf("costs could not be compared: %v", err), err
+	} else if cmp < 0 {
+		return false, fmt.Sprintf("%v < %v", cost, p.Min), nil
+	}
+	if cmp, err := cost.Cmp(p.Max); err != nil {
+		return false, fmt.Sprintf("costs could not be compared: %v", err), err
+	} else if cmp > 0 {
+		return false, fmt.Sprintf("%v > %v", cost, p.Max), nil
+	}
+
+	return true, fmt.Sprintf("%v <= %v <= %v", p.Min, cost, p.Max), nil
+}
+
+func (p *PriceRange) Explain(post *reddit.Post) string {
+	_, reason, _ := p.evaluate(post)
+	return reason
+}
+
+func (p *PriceRange) Match(post *reddit.Post) bool {
+	matched, _ := p.TryMatch(post)
+	return matched
+}
+
+func (p *PriceRange) TryMatch(post *reddit.Post) (bool, error) {
+	matched, _, err := p.evaluate(post)
+	return matched, err
+}
+
+func init() {
+	rule.MustRegisterRule(func() rule.Rule {
+		return &PriceRange{}
+	})
+}
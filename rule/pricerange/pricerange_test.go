@@ -0,0 +1,91 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package pricerange
+
+import (
+	"testing"
+
+	"github.com/turnage/graw/reddit"
+)
+
+func TestRegisterConfigs(t *testing.T) {
+	tests := []struct {
+		configs string
+		wantErr bool
+	}{
+		{`{"min": 40, "max": 80}`, false},
+		{`{"max": 80}`, false},
+		{`{"min": 80, "max": 80}`, false},
+		{`{"min": "€40", "max": "€80"}`, false},
+		{`{"max": "€80"}`, false},
+		{`{"min": 40, "max": 80, "component": "ram"}`, false},
+		{`{"min": 40}`, true},
+		{`{"min": 40, "max": 0}`, true},
+		{`{"min": -1, "max": 80}`, true},
+		{`{"min": 90, "max": 80}`, true},
+		{`{"min": 40, "max": 80, "maxRealisticPrice": -1}`, true},
+		{`{"min": 40, "max": "€80"}`, true},
+		{`{"min": 40, "max": 80, "component": "toaster"}`, true},
+	}
+
+	for _, tt := range tests {
+		p := &PriceRange{}
+		if err := p.RegisterConfigs([]byte(tt.configs)); (err != nil) != tt.wantErr {
+			t.Errorf("RegisterConfigs(%v) error = %v, wantErr %v", tt.configs, err, tt.wantErr)
+		}
+	}
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		configs    string
+		title      string
+		want       bool
+		wantReason string
+	}{
+		{`{"min": 40, "max": 80}`, "[RAM] Corsair Vengeance 16GB $29.99", false, "$29.99 < $40"},
+		{`{"min": 40, "max": 80}`, "[RAM] Corsair Vengeance 16GB $40", true, "$40 <= $40 <= $80"},
+		{`{"min": 40, "max": 80}`, "[RAM] Corsair Vengeance 32GB $59.99", true, "$40 <= $59.99 <= $80"},
+		{`{"min": 40, "max": 80}`, "[RAM] Corsair Vengeance 32GB $80", true, "$40 <= $80 <= $80"},
+		{`{"min": 40, "max": 80}`, "[RAM] Corsair Vengeance 64GB $129.99", false, "$129.99 > $80"},
+		{`{"min": 40, "max": 80}`, "[RAM] Corsair Vengeance 64GB", false, "no cost in title"},
+		{`{"max": 80}`, "[RAM] Corsair Vengeance 16GB $9.99", true, "$0 <= $9.99 <= $80"},
+		{`{"min": 40, "max": 80, "component": "ram"}`, "[RAM] Corsair Vengeance 32GB $59.99", true, "$40 <= $59.99 <= $80"},
+		{`{"min": 40, "max": 80, "component": "ram"}`, "[SSD] Samsung 970 EVO 1TB $59.99", false, "no RAM in title"},
+		{`{"min": "€40", "max": "€80"}`, "[RAM] Corsair Vengeance 32GB €59,99", true, "€40 <= €59.99 <= €80"},
+		{`{"min": "€40", "max": "€80"}`, "[RAM] Corsair Vengeance 32GB $59.99", false, "no cost in title"},
+		{`{"min": 40, "max": 2500, "maxRealisticPrice": 1500}`, "[CPU] Ryzen 7 7800X3D, build was $2000 total", false, "no cost in title"},
+	}
+
+	for _, tt := range tests {
+		p := &PriceRange{}
+		if err := p.RegisterConfigs([]byte(tt.configs)); err != nil {
+			t.Fatalf("RegisterConfigs(%v) returned an error: %v", tt.configs, err)
+		}
+
+		post := &reddit.Post{Title: tt.title}
+		if got := p.Match(post); got != tt.want {
+			t.Errorf("Match(%q) with %v = %v, want %v", tt.title, tt.configs, got, tt.want)
+		}
+		if got := p.Explain(post); got != tt.wantReason {
+			t.Errorf("Explain(%q) with %v = %q, want %q", tt.title, tt.configs, got, tt.wantReason)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package ramdeal
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/cavcrosby/rsb/rule"
+	"github.com/turnage/graw/reddit"
+)
+
+var (
+	reGenerationInTitle = regexp.MustCompile(`(?i)\bDDR(\d)`)
+	reKitInTitle        = regexp.MustCompile(`(?i)\b(\d+)\s?x\s?(\d+)\s?GB\b`)
+	reCapacityInTitle   = regexp.MustCompile(`(?i)\b(\d+)\s?GB\b`)
+)
+
+// ensure the rule satisfies the rule interfaces at build time
+var (
+	_ rule.Rule         = (*RamDeal)(nil)
+	_ rule.Explainer    = (*RamDeal)(nil)
+	_ rule.FallibleRule = (*RamDeal)(nil)
+)
+
+// A type that represents a rule that matches RAM posts of a generation (e.g.
+// DDR5), with at least a minimum capacity (in GB) and at or below a maximum price.
+// The generation and capacity checks are skipped when not set. Only costs in the
+// currency of the maximum price are compared, costs above the maximum realistic
+// price (e.g. "$2000 total" of a build) being ignored, if set.
+type RamDeal struct {
+	Generation        string     `json:"generation"`
+	MinGB             int        `json:"minGB"`
+	MaxPrice          rule.Price `json:"maxPrice"`
+	MaxRealisticPrice rule.Price `json:"maxRealisticPrice"`
+}
+
+func (r *RamDeal) Name() string {
+	return "ramdeal"
+}
+
+func (r *RamDeal) Description() string {
+	return "matches RAM of a generation, capacity and price"
+}
+
+func (r *RamDeal) RegisterConfigs(configs []byte) error {
+	if err := json.Unmarshal(configs, r); err != nil {
+		return err
+	}
+
+	if err := rule.ValidatePrice("maxPrice", r.MaxPrice); err != nil {
+		return err
+	}
+
+	return rule.ValidateMaxRealisticPrice(r.MaxRealisticPrice, "maxPrice", r.MaxPrice)
+}
+
+// Parse the RAM generation from the title (e.g. "DDR5").
+func generationInTitle(title string) string {
+	if submatches := reGenerationInTitle.FindStringSubmatch(title); submatches != nil {
+		return "DDR" + submatches[1]
+	}
+
+	return ""
+}
+
+// Parse the total RAM capacity (in GB) from the title, kits (e.g. "2x16GB") are
+// totaled.
+func capacityInTitle(title string) int {
+	if submatches := reKitInTitle.FindStringSubmatch(title); submatches != nil {
+		sticks, _ := strconv.Atoi(submatches[1])
+		stickGB, _ := strconv.Atoi(submatches[2])
+		return sticks * stickGB
+	}
+
+	var capacity int
+	for _, submatches := range reCapacityInTitle.FindAllStringSubmatch(title, -1) {
+		if gb, err := strconv.Atoi(submatches[1]); err == nil && gb > capacity {
+			capacity = gb
+		}
+	}
+
+	return capacity
+}
+
+// Determine if the post matches, along with the reason why.
+func (r *RamDeal) evaluate(post *reddit.Post) (bool, string, error) {
+	var reasons []string
+	if r.Generation != "" {
+		generation := generationInTitle(post.Title)
+		if !strings.EqualFold(generation, r.Generation) {
+			return false, fmt.Sprintf("generation %q is not %v", generation, r.Generation), nil
+		}
+		reasons = append(reasons, generation)
+	}
+
+	if r.MinGB > 0 {
+		capacity := capacityInTitle(post.Title)
+		if capacity < r.MinGB {
+			return false, fmt.Sprintf("%vGB < %vGB", capacity, r.MinGB), nil
+		}
+		reasons = append(reasons, fmt.Sprintf("%vGB >= %vGB", capacity, r.MinGB))
+	}
+
+	matched, reason, err := rule.UnderPrice(post.Title, r.MaxPrice, r.MaxRealisticPrice)
+	if !matched {
+		return false, reason, err
+	}
+	reasons = append(reasons, reason)
+
+	return true, strings.Join(reasons, ", "), nil
+}
+
+func (r *RamDeal) Explain(post *reddit.Post) string {
+	_, reason, _ := r.evaluate(post)
+	return reason
+}
+
+func (r *RamDeal) Match(post *reddit.Post) bool {
+	matched, _ := r.TryMatch(post)
+	return matched
+}
+
+func (r *RamDeal) TryMatch(post *reddit.Post) (bool, error) {
+	matched, _, err := r.evaluate(post)
+	return matched, err
+}
+
+func init() {
+	rule.MustRegisterRule(func() rule.Rule {
+		return &RamDeal{}
+	})
+}
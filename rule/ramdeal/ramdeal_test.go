@@ -0,0 +1,128 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package ramdeal
+
+import (
+	"testing"
+
+	"github.com/turnage/graw/reddit"
+)
+
+func TestMatch(t *testing.T) {
+	r := &RamDeal{}
+	if err := r.RegisterConfigs([]byte(`{"generation": "DDR5", "minGB": 32, "maxPrice": 120}`)); err != nil {
+		t.Fatalf("RegisterConfigs returned an error: %v", err)
+	}
+
+	tests := []struct {
+		title      string
+		want       bool
+		wantReason string
+	}{
+		{"[RAM] 32GB DDR5 $110", true, "DDR5, 32GB >= 32GB, $110 <= $120"},
+		{"[RAM] G.Skill Flare X5 2x16GB DDR5-6000 $99.99", true, "DDR5, 32GB >= 32GB, $99.99 <= $120"},
+		{"[RAM] 32GB DDR4 $110", false, `generation "DDR4" is not DDR5`},
+		{"[RAM] 16GB DDR5 $60", false, "16GB < 32GB"},
+		{"[RAM] 32GB DDR5 $130", false, "$130 > $120"},
+		{"[RAM] 32GB DDR5 €110", false, "no cost in title"},
+		{"[RAM] 32GB DDR5", false, "no cost in title"},
+		{"[RAM] 32GB kit $110", false, `generation "" is not DDR5`},
+	}
+
+	for _, tt := range tests {
+		post := &reddit.Post{Title: tt.title}
+		if got := r.Match(post); got != tt.want {
+			t.Errorf("Match(%q) = %v, want %v", tt.title, got, tt.want)
+		}
+		if got := r.Explain(post); got != tt.wantReason {
+			t.Errorf("Explain(%q) = %q, want %q", tt.title, got, tt.wantReason)
+		}
+	}
+}
+
+func TestRegisterConfigs(t *testing.T) {
+	tests := []struct {
+		configs string
+		wantErr bool
+	}{
+		{`{"generation": "DDR5", "minGB": 32, "maxPrice": 120}`, false},
+		{`{"maxPrice": "€120"}`, false},
+		{`{}`, true},
+		{`{"maxPrice": 0}`, true},
+		{`{"maxPrice": -1}`, true},
+		{`{"minGB": "32"}`, true},
+		{`{"maxPrice": "$120"}`, false},
+		{`{"maxPrice": "cheap"}`, true},
+		{`{"maxPrice": 120, "maxRealisticPrice": "€2000"}`, true},
+		{`{"maxPrice": 120, "maxRealisticPrice": -1}`, true},
+	}
+
+	for _, tt := range tests {
+		r := &RamDeal{}
+		if err := r.RegisterConfigs([]byte(tt.configs)); (err != nil) != tt.wantErr {
+			t.Errorf("RegisterConfigs(%v) error = %v, wantErr %v", tt.configs, err, tt.wantErr)
+		}
+	}
+}
+
+func TestCapacityInTitle(t *testing.T) {
+	tests := []struct {
+		title string
+		want  int
+	}{
+		{"32GB DDR5", 32},
+		{"2x16GB DDR5", 32},
+		{"2 x 16 GB DDR5", 32},
+		{"4x8GB DDR4", 32},
+		{"16GB (2x8GB) DDR4", 16},
+		{"64GB kit, 32GB sticks", 64},
+		{"DDR5-6000", 0},
+	}
+
+	for _, tt := range tests {
+		if got := capacityInTitle(tt.title); got != tt.want {
+			t.Errorf("capacityInTitle(%q) = %v, want %v", tt.title, got, tt.want)
+		}
+	}
+}
+
+func TestMatchMaxRealisticPrice(t *testing.T) {
+	tests := []struct {
+		configs string
+		title   string
+		want    bool
+	}{
+		{`{"maxPrice": 2500}`, "[RAM] 32GB DDR5, build was $2000 total", true},
+		{`{"maxPrice": 2500, "maxRealisticPrice": 1500}`, "[RAM] 32GB DDR5, build was $2000 total", false},
+		{`{"maxPrice": 2500, "maxRealisticPrice": 1500}`, "[RAM] 32GB DDR5 $99 (build was $2000 total)", true},
+		{`{"maxPrice": 90, "maxRealisticPrice": 1500}`, "[RAM] 32GB DDR5 $99 (build was $2000 total)", false},
+	}
+
+	for _, tt := range tests {
+		r := &RamDeal{}
+		if err := r.RegisterConfigs([]byte(tt.configs)); err != nil {
+			t.Fatalf("RegisterConfigs(%v) returned an error: %v", tt.configs, err)
+		}
+
+		if got := r.Match(&reddit.Post{Title: tt.title}); got != tt.want {
+			t.Errorf("Match(%q) with %v = %v, want %v", tt.title, tt.configs, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package ramunder100
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/cavcrosby/rsb/rule"
+	"github.com/cavcrosby/rsb/rule/ramunderprice"
+)
+
+var (
+	price rule.Price = rule.Dollars(100)
+)
+
+// ensure the rule satisfies the rule interface at build time
+var _ rule.Rule = (*RamUnder100)(nil)
+
+// A type that represents a rule that matches RAM posts at or below $100. This is
+// the ramunderprice rule with a fixed price, thus it takes no configurations.
+type RamUnder100 struct {
+	ramunderprice.RamUnderPrice
+}
+
+func (r *RamUnder100) Name() string {
+	return "ramunder100"
+}
+
+func (r *RamUnder100) Description() string {
+	return "matches RAM at or below $100"
+}
+
+// The rule takes no configurations, so configurations of the ramunderprice rule
+// (e.g. "price"), which would otherwise be unmarshaled into the embedded rule, are
+// rejected rather than silently ignored or overridden.
+func (r *RamUnder100) RegisterConfigs(configs []byte) error {
+	var configsByKey map[string]json.RawMessage
+	if err := json.Unmarshal(configs, &configsByKey); err != nil {
+		return err
+	} else if len(configsByKey) == 0 {
+		return nil
+	}
+
+	var keys []string
+	for key := range configsByKey {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return fmt.Errorf("the rule takes no configurations (use ramunderprice to set a price), got: %v", strings.Join(keys, ", "))
+}
+
+func init() {
+	rule.MustRegisterRule(func() rule.Rule {
+		return &RamUnder100{
+			RamUnderPrice: ramunderprice.RamUnderPrice{
+				Price: price,
+			},
+		}
+	})
+}
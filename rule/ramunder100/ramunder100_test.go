@@ -0,0 +1,68 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package ramunder100
+
+import (
+	"testing"
+
+	"github.com/cavcrosby/rsb/engine"
+	"github.com/turnage/graw/reddit"
+)
+
+func TestBuildRule(t *testing.T) {
+	tests := []struct {
+		configs map[string]interface{}
+		wantErr bool
+	}{
+		{nil, false},
+		{map[string]interface{}{}, false},
+		{map[string]interface{}{"price": 50.0}, true},
+		{map[string]interface{}{"maxRealisticPrice": 1500.0}, true},
+	}
+
+	for _, tt := range tests {
+		if _, err := engine.BuildRules([]engine.RuleConfig{{ID: "ramunder100", Configs: tt.configs}}); (err != nil) != tt.wantErr {
+			t.Errorf("BuildRules(ramunder100 with configs %v) error = %v, wantErr %v", tt.configs, err, tt.wantErr)
+		}
+	}
+}
+
+func TestMatch(t *testing.T) {
+	rules, err := engine.BuildRules([]engine.RuleConfig{{ID: "ramunder100"}})
+	if err != nil {
+		t.Fatalf("BuildRules returned an error: %v", err)
+	}
+
+	tests := []struct {
+		title string
+		want  bool
+	}{
+		{"[RAM] Corsair Vengeance LPX 16GB DDR4 3200 - $49.99", true},
+		{"[RAM] G.Skill Flare X5 32GB DDR5 $100", true},
+		{"[RAM] G.Skill Trident Z5 64GB DDR5-6000 $189.99", false},
+		{"[GPU] RTX 4070 $99.99", false},
+	}
+
+	for _, tt := range tests {
+		if got := rules[0].Match(&reddit.Post{Title: tt.title}); got != tt.want {
+			t.Errorf("Match(%q) = %v, want %v", tt.title, got, tt.want)
+		}
+	}
+}
@@ -21,64 +21,103 @@ package ramunderprice
 
 import (
 	"encoding/json"
-	"log"
-	"regexp"
-	"strconv"
+	"strings"
 
 	"github.com/cavcrosby/rsb/rule"
 	"github.com/turnage/graw/reddit"
 )
 
 var (
-	defaultPrice  int = 0
-	reRamInTitle      = regexp.MustCompile(`(?i)\bRAM\b`)
-	reCostInTitle     = regexp.MustCompile(`^\$\d+\.*\d*$`)
+	defaultPrice rule.Price = rule.Dollars(0)
 )
 
+// ensure the rule satisfies the rule interfaces at build time
+var (
+	_ rule.Rule         = (*RamUnderPrice)(nil)
+	_ rule.Explainer    = (*RamUnderPrice)(nil)
+	_ rule.FallibleRule = (*RamUnderPrice)(nil)
+)
+
+// A type that represents a rule that matches RAM posts at or below a price. The
+// price can be overridden per subreddit (e.g. to account for currency), only
+// costs in the currency of the price being compared (e.g. "€50" only matching
+// costs in euros). Costs above the maximum realistic price (e.g. "$2000 total" of
+// a build) are ignored, if set.
 type RamUnderPrice struct {
-	Price int `json:"price"`
+	Price             rule.Price            `json:"price"`
+	PerSubreddit      map[string]rule.Price `json:"perSubreddit"`
+	MaxRealisticPrice rule.Price            `json:"maxRealisticPrice"`
 }
 
 func (r *RamUnderPrice) Name() string {
 	return "ramunderprice"
 }
 
+func (r *RamUnderPrice) Description() string {
+	return "matches RAM at or below a price"
+}
+
 func (r *RamUnderPrice) RegisterConfigs(configs []byte) error {
 	if err := json.Unmarshal(configs, r); err != nil {
 		return err
 	}
+	if err := rule.ValidatePrice("price", r.Price); err != nil {
+		return err
+	} else if err := rule.ValidateMaxRealisticPrice(r.MaxRealisticPrice, "price", r.Price); err != nil {
+		return err
+	}
+
+	// subreddit names are case-insensitive
+	perSubreddit := make(map[string]rule.Price)
+	for subreddit, price := range r.PerSubreddit {
+		if err := rule.ValidatePrice("price for "+subreddit, price); err != nil {
+			return err
+		}
+		perSubreddit[strings.ToLower(subreddit)] = price
+	}
+	r.PerSubreddit = perSubreddit
 
 	return nil
 }
 
-func (r *RamUnderPrice) Match(post *reddit.Post) bool {
-	if reRamInTitle.FindStringIndex(post.Title) == nil {
-		return false
+// Get the price to compare against for the post, this being the price of the
+// post's subreddit if overridden.
+func (r *RamUnderPrice) priceFor(post *reddit.Post) rule.Price {
+	if price, ok := r.PerSubreddit[strings.ToLower(post.Subreddit)]; ok {
+		return price
 	}
 
-	var allSubStrings int = -1
-	costs := reCostInTitle.FindAllString(post.Title, allSubStrings)
-	if len(costs) != 1 {
-		// TODO(cavcrosby): return false but there numerous reasons why there might exist
-		// more than one "cost" in the title and we may wish to include those cases (e.g.
-		// price difference from msrp minus discount could be under 100). Obviously 0
-		// costs found should not have the rule match.
-		return false
-	}
+	return r.Price
+}
 
-	if cost, err := strconv.Atoi(regexp.MustCompile(`\d+$`).FindAllString(costs[0], -1)[0]); err != nil {
-		log.Panic(err)
-	} else if cost > r.Price {
-		return false
+// Determine if the post matches, along with the reason why.
+func (r *RamUnderPrice) evaluate(post *reddit.Post) (bool, string, error) {
+	if !rule.ComponentInTitle(rule.RamComponent, post.Title) {
+		return false, "no RAM in title", nil
 	}
 
-	return true
+	return rule.UnderPrice(post.Title, r.priceFor(post), r.MaxRealisticPrice)
 }
 
-func init() {
-	var ramUnderPrice *RamUnderPrice = &RamUnderPrice{
-		Price: defaultPrice,
-	}
+func (r *RamUnderPrice) Explain(post *reddit.Post) string {
+	_, reason, _ := r.evaluate(post)
+	return reason
+}
 
-	rule.RegisterRule(ramUnderPrice)
+func (r *RamUnderPrice) Match(post *reddit.Post) bool {
+	matched, _ := r.TryMatch(post)
+	return matched
+}
+
+func (r *RamUnderPrice) TryMatch(post *reddit.Post) (bool, error) {
+	matched, _, err := r.evaluate(post)
+	return matched, err
+}
+
+func init() {
+	rule.MustRegisterRule(func() rule.Rule {
+		return &RamUnderPrice{
+			Price: defaultPrice,
+		}
+	})
 }
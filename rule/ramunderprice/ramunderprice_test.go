@@ -0,0 +1,164 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package ramunderprice
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cavcrosby/rsb/rule"
+	"github.com/turnage/graw/reddit"
+)
+
+func TestPriceFor(t *testing.T) {
+	r := &RamUnderPrice{Price: defaultPrice}
+	if err := r.RegisterConfigs([]byte(`{
+		"price": 100,
+		"perSubreddit": {"BuildAPCSalesCanada": 140, "buildapcsalesuk": 90}
+	}`)); err != nil {
+		t.Fatalf("RegisterConfigs returned an error: %v", err)
+	}
+
+	tests := []struct {
+		subreddit string
+		want      rule.Price
+	}{
+		{"buildapcsales", rule.Dollars(100)},
+		{"buildapcsalescanada", rule.Dollars(140)},
+		{"BuildapcsalesCanada", rule.Dollars(140)},
+		{"buildapcsalesuk", rule.Dollars(90)},
+	}
+
+	for _, tt := range tests {
+		if got := r.priceFor(&reddit.Post{Subreddit: tt.subreddit}); got != tt.want {
+			t.Errorf("priceFor(%v) = %v, want %v", tt.subreddit, got, tt.want)
+		}
+	}
+}
+
+func TestMatchPerSubreddit(t *testing.T) {
+	r := &RamUnderPrice{Price: defaultPrice}
+	if err := r.RegisterConfigs([]byte(`{
+		"price": 100,
+		"perSubreddit": {"BuildAPCSalesCanada": 140, "buildapcsalesuk": "£90"}
+	}`)); err != nil {
+		t.Fatalf("RegisterConfigs returned an error: %v", err)
+	}
+
+	tests := []struct {
+		subreddit  string
+		title      string
+		want       bool
+		wantReason string
+	}{
+		{"buildapcsales", "[RAM] Corsair Vengeance 32GB DDR5 $120", false, "$120 > $100"},
+		{"buildapcsales", "[RAM] Corsair Vengeance 32GB DDR5 $95", true, "$95 <= $100"},
+		{"buildapcsalescanada", "[RAM] Corsair Vengeance 32GB DDR5 $120", true, "$120 <= $140"},
+		{"BuildapcsalesCanada", "[RAM] Corsair Vengeance 32GB DDR5 $150", false, "$150 > $140"},
+		{"buildapcsalesuk", "[RAM] Corsair Vengeance 32GB DDR5 £85", true, "£85 <= £90"},
+		{"buildapcsalesuk", "[RAM] Corsair Vengeance 32GB DDR5 $85", false, "no cost in title"},
+		{"buildapcsales", "[RAM] Corsair Vengeance 32GB DDR5", false, "no cost in title"},
+		{"buildapcsalescanada", "[GPU] RTX 4070 $120", false, "no RAM in title"},
+	}
+
+	for _, tt := range tests {
+		post := &reddit.Post{Subreddit: tt.subreddit, Title: tt.title}
+		if got := r.Match(post); got != tt.want {
+			t.Errorf("Match(%q in %v) = %v, want %v", tt.title, tt.subreddit, got, tt.want)
+		}
+		if got := r.Explain(post); got != tt.wantReason {
+			t.Errorf("Explain(%q in %v) = %q, want %q", tt.title, tt.subreddit, got, tt.wantReason)
+		}
+	}
+}
+
+func TestTryMatchAbsurdAmount(t *testing.T) {
+	r := &RamUnderPrice{Price: defaultPrice}
+	if err := r.RegisterConfigs([]byte(`{"price": 100}`)); err != nil {
+		t.Fatalf("RegisterConfigs returned an error: %v", err)
+	}
+
+	tests := []struct {
+		title   string
+		want    bool
+		wantErr bool
+	}{
+		{"[RAM] Corsair Vengeance 16GB $" + strings.Repeat("9", 400), false, true},
+		{"[RAM] Corsair Vengeance 16GB $" + strings.Repeat("1", 400) + ".99", false, true},
+		{"[RAM] Corsair Vengeance 16GB $49.99", true, false},
+	}
+
+	for _, tt := range tests {
+		got, err := r.TryMatch(&reddit.Post{Title: tt.title})
+		if (err != nil) != tt.wantErr {
+			t.Errorf("TryMatch(%.40q...) error = %v, wantErr %v", tt.title, err, tt.wantErr)
+		} else if got != tt.want {
+			t.Errorf("TryMatch(%.40q...) = %v, want %v", tt.title, got, tt.want)
+		}
+	}
+}
+
+func TestRegisterConfigs(t *testing.T) {
+	tests := []struct {
+		configs string
+		wantErr bool
+	}{
+		{`{"price": 100}`, false},
+		{`{"price": 100, "perSubreddit": {"buildapcsalescanada": 140}}`, false},
+		{`{"price": -1}`, true},
+		{`{"price": 0}`, true},
+		{`{}`, true},
+		{`{"price": 100, "perSubreddit": {"buildapcsalescanada": 0}}`, true},
+		{`{"price": 100, "perSubreddit": {"buildapcsalescanada": -140}}`, true},
+		{`{"price": 100, "perSubreddit": {"buildapcsalescanada": "cheap"}}`, true},
+	}
+
+	for _, tt := range tests {
+		r := &RamUnderPrice{Price: defaultPrice}
+		if err := r.RegisterConfigs([]byte(tt.configs)); (err != nil) != tt.wantErr {
+			t.Errorf("RegisterConfigs(%v) error = %v, wantErr %v", tt.configs, err, tt.wantErr)
+		}
+	}
+}
+
+func TestRegisteredRuleMatch(t *testing.T) {
+	r, err := rule.RuleInRuleRegistry("ramunderprice")
+	if err != nil {
+		t.Fatalf("RuleInRuleRegistry returned an error: %v", err)
+	}
+	if err := r.RegisterConfigs([]byte(`{"price": 100}`)); err != nil {
+		t.Fatalf("RegisterConfigs returned an error: %v", err)
+	}
+
+	tests := []struct {
+		title string
+		want  bool
+	}{
+		{"[RAM] Corsair Vengeance LPX 16GB DDR4 3200 - $49.99", true},
+		{"[RAM] G.Skill Trident Z5 64GB DDR5-6000 $189.99", false},
+		{"[GPU] RTX 4070 $99.99", false},
+	}
+
+	for _, tt := range tests {
+		if got := r.Match(&reddit.Post{Title: tt.title}); got != tt.want {
+			t.Errorf("Match(%q) = %v, want %v", tt.title, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package regexmatch
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/cavcrosby/rsb/rule"
+	"github.com/turnage/graw/reddit"
+)
+
+// ensure the rule satisfies the rule interface at build time
+var _ rule.Rule = (*RegexMatch)(nil)
+
+// A type that represents a rule that matches posts whose titles match a regular
+// expression (e.g. "(?i)\bRTX\s?30[789]0\b"). An empty pattern is rejected when
+// the configurations are registered. If a timeout is set (e.g. "100ms"), titles
+// taking longer than the timeout to match are treated as not matching. If
+// searchBody is set, the post's body (selftext) is matched along with the title.
+type RegexMatch struct {
+	Pattern    string `json:"pattern"`
+	Timeout    string `json:"timeout"`
+	SearchBody bool   `json:"searchBody"`
+	rePattern  *regexp.Regexp
+	timeout    time.Duration
+}
+
+func (r *RegexMatch) Name() string {
+	return "regexmatch"
+}
+
+func (r *RegexMatch) Description() string {
+	return "matches titles matching a regular expression"
+}
+
+func (r *RegexMatch) RegisterConfigs(configs []byte) error {
+	if err := json.Unmarshal(configs, r); err != nil {
+		return err
+	}
+
+	re, err := rule.CompileUserRegexp(r.Pattern)
+	if err != nil {
+		return fmt.Errorf("invalid pattern: %v", err)
+	}
+	r.rePattern = re
+
+	r.timeout = 0
+	if r.Timeout != "" {
+		timeout, err := time.ParseDuration(r.Timeout)
+		if err != nil {
+			return fmt.Errorf("invalid timeout: %v", err)
+		}
+		r.timeout = timeout
+	}
+
+	return nil
+}
+
+func (r *RegexMatch) Sanity() []string {
+	if r.rePattern == nil {
+		return []string{"no pattern is configured, no posts will match"}
+	}
+
+	return nil
+}
+
+func (r *RegexMatch) Match(post *reddit.Post) bool {
+	if r.rePattern == nil {
+		return false
+	}
+
+	text := rule.PostText(post, r.SearchBody)
+	if r.timeout > 0 {
+		return rule.MatchStringTimeout(r.rePattern, text, r.timeout)
+	}
+
+	return r.rePattern.MatchString(text)
+}
+
+func init() {
+	rule.MustRegisterRule(func() rule.Rule {
+		return &RegexMatch{}
+	})
+}
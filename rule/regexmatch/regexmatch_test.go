@@ -0,0 +1,76 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package regexmatch
+
+import (
+	"testing"
+
+	"github.com/turnage/graw/reddit"
+)
+
+func TestRegisterConfigs(t *testing.T) {
+	tests := []struct {
+		configs string
+		wantErr bool
+	}{
+		{`{"pattern": "(?i)\\bRTX\\s?30[789]0\\b"}`, false},
+		{`{"pattern": "(?i)\\bRTX\\s?30[789]0\\b", "timeout": "100ms"}`, false},
+		{`{"pattern": "(?i)\\bRTX\\s?30[789]0"}`, false},
+		{`{"pattern": "[RTX"}`, true},
+		{`{"pattern": ""}`, true},
+		{`{}`, true},
+		{`{"pattern": "RTX", "timeout": "soon"}`, true},
+	}
+
+	for _, tt := range tests {
+		r := &RegexMatch{}
+		if err := r.RegisterConfigs([]byte(tt.configs)); (err != nil) != tt.wantErr {
+			t.Errorf("RegisterConfigs(%v) error = %v, wantErr %v", tt.configs, err, tt.wantErr)
+		}
+	}
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		configs  string
+		title    string
+		selfText string
+		want     bool
+	}{
+		{`{"pattern": "(?i)\\bRTX\\s?30[789]0\\b"}`, "[GPU] EVGA RTX 3080 FTW3 $499", "", true},
+		{`{"pattern": "(?i)\\bRTX\\s?30[789]0\\b"}`, "[GPU] MSI rtx3070 Ventus $329", "", true},
+		{`{"pattern": "(?i)\\bRTX\\s?30[789]0\\b"}`, "[GPU] ASUS RTX 3060 Dual $249", "", false},
+		{`{"pattern": "(?i)\\bRTX\\s?30[789]0\\b"}`, "[GPU] RTX 30800 $249", "", false},
+		{`{"pattern": "(?i)\\bRTX\\s?30[789]0\\b", "timeout": "1s"}`, "[GPU] EVGA RTX 3080 FTW3 $499", "", true},
+		{`{"pattern": "(?i)\\bRTX\\s?30[789]0\\b"}`, "[GPU] Graphics card $499", "It is an RTX 3080", false},
+		{`{"pattern": "(?i)\\bRTX\\s?30[789]0\\b", "searchBody": true}`, "[GPU] Graphics card $499", "It is an RTX 3080", true},
+	}
+
+	for _, tt := range tests {
+		r := &RegexMatch{}
+		if err := r.RegisterConfigs([]byte(tt.configs)); err != nil {
+			t.Fatalf("RegisterConfigs(%v) returned an error: %v", tt.configs, err)
+		}
+
+		if got := r.Match(&reddit.Post{Title: tt.title, SelfText: tt.selfText}); got != tt.want {
+			t.Errorf("Match(%q) with %v = %v, want %v", tt.title, tt.configs, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package rule
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/cavcrosby/rsb/logging"
+)
+
+var (
+	DefaultRegexpTimeout time.Duration = 100 * time.Millisecond
+	maxUserPatternLen    int           = 1024
+)
+
+// Compile a regular expression supplied by a user (e.g. through a rule's
+// configurations), rejecting empty and overly long patterns.
+//
+// Go's regexp package (RE2) guarantees matching in time linear to the input, so
+// patterns with nested quantifiers cannot backtrack catastrophically. Large
+// patterns can still be expensive to compile and match, hence the length limit.
+func CompileUserRegexp(pattern string) (*regexp.Regexp, error) {
+	if pattern == "" {
+		return nil, errors.New("pattern must not be empty")
+	} else if len(pattern) > maxUserPatternLen {
+		return nil, fmt.Errorf("pattern is longer than %v characters", maxUserPatternLen)
+	}
+
+	return regexp.Compile(pattern)
+}
+
+// Look to see if the regular expression matches the string, giving up and
+// reporting a non-match if matching takes longer than the timeout. A match that
+// times out keeps running in the background until it finishes.
+func MatchStringTimeout(re *regexp.Regexp, s string, timeout time.Duration) bool {
+	done := make(chan bool, 1)
+	go func() {
+		done <- re.MatchString(s)
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case matched := <-done:
+		return matched
+	case <-timer.C:
+		logging.Warnf("regexp %q timed out after %v, treating as no match", re.String(), timeout)
+		return false
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package rule
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCompileUserRegexp(t *testing.T) {
+	tests := []struct {
+		pattern string
+		wantErr bool
+	}{
+		{`(?i)\bRTX\s?30[789]0\b`, false},
+		{`(a+)+$`, false},
+		{``, true},
+		{`(unclosed`, true},
+		{strings.Repeat("a", maxUserPatternLen+1), true},
+	}
+
+	for _, tt := range tests {
+		if _, err := CompileUserRegexp(tt.pattern); (err != nil) != tt.wantErr {
+			t.Errorf("CompileUserRegexp(%.20q) returned error %v, want error %v", tt.pattern, err, tt.wantErr)
+		}
+	}
+}
+
+func TestMatchStringTimeoutCatastrophicPattern(t *testing.T) {
+	// the pattern backtracks catastrophically in backtracking engines, RE2 matches
+	// it in linear time
+	re := regexp.MustCompile(`^(a+)+$`)
+	input := strings.Repeat("a", 100000) + "!"
+
+	done := make(chan bool, 1)
+	go func() {
+		done <- MatchStringTimeout(re, input, DefaultRegexpTimeout)
+	}()
+
+	select {
+	case matched := <-done:
+		if matched {
+			t.Errorf("MatchStringTimeout(%v) = true, want false", re)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("MatchStringTimeout(%v) did not return", re)
+	}
+}
+
+func TestMatchStringTimeout(t *testing.T) {
+	re := regexp.MustCompile(`(?i)\bRTX\s?4070\b`)
+	tests := []struct {
+		s       string
+		timeout time.Duration
+		want    bool
+	}{
+		{"[GPU] RTX 4070 $549.99", time.Second, true},
+		{"[GPU] RX 7800 XT $499.99", time.Second, false},
+		// the match cannot finish within the timeout, so it is reported as a non-match
+		{"[GPU] RTX 4070 $549.99", 0, false},
+	}
+
+	for _, tt := range tests {
+		if tt.timeout == 0 {
+			// a timeout of 0 may still lose the race to a fast match, so the input is
+			// made long enough that matching takes a while
+			tt.s = strings.Repeat("x ", 1000000) + tt.s
+		}
+		if got := MatchStringTimeout(re, tt.s, tt.timeout); got != tt.want {
+			t.Errorf("MatchStringTimeout(%v, %.20q, %v) = %v, want %v", re, tt.s, tt.timeout, got, tt.want)
+		}
+	}
+}
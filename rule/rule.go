@@ -21,41 +21,231 @@ package rule
 
 import (
 	"fmt"
+	"sort"
+	"sync"
+	"time"
 
 	"github.com/turnage/graw/reddit"
 )
 
 var (
-	ruleRegistry RuleRegistry
+	ruleRegistry *RuleRegistry
 )
 
-// A type that defines what a rule is.
+// A type that defines what a rule is. A rule's description says what it matches
+// in a short human readable way (e.g. "matches RAM at or below a price").
 type Rule interface {
 	Name() string
+	Description() string
 	RegisterConfigs(configs []byte) error
 	Match(post *reddit.Post) bool
 }
 
-// A type to map rules keyed by their name.
-type RuleRegistry map[string]Rule
+// A type that defines a rule that can report problems with its configurations
+// that do not prevent it from being used (e.g. a threshold that can never be met).
+type SanityChecker interface {
+	Sanity() []string
+}
 
-// Register a rule for inclusion in the internal rule registry.
-func RegisterRule(r Rule) {
-	ruleRegistry[r.Name()] = r
+// A type that defines a rule that can explain why it matched (or did not match) a
+// post in a human readable way (e.g. "$59 <= $100").
+type Explainer interface {
+	Explain(post *reddit.Post) string
 }
 
-// Look to see if the rule is in the internal rule registry.
-func RuleInRuleRegistry(ruleName string) (Rule, error) {
+// A type that defines a rule whose matching can fail (e.g. a price in the title
+// that cannot be parsed). Such rules are matched using TryMatch instead of Match,
+// with a post whose matching failed being skipped rather than stopping the
+// program.
+type FallibleRule interface {
+	TryMatch(post *reddit.Post) (bool, error)
+}
+
+// Get the rule's explanation of why it matched (or did not match) the post, this
+// being empty if the rule cannot explain itself.
+func ExplainRule(r Rule, post *reddit.Post) string {
+	if explainer, ok := r.(Explainer); ok {
+		return explainer.Explain(post)
+	}
+
+	return ""
+}
+
+// Get the text of the post that rules search, being the post's title and, if the
+// body is searched, the post's selftext (link posts have no selftext).
+func PostText(post *reddit.Post, searchBody bool) string {
+	if searchBody && post.SelfText != "" {
+		return post.Title + "\n" + post.SelfText
+	}
+
+	return post.Title
+}
+
+// A type that carries information about a post that is not part of the post
+// itself, gathered while fetching the post. A zero value means the information
+// is not known (e.g. posts replayed from the post history).
+type PostContext struct {
+	// The position of the post in the listing it was fetched from, starting at 1
+	// for the newest post.
+	Rank int
+	// When the post was first seen and its score back then, taken from the post
+	// history. FirstSeen is zero if this is the first sighting of the post.
+	FirstSeen  time.Time
+	FirstScore int32
+}
+
+// A type that defines a rule that needs a post's context to match the post. Such
+// rules are matched using MatchContext instead of Match.
+type ContextRule interface {
+	MatchContext(post *reddit.Post, pctx PostContext) bool
+}
+
+// A type that defines a rule that only filters posts out (e.g. posts by blocked
+// authors), thus matching nearly every post on its own. Such rules are meant to
+// be combined with other rules (e.g. in an and rule or with the "all" match mode).
+type FilterRule interface {
+	FiltersOnly() bool
+}
+
+// A type that defines a context rule that compares a post with the post when it
+// was first seen (e.g. the upvotes gained since), thus needing the post history.
+// Posts that did not match such a rule are matched again when seen again.
+type HistoryRule interface {
+	ContextRule
+	UsesHistory() bool
+}
+
+// A type that creates a new instance of a rule with the rule's default
+// configurations. Each instance has its own configurations and state, so
+// instances of the same rule do not interfere with each other.
+type RuleFactory func() Rule
+
+// A type to map rule factories keyed by the name of their rule, safe for
+// concurrent use.
+type RuleRegistry struct {
+	mu    sync.RWMutex
+	rules map[string]RuleFactory
+}
+
+// Create a new rule registry.
+func NewRuleRegistry() *RuleRegistry {
+	return &RuleRegistry{rules: make(map[string]RuleFactory)}
+}
+
+// Register a rule's factory in the registry. Rules whose name is already in the
+// registry are not registered.
+func (rr *RuleRegistry) Register(factory RuleFactory) error {
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+
+	ruleName := factory().Name()
+	if _, ok := rr.rules[ruleName]; ok {
+		return fmt.Errorf("the following rule is already registered: %v", ruleName)
+	}
+
+	rr.rules[ruleName] = factory
+	return nil
+}
+
+// Look to see if the rule is in the registry, returning a new instance of the
+// rule if so.
+func (rr *RuleRegistry) Lookup(ruleName string) (Rule, error) {
+	rr.mu.RLock()
+	defer rr.mu.RUnlock()
+
 	// The returned error is necessary otherwise other parts of the code will have to
 	// guess the zero value of 'rule'.
-	if rule, ok := ruleRegistry[ruleName]; ok {
-		return rule, nil
+	if factory, ok := rr.rules[ruleName]; ok {
+		return factory(), nil
 	} else {
-		return rule, fmt.Errorf("the following rule is not known: %v", ruleName)
+		return nil, fmt.Errorf("the following rule is not known: %v", ruleName)
 	}
 }
 
-// Get some rules from the internal rule registry.
+// Remove a rule from the registry, returning whether the rule was in the registry.
+func (rr *RuleRegistry) Deregister(ruleName string) bool {
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+
+	if _, ok := rr.rules[ruleName]; !ok {
+		return false
+	}
+
+	delete(rr.rules, ruleName)
+	return true
+}
+
+// Remove every rule from the registry.
+func (rr *RuleRegistry) Reset() {
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+	rr.rules = make(map[string]RuleFactory)
+}
+
+// Get the names of the rules in the registry, in sorted order.
+func (rr *RuleRegistry) Names() []string {
+	rr.mu.RLock()
+	defer rr.mu.RUnlock()
+
+	var ruleNames []string
+	for ruleName := range rr.rules {
+		ruleNames = append(ruleNames, ruleName)
+	}
+	sort.Strings(ruleNames)
+
+	return ruleNames
+}
+
+// Get a new instance of every rule in the registry, sorted by name.
+func (rr *RuleRegistry) Rules() []Rule {
+	rr.mu.RLock()
+	defer rr.mu.RUnlock()
+
+	var rules []Rule
+	for _, factory := range rr.rules {
+		rules = append(rules, factory())
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		return rules[i].Name() < rules[j].Name()
+	})
+
+	return rules
+}
+
+// Register a rule's factory for inclusion in the internal rule registry. Rules
+// whose name is already in the registry are not registered.
+func RegisterRule(factory RuleFactory) error {
+	return ruleRegistry.Register(factory)
+}
+
+// Register a rule's factory like RegisterRule but panic if the rule cannot be
+// registered, this is intended for registering rules inside init().
+func MustRegisterRule(factory RuleFactory) {
+	if err := RegisterRule(factory); err != nil {
+		panic(err)
+	}
+}
+
+// Remove a rule from the internal rule registry, returning whether the rule was
+// registered. This is primarily intended for tests and dynamic reconfiguration.
+func DeregisterRule(ruleName string) bool {
+	return ruleRegistry.Deregister(ruleName)
+}
+
+// Remove every rule from the internal rule registry, including the rules
+// registered by the rule packages in their init(). This is primarily intended for
+// tests and dynamic reconfiguration.
+func ResetRuleRegistry() {
+	ruleRegistry.Reset()
+}
+
+// Look to see if the rule is in the internal rule registry, returning a new
+// instance of the rule if so.
+func RuleInRuleRegistry(ruleName string) (Rule, error) {
+	return ruleRegistry.Lookup(ruleName)
+}
+
+// Get a new instance of some rules from the internal rule registry.
 func GetRegisteredRules(ruleNames []string) ([]Rule, error) {
 	var rulesFound []Rule
 	for _, ruleName := range ruleNames {
@@ -69,11 +259,17 @@ func GetRegisteredRules(ruleNames []string) ([]Rule, error) {
 	return rulesFound, nil
 }
 
+// Get a new instance of every rule from the internal rule registry, sorted by
+// name.
+func GetAllRegisteredRules() []Rule {
+	return ruleRegistry.Rules()
+}
+
 // Get the internal rule registry.
 func GetRuleRegistry() *RuleRegistry {
-	return &ruleRegistry
+	return ruleRegistry
 }
 
 func init() {
-	ruleRegistry = make(RuleRegistry)
+	ruleRegistry = NewRuleRegistry()
 }
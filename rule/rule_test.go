@@ -0,0 +1,244 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package rule
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/turnage/graw/reddit"
+)
+
+// A type that represents a rule known only by its name.
+type namedRule struct {
+	name string
+}
+
+func (n *namedRule) Name() string                         { return n.name }
+func (n *namedRule) Description() string                  { return "matches nothing" }
+func (n *namedRule) RegisterConfigs(configs []byte) error { return nil }
+func (n *namedRule) Match(post *reddit.Post) bool         { return false }
+
+// Create the factory of a rule with the name.
+func namedRuleFactory(name string) RuleFactory {
+	return func() Rule { return &namedRule{name: name} }
+}
+
+func TestRuleRegistryRegister(t *testing.T) {
+	tests := []struct {
+		name    string
+		wantErr bool
+	}{
+		{"first", false},
+		{"second", false},
+		{"first", true},
+		{"second", true},
+	}
+
+	rr := NewRuleRegistry()
+	for _, tt := range tests {
+		if err := rr.Register(namedRuleFactory(tt.name)); (err != nil) != tt.wantErr {
+			t.Errorf("Register(%v) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+
+	if got, want := rr.Names(), []string{"first", "second"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Names() = %v, want %v", got, want)
+	}
+}
+
+func TestMustRegisterRuleDuplicate(t *testing.T) {
+	const ruleName = "duplicate"
+	defer DeregisterRule(ruleName)
+
+	MustRegisterRule(namedRuleFactory(ruleName))
+	defer func() {
+		if recover() == nil {
+			t.Errorf("MustRegisterRule(%v) did not panic registering the rule again", ruleName)
+		}
+	}()
+	MustRegisterRule(namedRuleFactory(ruleName))
+}
+
+func TestRuleRegistryConcurrentAccess(t *testing.T) {
+	rr := NewRuleRegistry()
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		ruleName := fmt.Sprintf("rule%v", i)
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			if err := rr.Register(namedRuleFactory(ruleName)); err != nil {
+				t.Errorf("Register(%v) returned an error: %v", ruleName, err)
+			}
+			rr.Lookup(ruleName)
+			rr.Deregister(ruleName)
+			rr.Register(namedRuleFactory(ruleName))
+		}()
+		go func() {
+			defer wg.Done()
+			rr.Lookup(ruleName)
+			rr.Names()
+			rr.Rules()
+		}()
+	}
+	wg.Wait()
+
+	if got := len(rr.Names()); got != 10 {
+		t.Errorf("Names() has %v rules, want 10", got)
+	}
+}
+
+// Get the names of the rules.
+func ruleNames(rules []Rule) []string {
+	var names []string
+	for _, r := range rules {
+		names = append(names, r.Name())
+	}
+
+	return names
+}
+
+func TestGetAllRegisteredRules(t *testing.T) {
+	tests := []struct {
+		name       string
+		registered []string
+		want       []string
+	}{
+		{"no rules", nil, nil},
+		{"one rule", []string{"ramunderprice"}, []string{"ramunderprice"}},
+		{"registered in name order", []string{"gpuunderprice", "maxage", "ramdeal"}, []string{"gpuunderprice", "maxage", "ramdeal"}},
+		{"registered out of name order", []string{"sellonly", "authorblock", "maxrank", "cpuunderprice"}, []string{"authorblock", "cpuunderprice", "maxrank", "sellonly"}},
+	}
+
+	for _, tt := range tests {
+		for _, ruleName := range tt.registered {
+			MustRegisterRule(namedRuleFactory(ruleName))
+		}
+
+		if got := ruleNames(GetAllRegisteredRules()); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%v: GetAllRegisteredRules() = %v, want %v", tt.name, got, tt.want)
+		}
+
+		for _, ruleName := range tt.registered {
+			DeregisterRule(ruleName)
+		}
+	}
+}
+
+func TestRuleRegistryRulesAreNewInstances(t *testing.T) {
+	rr := NewRuleRegistry()
+	rr.Register(namedRuleFactory("first"))
+
+	if first, second := rr.Rules()[0], rr.Rules()[0]; first == second {
+		t.Errorf("Rules() returned the same instance of a rule twice")
+	}
+}
+
+func TestDeregisterRule(t *testing.T) {
+	tests := []struct {
+		name       string
+		registered []string
+		deregister string
+		want       bool
+		wantNames  []string
+	}{
+		{"registered rule", []string{"first", "second"}, "first", true, []string{"second"}},
+		{"last rule", []string{"first"}, "first", true, nil},
+		{"rule not registered", []string{"first"}, "second", false, []string{"first"}},
+		{"empty registry", nil, "first", false, nil},
+	}
+
+	for _, tt := range tests {
+		for _, ruleName := range tt.registered {
+			MustRegisterRule(namedRuleFactory(ruleName))
+		}
+
+		if got := DeregisterRule(tt.deregister); got != tt.want {
+			t.Errorf("%v: DeregisterRule(%v) = %v, want %v", tt.name, tt.deregister, got, tt.want)
+		}
+		if _, err := RuleInRuleRegistry(tt.deregister); err == nil {
+			t.Errorf("%v: RuleInRuleRegistry(%v) found the rule after it was deregistered", tt.name, tt.deregister)
+		}
+		if got := GetRuleRegistry().Names(); !reflect.DeepEqual(got, tt.wantNames) {
+			t.Errorf("%v: Names() = %v, want %v", tt.name, got, tt.wantNames)
+		}
+
+		// a deregistered rule can be registered again
+		if tt.want {
+			if err := RegisterRule(namedRuleFactory(tt.deregister)); err != nil {
+				t.Errorf("%v: RegisterRule(%v) after deregistering returned an error: %v", tt.name, tt.deregister, err)
+			}
+			DeregisterRule(tt.deregister)
+		}
+
+		for _, ruleName := range tt.registered {
+			DeregisterRule(ruleName)
+		}
+	}
+}
+
+func TestResetRuleRegistry(t *testing.T) {
+	tests := []struct {
+		name       string
+		registered []string
+	}{
+		{"empty registry", nil},
+		{"several rules", []string{"first", "second", "third"}},
+	}
+
+	for _, tt := range tests {
+		for _, ruleName := range tt.registered {
+			MustRegisterRule(namedRuleFactory(ruleName))
+		}
+
+		ResetRuleRegistry()
+		if got := GetRuleRegistry().Names(); len(got) != 0 {
+			t.Errorf("%v: Names() after ResetRuleRegistry() = %v, want no rules", tt.name, got)
+		}
+		for _, ruleName := range tt.registered {
+			if _, err := RuleInRuleRegistry(ruleName); err == nil {
+				t.Errorf("%v: RuleInRuleRegistry(%v) found the rule after the registry was reset", tt.name, ruleName)
+			}
+		}
+	}
+}
+
+func TestPostText(t *testing.T) {
+	tests := []struct {
+		title      string
+		selfText   string
+		searchBody bool
+		want       string
+	}{
+		{"[GPU] Graphics card $499", "It is an RTX 3080", false, "[GPU] Graphics card $499"},
+		{"[GPU] Graphics card $499", "It is an RTX 3080", true, "[GPU] Graphics card $499\nIt is an RTX 3080"},
+		{"[GPU] Graphics card $499", "", true, "[GPU] Graphics card $499"},
+	}
+
+	for _, tt := range tests {
+		post := &reddit.Post{Title: tt.title, SelfText: tt.selfText}
+		if got := PostText(post, tt.searchBody); got != tt.want {
+			t.Errorf("PostText(%q, %q, %v) = %q, want %q", tt.title, tt.selfText, tt.searchBody, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package scoregain
+
+import (
+	"encoding/json"
+
+	"github.com/cavcrosby/rsb/rule"
+	"github.com/turnage/graw/reddit"
+)
+
+var (
+	defaultMinGain int32 = 0
+)
+
+// ensure the rule satisfies the rule interfaces at build time
+var (
+	_ rule.Rule        = (*ScoreGain)(nil)
+	_ rule.HistoryRule = (*ScoreGain)(nil)
+)
+
+// A type that represents a rule that matches posts that gained a minimum number
+// of upvotes since they were first seen, the score of a post when first seen
+// being taken from the post history. The first sighting of a post does not match.
+//
+// NOTE: posts must be seen more than once for a gain to be seen, e.g. by polling
+// the hot or top listing, as the new listing only has each post once.
+type ScoreGain struct {
+	MinGain int32 `json:"minGain"`
+}
+
+func (s *ScoreGain) Name() string {
+	return "scoregain"
+}
+
+func (s *ScoreGain) Description() string {
+	return "matches posts that gained upvotes since first seen"
+}
+
+func (s *ScoreGain) RegisterConfigs(configs []byte) error {
+	if err := json.Unmarshal(configs, s); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *ScoreGain) UsesHistory() bool {
+	return true
+}
+
+func (s *ScoreGain) Match(post *reddit.Post) bool {
+	return s.MatchContext(post, rule.PostContext{})
+}
+
+func (s *ScoreGain) MatchContext(post *reddit.Post, pctx rule.PostContext) bool {
+	return !pctx.FirstSeen.IsZero() && post.Score-pctx.FirstScore >= s.MinGain
+}
+
+func init() {
+	rule.MustRegisterRule(func() rule.Rule {
+		return &ScoreGain{
+			MinGain: defaultMinGain,
+		}
+	})
+}
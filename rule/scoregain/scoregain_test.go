@@ -0,0 +1,56 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package scoregain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cavcrosby/rsb/rule"
+	"github.com/turnage/graw/reddit"
+)
+
+func TestMatchContext(t *testing.T) {
+	s := &ScoreGain{MinGain: defaultMinGain}
+	if err := s.RegisterConfigs([]byte(`{"minGain": 20}`)); err != nil {
+		t.Fatalf("RegisterConfigs returned an error: %v", err)
+	}
+
+	firstSeen := time.Now().Add(-time.Hour)
+	tests := []struct {
+		name  string
+		score int32
+		pctx  rule.PostContext
+		want  bool
+	}{
+		{"first sighting", 100, rule.PostContext{}, false},
+		{"gained enough", 35, rule.PostContext{FirstSeen: firstSeen, FirstScore: 10}, true},
+		{"gained exactly the minimum", 30, rule.PostContext{FirstSeen: firstSeen, FirstScore: 10}, true},
+		{"gained too little", 29, rule.PostContext{FirstSeen: firstSeen, FirstScore: 10}, false},
+		{"lost upvotes", 5, rule.PostContext{FirstSeen: firstSeen, FirstScore: 10}, false},
+	}
+
+	for _, tt := range tests {
+		post := &reddit.Post{Title: "[RAM] Corsair Vengeance 32GB DDR5 $89.99", Score: tt.score}
+		if got := s.MatchContext(post, tt.pctx); got != tt.want {
+			t.Errorf("%v: MatchContext() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
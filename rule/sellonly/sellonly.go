@@ -0,0 +1,113 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package sellonly
+
+import (
+	"encoding/json"
+	"regexp"
+
+	"github.com/cavcrosby/rsb/rule"
+	"github.com/turnage/graw/reddit"
+)
+
+var (
+	defaultBuyMarkers  = []string{"ISO", "WTB", "LF"}
+	defaultSellMarkers = []string{"FS", "WTS"}
+	rePriceInTitle     = regexp.MustCompile(`\$\d`)
+)
+
+// ensure the rule satisfies the rule interface at build time
+var _ rule.Rule = (*SellOnly)(nil)
+
+// A type that represents a rule that only matches posts selling something. Posts
+// whose title or flair has a buy marker (e.g. "[WTB]") do not match, other posts
+// match when they have a sell marker (e.g. "[WTS]") or a price.
+type SellOnly struct {
+	BuyMarkers    []string `json:"buyMarkers"`
+	SellMarkers   []string `json:"sellMarkers"`
+	reBuyMarkers  *regexp.Regexp
+	reSellMarkers *regexp.Regexp
+}
+
+func (s *SellOnly) Name() string {
+	return "sellonly"
+}
+
+func (s *SellOnly) Description() string {
+	return "matches posts selling something"
+}
+
+// Compile the markers into a case-insensitive regexp matching any of the markers
+// as a whole word.
+func compileMarkers(markers []string) *regexp.Regexp {
+	if len(markers) == 0 {
+		return nil
+	}
+
+	var pattern string
+	for i, marker := range markers {
+		if i > 0 {
+			pattern += "|"
+		}
+		pattern += regexp.QuoteMeta(marker)
+	}
+
+	return regexp.MustCompile(`(?i)\b(?:` + pattern + `)\b`)
+}
+
+func (s *SellOnly) RegisterConfigs(configs []byte) error {
+	if err := json.Unmarshal(configs, s); err != nil {
+		return err
+	}
+
+	s.reBuyMarkers = compileMarkers(s.BuyMarkers)
+	s.reSellMarkers = compileMarkers(s.SellMarkers)
+	return nil
+}
+
+func (s *SellOnly) Match(post *reddit.Post) bool {
+	texts := []string{post.Title, post.LinkFlairText}
+	for _, text := range texts {
+		if s.reBuyMarkers != nil && s.reBuyMarkers.MatchString(text) {
+			return false
+		}
+	}
+
+	for _, text := range texts {
+		if s.reSellMarkers != nil && s.reSellMarkers.MatchString(text) {
+			return true
+		}
+	}
+
+	return rePriceInTitle.MatchString(post.Title)
+}
+
+func init() {
+	rule.MustRegisterRule(func() rule.Rule {
+		return &SellOnly{
+			// the defaults are copied as configurations unmarshaled into the
+			// markers reuse their backing arrays
+			BuyMarkers:    append([]string(nil), defaultBuyMarkers...),
+			SellMarkers:   append([]string(nil), defaultSellMarkers...),
+			reBuyMarkers:  compileMarkers(defaultBuyMarkers),
+			reSellMarkers: compileMarkers(defaultSellMarkers),
+		}
+	})
+}
@@ -0,0 +1,72 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package sellonly
+
+import (
+	"testing"
+
+	"github.com/turnage/graw/reddit"
+)
+
+// Create the rule the way it is registered, with the default markers.
+func newSellOnly() *SellOnly {
+	return &SellOnly{
+		BuyMarkers:    append([]string(nil), defaultBuyMarkers...),
+		SellMarkers:   append([]string(nil), defaultSellMarkers...),
+		reBuyMarkers:  compileMarkers(defaultBuyMarkers),
+		reSellMarkers: compileMarkers(defaultSellMarkers),
+	}
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		configs string
+		title   string
+		flair   string
+		want    bool
+	}{
+		{`{}`, "[WTS] RAM $80", "", true},
+		{`{}`, "[ISO] cheap RAM", "", false},
+		{`{}`, "[WTB] RAM $80", "", false},
+		{`{}`, "[LF] 32GB DDR5 kit, paying $80", "", false},
+		{`{}`, "[FS] Corsair Vengeance 16GB", "", true},
+		{`{}`, "Corsair Vengeance 16GB $49.99", "", true},
+		{`{}`, "Corsair Vengeance 16GB", "", false},
+		{`{}`, "Corsair Vengeance 16GB", "Selling", false},
+		{`{}`, "Corsair Vengeance 16GB $49.99", "WTB", false},
+		{`{}`, "Corsair Vengeance 16GB", "FS", true},
+		{`{}`, "[USA-CA] [H] isolated fans [W] PayPal", "", false},
+		{`{"sellMarkers": ["H"]}`, "[USA-CA] [H] Noctua fans [W] PayPal", "", true},
+		{`{"buyMarkers": ["W"]}`, "[USA-CA] [H] $80 [W] RAM", "", false},
+		{`{"buyMarkers": []}`, "[WTB] RAM $80", "", true},
+	}
+
+	for _, tt := range tests {
+		s := newSellOnly()
+		if err := s.RegisterConfigs([]byte(tt.configs)); err != nil {
+			t.Fatalf("RegisterConfigs(%v) returned an error: %v", tt.configs, err)
+		}
+
+		post := &reddit.Post{Title: tt.title, LinkFlairText: tt.flair}
+		if got := s.Match(post); got != tt.want {
+			t.Errorf("Match(%q, flair %q) with %v = %v, want %v", tt.title, tt.flair, tt.configs, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package storageperprice
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/cavcrosby/rsb/rule"
+	"github.com/turnage/graw/reddit"
+)
+
+var (
+	defaultMaxPricePerTB rule.Price = rule.Dollars(0)
+	gbPerTB              float64    = 1000
+	reCapacityInTitle               = regexp.MustCompile(`(?i)\b(\d+(?:\.\d+)?)\s?(TB|GB)\b`)
+)
+
+// ensure the rule satisfies the rule interfaces at build time
+var (
+	_ rule.Rule         = (*StoragePerPrice)(nil)
+	_ rule.Explainer    = (*StoragePerPrice)(nil)
+	_ rule.FallibleRule = (*StoragePerPrice)(nil)
+)
+
+// A type that represents a rule that matches storage posts (e.g. "[SSD] 2TB NVMe
+// $79.99") whose price per terabyte is at or below a maximum. Capacities in GB are
+// normalized to TB (1TB being 1000GB, as drives are sold). Costs above the maximum
+// realistic price (e.g. "$2000 total" of a build) are ignored, if set.
+type StoragePerPrice struct {
+	MaxPricePerTB     rule.Price `json:"maxPricePerTB"`
+	MaxRealisticPrice rule.Price `json:"maxRealisticPrice"`
+}
+
+func (s *StoragePerPrice) Name() string {
+	return "storageperprice"
+}
+
+func (s *StoragePerPrice) Description() string {
+	return "matches storage at or below a price per TB"
+}
+
+func (s *StoragePerPrice) RegisterConfigs(configs []byte) error {
+	if err := json.Unmarshal(configs, s); err != nil {
+		return err
+	}
+
+	if err := rule.ValidatePrice("maxPricePerTB", s.MaxPricePerTB); err != nil {
+		return err
+	}
+
+	return rule.ValidateMaxRealisticPrice(s.MaxRealisticPrice, "maxPricePerTB", s.MaxPricePerTB)
+}
+
+// Parse the capacity (in TB) from the title, the largest capacity being taken if
+// the title has more than one (e.g. "500GB cache, 4TB").
+func capacityInTitle(title string) (float64, bool) {
+	var capacity float64
+	for _, submatches := range reCapacityInTitle.FindAllStringSubmatch(title, -1) {
+		size, err := strconv.ParseFloat(submatches[1], 64)
+		if err != nil {
+			continue
+		}
+		if strings.EqualFold(submatches[2], "GB") {
+			size /= gbPerTB
+		}
+		if size > capacity {
+			capacity = size
+		}
+	}
+
+	return capacity, capacity > 0
+}
+
+// Determine if the post matches, along with the reason why.
+func (s *StoragePerPrice) evaluate(post *reddit.Post) (bool, string, error) {
+	capacity, ok := capacityInTitle(post.Title)
+	if !ok {
+		return false, "no capacity in title", nil
+	}
+
+	costs, err := rule.CostsInTitle(post.Title, s.MaxRealisticPrice)
+	if err != nil {
+		return false, fmt.Sprintf("costs could not be parsed: %v", err), err
+	}
+
+	cost, ok := rule.SalePrice(costs, s.MaxPricePerTB.Currency)
+	if !ok {
+		return false, "no cost in title", nil
+	}
+
+	perTB := rule.NewPrice(cost.Float()/capacity, cost.Currency)
+	if cmp, err := perTB.Cmp(s.MaxPricePerTB); err != nil {
+		return false, fmt.Sprintf("costs could not be compared: %v", err), err
+	} else if cmp > 0 {
+		return false, fmt.Sprintf("%v/TB > %v/TB", perTB, s.MaxPricePerTB), nil
+	}
+
+	return true, fmt.Sprintf("%v/TB <= %v/TB", perTB, s.MaxPricePerTB), nil
+}
+
+func (s *StoragePerPrice) Explain(post *reddit.Post) string {
+	_, reason, _ := s.evaluate(post)
+	return reason
+}
+
+func (s *StoragePerPrice) Match(post *reddit.Post) bool {
+	matched, _ := s.TryMatch(post)
+	return matched
+}
+
+func (s *StoragePerPrice) TryMatch(post *reddit.Post) (bool, error) {
+	matched, _, err := s.evaluate(post)
+	return matched, err
+}
+
+func init() {
+	rule.MustRegisterRule(func() rule.Rule {
+		return &StoragePerPrice{
+			MaxPricePerTB: defaultMaxPricePerTB,
+		}
+	})
+}
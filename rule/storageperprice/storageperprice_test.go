@@ -0,0 +1,101 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package storageperprice
+
+import (
+	"testing"
+
+	"github.com/turnage/graw/reddit"
+)
+
+func TestMatch(t *testing.T) {
+	s := &StoragePerPrice{MaxPricePerTB: defaultMaxPricePerTB}
+	if err := s.RegisterConfigs([]byte(`{"maxPricePerTB": 50}`)); err != nil {
+		t.Fatalf("RegisterConfigs returned an error: %v", err)
+	}
+
+	tests := []struct {
+		title      string
+		want       bool
+		wantReason string
+	}{
+		{"[SSD] 2TB NVMe $79.99", true, "$39.99/TB <= $50/TB"},
+		{"[SSD] WD Black SN850X 1 TB $59.99", false, "$59.99/TB > $50/TB"},
+		{"[SSD] Samsung 990 EVO 500GB $24.99", true, "$49.98/TB <= $50/TB"},
+		{"[HDD] Seagate 8TB, 256MB cache $129.99", true, "$16.25/TB <= $50/TB"},
+		{"[SSD] Crucial P3 Plus NVMe $79.99", false, "no capacity in title"},
+		{"[SSD] Crucial P3 Plus 2TB", false, "no cost in title"},
+	}
+
+	for _, tt := range tests {
+		post := &reddit.Post{Title: tt.title}
+		if got := s.Match(post); got != tt.want {
+			t.Errorf("Match(%q) = %v, want %v", tt.title, got, tt.want)
+		}
+		if got := s.Explain(post); got != tt.wantReason {
+			t.Errorf("Explain(%q) = %q, want %q", tt.title, got, tt.wantReason)
+		}
+	}
+}
+
+func TestRegisterConfigs(t *testing.T) {
+	tests := []struct {
+		configs string
+		wantErr bool
+	}{
+		{`{"maxPricePerTB": 50}`, false},
+		{`{"maxPricePerTB": "€50"}`, false},
+		{`{}`, true},
+		{`{"maxPricePerTB": 0}`, true},
+		{`{"maxPricePerTB": -50}`, true},
+		{`{"maxPricePerTB": "cheap"}`, true},
+		{`{"maxPricePerTB": 50, "maxRealisticPrice": -1}`, true},
+		{`{"maxPricePerTB": 50, "maxRealisticPrice": 1500}`, false},
+	}
+
+	for _, tt := range tests {
+		s := &StoragePerPrice{MaxPricePerTB: defaultMaxPricePerTB}
+		if err := s.RegisterConfigs([]byte(tt.configs)); (err != nil) != tt.wantErr {
+			t.Errorf("RegisterConfigs(%v) error = %v, wantErr %v", tt.configs, err, tt.wantErr)
+		}
+	}
+}
+
+func TestCapacityInTitle(t *testing.T) {
+	tests := []struct {
+		title  string
+		want   float64
+		wantOk bool
+	}{
+		{"2TB NVMe", 2, true},
+		{"1 TB NVMe", 1, true},
+		{"500GB NVMe", 0.5, true},
+		{"1.5TB HDD", 1.5, true},
+		{"500GB cache, 4TB", 4, true},
+		{"NVMe SSD", 0, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := capacityInTitle(tt.title)
+		if got != tt.want || ok != tt.wantOk {
+			t.Errorf("capacityInTitle(%q) = %v, %v, want %v, %v", tt.title, got, ok, tt.want, tt.wantOk)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package subredditmatch
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/cavcrosby/rsb/rule"
+	"github.com/turnage/graw/reddit"
+)
+
+// ensure the rule satisfies the rule interface at build time
+var _ rule.Rule = (*SubredditMatch)(nil)
+
+// A type that represents a rule that only matches posts from the subreddits
+// (e.g. "buildapcsales"), useful when watching multiple subreddits but some rules
+// should only apply to a few of them. Subreddit names are case-insensitive and
+// may be prefixed with "r/". No posts match if no subreddits are configured.
+type SubredditMatch struct {
+	Subreddits []string `json:"subreddits"`
+	subreddits map[string]bool
+}
+
+func (s *SubredditMatch) Name() string {
+	return "subredditmatch"
+}
+
+func (s *SubredditMatch) Description() string {
+	return "matches posts from the subreddits"
+}
+
+// Normalize a subreddit name for comparing against other subreddit names.
+func normalizeSubreddit(subreddit string) string {
+	subreddit = strings.ToLower(strings.TrimSpace(subreddit))
+	subreddit = strings.TrimPrefix(subreddit, "/")
+	return strings.TrimPrefix(subreddit, "r/")
+}
+
+func (s *SubredditMatch) RegisterConfigs(configs []byte) error {
+	if err := json.Unmarshal(configs, s); err != nil {
+		return err
+	}
+
+	s.subreddits = make(map[string]bool)
+	for _, subreddit := range s.Subreddits {
+		s.subreddits[normalizeSubreddit(subreddit)] = true
+	}
+
+	return nil
+}
+
+func (s *SubredditMatch) Sanity() []string {
+	if len(s.subreddits) == 0 {
+		return []string{"no subreddits are configured, no posts will match"}
+	}
+
+	return nil
+}
+
+func (s *SubredditMatch) Match(post *reddit.Post) bool {
+	return s.subreddits[normalizeSubreddit(post.Subreddit)]
+}
+
+func init() {
+	rule.MustRegisterRule(func() rule.Rule {
+		return &SubredditMatch{}
+	})
+}
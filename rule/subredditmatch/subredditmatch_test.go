@@ -0,0 +1,75 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package subredditmatch
+
+import (
+	"testing"
+
+	"github.com/turnage/graw/reddit"
+)
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		configs   string
+		subreddit string
+		want      bool
+	}{
+		{`{"subreddits": ["buildapcsales"]}`, "buildapcsales", true},
+		{`{"subreddits": ["buildapcsales"]}`, "BuildAPCSales", true},
+		{`{"subreddits": ["r/BuildAPCSales", "/r/hardwareswap"]}`, "hardwareswap", true},
+		{`{"subreddits": ["buildapcsales"]}`, "hardwareswap", false},
+		{`{"subreddits": ["buildapcsales"]}`, "", false},
+		{`{"subreddits": []}`, "buildapcsales", false},
+		{`{}`, "buildapcsales", false},
+	}
+
+	for _, tt := range tests {
+		s := &SubredditMatch{}
+		if err := s.RegisterConfigs([]byte(tt.configs)); err != nil {
+			t.Fatalf("RegisterConfigs(%v) returned an error: %v", tt.configs, err)
+		}
+
+		if got := s.Match(&reddit.Post{Subreddit: tt.subreddit}); got != tt.want {
+			t.Errorf("Match(post from %q) with %v = %v, want %v", tt.subreddit, tt.configs, got, tt.want)
+		}
+	}
+}
+
+func TestSanity(t *testing.T) {
+	tests := []struct {
+		configs      string
+		wantWarnings int
+	}{
+		{`{"subreddits": ["buildapcsales"]}`, 0},
+		{`{"subreddits": []}`, 1},
+		{`{}`, 1},
+	}
+
+	for _, tt := range tests {
+		s := &SubredditMatch{}
+		if err := s.RegisterConfigs([]byte(tt.configs)); err != nil {
+			t.Fatalf("RegisterConfigs(%v) returned an error: %v", tt.configs, err)
+		}
+
+		if got := s.Sanity(); len(got) != tt.wantWarnings {
+			t.Errorf("Sanity() with %v = %q, want %v warnings", tt.configs, got, tt.wantWarnings)
+		}
+	}
+}
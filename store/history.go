@@ -0,0 +1,132 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package store
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/turnage/graw/reddit"
+)
+
+// A type that represents a post recorded in the post history.
+type HistoryRecord struct {
+	SeenAt time.Time    `json:"seenAt"`
+	Post   *reddit.Post `json:"post"`
+}
+
+// A type that represents the history of posts seen, stored as a file of JSON
+// records (one per line) so posts can be replayed later.
+type PostHistory struct {
+	mu   sync.Mutex
+	path string
+}
+
+// Create a post history backed by the file at the path.
+func NewPostHistory(path string) *PostHistory {
+	return &PostHistory{path: path}
+}
+
+// Append the posts to the post history, recording when they were seen.
+func (h *PostHistory) Record(posts []*reddit.Post, seenAt time.Time) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	historyFd, err := os.OpenFile(h.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer historyFd.Close()
+
+	encoder := json.NewEncoder(historyFd)
+	for _, post := range posts {
+		if err := encoder.Encode(HistoryRecord{SeenAt: seenAt, Post: post}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Get the records seen at or after the time passed in, in the order they were
+// recorded.
+func (h *PostHistory) Since(since time.Time) ([]HistoryRecord, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var records []HistoryRecord
+	historyFd, err := os.Open(h.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return records, nil
+	} else if err != nil {
+		return records, err
+	}
+	defer historyFd.Close()
+
+	scanner := bufio.NewScanner(historyFd)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		var record HistoryRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return records, fmt.Errorf("failed to parse post history %v at line %v: %v", h.path, line, err)
+		}
+
+		if !record.SeenAt.Before(since) {
+			records = append(records, record)
+		}
+	}
+
+	return records, scanner.Err()
+}
+
+// Get the first record of each of the posts in the post history, keyed by post id.
+// Posts not in the post history have no record.
+func (h *PostHistory) FirstSeen(posts []*reddit.Post) (map[string]HistoryRecord, error) {
+	firstSeen := make(map[string]HistoryRecord)
+	if len(posts) == 0 {
+		return firstSeen, nil
+	}
+
+	ids := make(map[string]bool)
+	for _, post := range posts {
+		ids[post.ID] = true
+	}
+
+	records, err := h.Since(time.Time{})
+	if err != nil {
+		return firstSeen, err
+	}
+
+	for _, record := range records {
+		if record.Post == nil || !ids[record.Post.ID] {
+			continue
+		} else if _, ok := firstSeen[record.Post.ID]; !ok {
+			firstSeen[record.Post.ID] = record
+		}
+	}
+
+	return firstSeen, nil
+}
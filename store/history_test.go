@@ -0,0 +1,85 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package store
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/turnage/graw/reddit"
+)
+
+func TestPostHistory(t *testing.T) {
+	h := NewPostHistory(filepath.Join(t.TempDir(), "rsb.history.jsonl"))
+	start := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	if records, err := h.Since(time.Time{}); err != nil || len(records) != 0 {
+		t.Fatalf("Since of an empty history = %v, %v, want no records", records, err)
+	}
+
+	for i, posts := range [][]*reddit.Post{
+		{{ID: "a", Score: 1}, {ID: "b", Score: 2}},
+		{{ID: "a", Score: 10}, {ID: "c", Score: 3}},
+	} {
+		if err := h.Record(posts, start.Add(time.Duration(i)*time.Hour)); err != nil {
+			t.Fatalf("Record returned an error: %v", err)
+		}
+	}
+
+	tests := []struct {
+		since time.Time
+		want  []string
+	}{
+		{time.Time{}, []string{"a", "b", "a", "c"}},
+		{start.Add(time.Hour), []string{"a", "c"}},
+		{start.Add(2 * time.Hour), nil},
+	}
+
+	for _, tt := range tests {
+		records, err := h.Since(tt.since)
+		if err != nil {
+			t.Fatalf("Since(%v) returned an error: %v", tt.since, err)
+		}
+
+		var got []string
+		for _, record := range records {
+			got = append(got, record.Post.ID)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Since(%v) = %v, want %v", tt.since, got, tt.want)
+		}
+	}
+
+	firstSeen, err := h.FirstSeen([]*reddit.Post{{ID: "a"}, {ID: "c"}, {ID: "d"}})
+	if err != nil {
+		t.Fatalf("FirstSeen returned an error: %v", err)
+	}
+	if len(firstSeen) != 2 {
+		t.Errorf("FirstSeen returned %v records, want 2", len(firstSeen))
+	}
+	if record := firstSeen["a"]; !record.SeenAt.Equal(start) || record.Post.Score != 1 {
+		t.Errorf("FirstSeen of a = %v with score %v, want %v with score 1", record.SeenAt, record.Post.Score, start)
+	}
+	if record := firstSeen["c"]; !record.SeenAt.Equal(start.Add(time.Hour)) {
+		t.Errorf("FirstSeen of c = %v, want %v", record.SeenAt, start.Add(time.Hour))
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package store
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cavcrosby/rsb/notify"
+)
+
+// A type that represents a match recorded in a match store.
+type MatchStoreRecord struct {
+	MatchedAt time.Time `json:"matchedAt"`
+	notify.MatchRecord
+}
+
+// A type that represents a store of the most recent matches, which only lives in
+// memory. Once the store holds its limit of matches, the oldest matches are
+// dropped to make room for new ones.
+type MatchStore struct {
+	mu      sync.Mutex
+	limit   int
+	records []MatchStoreRecord
+}
+
+// Create a match store holding at most the limit of matches.
+func NewMatchStore(limit int) *MatchStore {
+	return &MatchStore{limit: limit}
+}
+
+// Add the matches to the match store, recording when they matched.
+func (m *MatchStore) Record(records []notify.MatchRecord, matchedAt time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, record := range records {
+		m.records = append(m.records, MatchStoreRecord{MatchedAt: matchedAt, MatchRecord: record})
+	}
+	if len(m.records) > m.limit {
+		m.records = append([]MatchStoreRecord(nil), m.records[len(m.records)-m.limit:]...)
+	}
+}
+
+// Get the matches in the match store, newest first. Only the matches of the rule
+// and of the subreddit (matched without regard to case) are returned, if set.
+func (m *MatchStore) Recent(ruleName, subreddit string) []MatchStoreRecord {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	records := []MatchStoreRecord{}
+	for i := len(m.records) - 1; i >= 0; i-- {
+		record := m.records[i]
+		if subreddit != "" && !strings.EqualFold(record.Subreddit, subreddit) {
+			continue
+		} else if ruleName != "" && !matchedRule(record.Rules, ruleName) {
+			continue
+		}
+		records = append(records, record)
+	}
+
+	return records
+}
+
+// Determine if the rule is one of the rules a match matched.
+func matchedRule(ruleNames []string, ruleName string) bool {
+	for _, name := range ruleNames {
+		if name == ruleName {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,62 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package store
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/cavcrosby/rsb/notify"
+)
+
+func TestMatchStoreLimit(t *testing.T) {
+	tests := []struct {
+		name       string
+		limit      int
+		recorded   [][]string
+		wantTitles []string
+	}{
+		{"under the limit", 5, [][]string{{"first", "second"}, {"third"}}, []string{"third", "second", "first"}},
+		{"at the limit", 3, [][]string{{"first", "second"}, {"third"}}, []string{"third", "second", "first"}},
+		{"over the limit", 2, [][]string{{"first", "second"}, {"third"}}, []string{"third", "second"}},
+		{"over the limit at once", 2, [][]string{{"first", "second", "third", "fourth"}}, []string{"fourth", "third"}},
+		{"nothing recorded", 2, nil, []string{}},
+	}
+
+	for _, tt := range tests {
+		m := NewMatchStore(tt.limit)
+		for _, titles := range tt.recorded {
+			var records []notify.MatchRecord
+			for _, title := range titles {
+				records = append(records, notify.MatchRecord{Title: title})
+			}
+			m.Record(records, time.Now())
+		}
+
+		gotTitles := []string{}
+		for _, record := range m.Recent("", "") {
+			gotTitles = append(gotTitles, record.Title)
+		}
+		if !reflect.DeepEqual(gotTitles, tt.wantTitles) {
+			t.Errorf("%v: Recent() = %v, want %v", tt.name, gotTitles, tt.wantTitles)
+		}
+	}
+}
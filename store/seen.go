@@ -0,0 +1,179 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	MemoryBackend = "memory"
+	FileBackend   = "file"
+)
+
+// A type that defines what a store of seen posts is. Posts are identified by
+// their ID.
+type SeenStore interface {
+	Has(id string) bool
+	Mark(id string)
+	Prune(before time.Time)
+	Reset()
+	Close() error
+}
+
+// A type that defines a seen store that buffers changes until flushed.
+type Flusher interface {
+	Flush() error
+}
+
+// A type that represents a seen store that only lives in memory.
+type MemorySeenStore struct {
+	mu   sync.Mutex
+	seen map[string]time.Time
+	now  func() time.Time
+}
+
+// Create an empty in-memory seen store.
+func NewMemorySeenStore() *MemorySeenStore {
+	return &MemorySeenStore{
+		seen: make(map[string]time.Time),
+		now:  time.Now,
+	}
+}
+
+func (m *MemorySeenStore) Has(id string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, ok := m.seen[id]
+	return ok
+}
+
+func (m *MemorySeenStore) Mark(id string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.seen[id] = m.now()
+}
+
+// Forget the posts that were marked before the time passed in.
+func (m *MemorySeenStore) Prune(before time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for id, markedAt := range m.seen {
+		if markedAt.Before(before) {
+			delete(m.seen, id)
+		}
+	}
+}
+
+// Forget every post seen.
+func (m *MemorySeenStore) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.seen = make(map[string]time.Time)
+}
+
+func (m *MemorySeenStore) Close() error {
+	return nil
+}
+
+// A type that represents a seen store persisted to a JSON file. The file is
+// written when the store is flushed or closed.
+type FileSeenStore struct {
+	*MemorySeenStore
+	path string
+}
+
+// Create a seen store backed by the JSON file at the path, loading the posts
+// already in the file (if it exists).
+func NewFileSeenStore(path string) (*FileSeenStore, error) {
+	f := &FileSeenStore{
+		MemorySeenStore: NewMemorySeenStore(),
+		path:            path,
+	}
+
+	seenBytes, err := ioutil.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return f, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(seenBytes, &f.seen); err != nil {
+		return nil, fmt.Errorf("failed to parse seen store %v: %v", path, err)
+	}
+
+	return f, nil
+}
+
+// Write the seen posts to the file. The posts are written to a temporary file
+// that then replaces the file, so the file is never left partially written.
+func (f *FileSeenStore) Flush() error {
+	f.mu.Lock()
+	seenBytes, err := json.Marshal(f.seen)
+	f.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	tmpFile, err := ioutil.TempFile(filepath.Dir(f.path), filepath.Base(f.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpFile.Name())
+
+	if _, err := tmpFile.Write(seenBytes); err != nil {
+		tmpFile.Close()
+		return err
+	}
+	if err := tmpFile.Chmod(0644); err != nil {
+		tmpFile.Close()
+		return err
+	}
+	if err := tmpFile.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpFile.Name(), f.path)
+}
+
+func (f *FileSeenStore) Close() error {
+	return f.Flush()
+}
+
+// Open a seen store using the backend passed in, the file backend being the
+// default. The path is only used by backends that persist seen posts.
+func OpenSeenStore(backend, path string) (SeenStore, error) {
+	switch backend {
+	case MemoryBackend:
+		return NewMemorySeenStore(), nil
+	case "", FileBackend:
+		return NewFileSeenStore(path)
+	default:
+		return nil, fmt.Errorf("the following seen store backend is not known: %v", backend)
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package store
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// Test the contract every seen store must satisfy.
+func testSeenStore(t *testing.T, name string, s SeenStore) {
+	t.Helper()
+	if s.Has("a") {
+		t.Errorf("%v: Has(a) = true before marking", name)
+	}
+
+	s.Mark("a")
+	if !s.Has("a") {
+		t.Errorf("%v: Has(a) = false after marking", name)
+	}
+
+	s.Mark("b")
+
+	s.Prune(time.Now().Add(-time.Hour))
+	if !s.Has("a") || !s.Has("b") {
+		t.Errorf("%v: Prune forgot posts marked after the time", name)
+	}
+
+	s.Prune(time.Now().Add(time.Hour))
+	if s.Has("a") || s.Has("b") {
+		t.Errorf("%v: Prune kept posts marked before the time", name)
+	}
+
+	s.Mark("c")
+
+	s.Reset()
+	if s.Has("c") {
+		t.Errorf("%v: Has(c) = true after resetting", name)
+	}
+
+	if err := s.Close(); err != nil {
+		t.Errorf("%v: Close returned an error: %v", name, err)
+	}
+}
+
+func TestSeenStores(t *testing.T) {
+	for _, backend := range []string{MemoryBackend, FileBackend} {
+		s, err := OpenSeenStore(backend, filepath.Join(t.TempDir(), "rsb.seen.json"))
+		if err != nil {
+			t.Fatalf("OpenSeenStore(%q) returned an error: %v", backend, err)
+		}
+
+		testSeenStore(t, backend, s)
+	}
+}
+
+func TestFileSeenStorePersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rsb.seen.json")
+	s, err := NewFileSeenStore(path)
+	if err != nil {
+		t.Fatalf("NewFileSeenStore returned an error: %v", err)
+	}
+
+	s.Mark("a")
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close returned an error: %v", err)
+	}
+
+	reopened, err := NewFileSeenStore(path)
+	if err != nil {
+		t.Fatalf("NewFileSeenStore returned an error: %v", err)
+	}
+	if !reopened.Has("a") || reopened.Has("b") {
+		t.Errorf("reopened store Has(a), Has(b) = %v, %v, want true, false", reopened.Has("a"), reopened.Has("b"))
+	}
+}
+
+func TestOpenSeenStoreUnknownBackend(t *testing.T) {
+	if _, err := OpenSeenStore("redis", ""); err == nil {
+		t.Errorf("OpenSeenStore(%q) returned no error", "redis")
+	}
+}
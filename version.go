@@ -0,0 +1,36 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import "fmt"
+
+// The build information of the program, set at build time by the linker (e.g.
+// go build -ldflags "-X main.version=v1.2.0").
+var (
+	version   string = "dev"
+	commit    string = "unknown"
+	buildDate string = "unknown"
+)
+
+// Get the version of the program along with the commit and date it was built
+// from (e.g. "rsb v1.2.0 (commit 1a2b3c4, built 2021-06-01T00:00:00Z)").
+func versionString() string {
+	return fmt.Sprintf("%v %v (commit %v, built %v)", progName, version, commit, buildDate)
+}
@@ -0,0 +1,137 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/cavcrosby/rsb/logging"
+	"github.com/cavcrosby/rsb/store"
+	"github.com/turnage/graw"
+	"github.com/turnage/graw/reddit"
+)
+
+var (
+	watchHealthInterval = time.Minute
+)
+
+// A type that represents a post handler for graw, passing on each new post as
+// soon as it arrives vs gathering posts into a queue. Posts are marked as seen
+// once passed on (or left out by the prefilters), after which onSeen is called
+// (e.g. to save the posts seen).
+type postStream struct {
+	prefilters []prefilter
+	seen       store.SeenStore
+	onPost     func(p *reddit.Post)
+	onSeen     func()
+}
+
+// Create a post stream that records each post passing the prefilters in the post
+// history (if set) before reporting it, calling onSeen once the post is marked as
+// seen.
+func newPostStream(seen store.SeenStore, history *store.PostHistory, report func(p *reddit.Post), onSeen func()) *postStream {
+	return &postStream{
+		prefilters: defaultPrefilters,
+		seen:       seen,
+		onSeen:     onSeen,
+		onPost: func(p *reddit.Post) {
+			if history != nil {
+				if err := history.Record([]*reddit.Post{p}, time.Now()); err != nil {
+					logging.Errorf("%v: failed to record post history: %v", progName, err)
+				}
+			}
+
+			report(p)
+		},
+	}
+}
+
+// Determine if the post passes every prefilter.
+func (s *postStream) passesPrefilters(p *reddit.Post) bool {
+	for _, pf := range s.prefilters {
+		if !pf(p) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (s *postStream) Post(p *reddit.Post) error {
+	if s.seen != nil && s.seen.Has(p.ID) {
+		return nil
+	}
+
+	if s.passesPrefilters(p) {
+		s.onPost(p)
+	}
+
+	if s.seen != nil {
+		s.seen.Mark(p.ID)
+		if s.onSeen != nil {
+			s.onSeen()
+		}
+	}
+
+	return nil
+}
+
+// Stream the new posts of the subreddits to the post stream until the context is
+// done. When the stream fails, it is reconnected after waiting the next poll
+// interval. The program is considered healthy while the stream is connected.
+func watchSubreddits(ctx context.Context, bot reddit.Bot, subredditNames []string, stream *postStream, pi *pollInterval, health *healthState) error {
+	for {
+		stop, wait, err := graw.Run(stream, bot, graw.Config{Subreddits: subredditNames})
+		if err == nil {
+			done := make(chan struct{})
+			go func() {
+				ticker := time.NewTicker(watchHealthInterval)
+				defer ticker.Stop()
+				for {
+					health.pollSucceeded()
+					select {
+					case <-ctx.Done():
+						stop()
+						return
+					case <-done:
+						return
+					case <-ticker.C:
+					}
+				}
+			}()
+
+			err = wait()
+			close(done)
+		}
+
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		delay := pi.next(false)
+		logging.Warnf("%v: the subreddit stream failed, reconnecting in %v: %v", progName, delay, err)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(delay):
+		}
+	}
+}
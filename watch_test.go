@@ -0,0 +1,180 @@
+// Copyright (c) 2021 Conner Crosby
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cavcrosby/rsb/engine"
+	"github.com/cavcrosby/rsb/store"
+	"github.com/turnage/graw/reddit"
+)
+
+// Feed the posts to the post stream one at a time, as graw does when posts
+// arrive, returning the IDs of the posts matched after each post.
+func feedPostStream(t *testing.T, stream *postStream, posts []*reddit.Post, matched *[]string) [][]string {
+	t.Helper()
+	var surfaced [][]string
+	for _, p := range posts {
+		if err := stream.Post(p); err != nil {
+			t.Fatalf("Post(%q) returned an error: %v", p.ID, err)
+		}
+		surfaced = append(surfaced, append([]string(nil), *matched...))
+	}
+
+	return surfaced
+}
+
+func TestPostStream(t *testing.T) {
+	rules, err := engine.BuildRules([]engine.RuleConfig{
+		{ID: "ramunderprice", Configs: map[string]interface{}{"price": 100.0}},
+	})
+	if err != nil {
+		t.Fatalf("failed to build rules: %v", err)
+	}
+	h := engine.NewHeuristic(rules)
+
+	cheap := &reddit.Post{ID: "cheap", Title: "[RAM] Corsair Vengeance 16GB $49.99"}
+	gpu := &reddit.Post{ID: "gpu", Title: "[GPU] RTX 4070 $549.99"}
+	cheaper := &reddit.Post{ID: "cheaper", Title: "[RAM] Crucial 8GB DDR4 $19.99"}
+	distinguished := &reddit.Post{ID: "mod", Title: "[RAM] Corsair 8GB $9.99", Distinguished: "moderator"}
+
+	tests := []struct {
+		name  string
+		posts []*reddit.Post
+		want  [][]string
+	}{
+		{
+			"matches surface as posts arrive",
+			[]*reddit.Post{cheap, gpu, cheaper},
+			[][]string{{"cheap"}, {"cheap"}, {"cheap", "cheaper"}},
+		},
+		{
+			"posts seen again are not matched again",
+			[]*reddit.Post{cheap, cheap, cheaper, cheap},
+			[][]string{{"cheap"}, {"cheap"}, {"cheap", "cheaper"}, {"cheap", "cheaper"}},
+		},
+		{
+			"prefiltered posts are not matched",
+			[]*reddit.Post{distinguished, cheap},
+			[][]string{nil, {"cheap"}},
+		},
+	}
+
+	for _, tt := range tests {
+		var matched []string
+		seenCalls := 0
+		stream := &postStream{
+			prefilters: defaultPrefilters,
+			seen:       store.NewMemorySeenStore(),
+			onSeen:     func() { seenCalls++ },
+			onPost: func(p *reddit.Post) {
+				for _, match := range h.AppliedTo([]*reddit.Post{p}, nil) {
+					matched = append(matched, match.Post.ID)
+				}
+			},
+		}
+
+		got := feedPostStream(t, stream, tt.posts, &matched)
+		for i := range tt.want {
+			if len(got[i]) != len(tt.want[i]) {
+				t.Errorf("%v: after post %v matched %v, want %v", tt.name, i+1, got[i], tt.want[i])
+				continue
+			}
+			for j := range tt.want[i] {
+				if got[i][j] != tt.want[i][j] {
+					t.Errorf("%v: after post %v matched %v, want %v", tt.name, i+1, got[i], tt.want[i])
+					break
+				}
+			}
+		}
+
+		if seenCalls == 0 {
+			t.Errorf("%v: onSeen was never called", tt.name)
+		}
+	}
+}
+
+// A type that represents a bot whose subreddit listings always fail, counting the
+// times it was connected to.
+type failingBot struct {
+	reddit.Bot
+	mu       sync.Mutex
+	listings int
+}
+
+func (b *failingBot) Listing(path, after string) (reddit.Harvest, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.listings++
+	return reddit.Harvest{}, errors.New("listing unavailable")
+}
+
+func (b *failingBot) numListings() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.listings
+}
+
+func TestWatchSubredditsReconnects(t *testing.T) {
+	tests := []struct {
+		name          string
+		minReconnects int
+	}{
+		{"cancelled before connecting", 0},
+		{"cancelled after reconnecting", 3},
+	}
+
+	for _, tt := range tests {
+		bot := &failingBot{}
+		ctx, cancel := context.WithCancel(context.Background())
+		if tt.minReconnects == 0 {
+			cancel()
+		}
+
+		done := make(chan error, 1)
+		go func() {
+			pi := newPollInterval(time.Millisecond, time.Millisecond, 1)
+			done <- watchSubreddits(ctx, bot, []string{"buildapcsales"}, &postStream{}, pi, newHealthState(time.Minute))
+		}()
+
+		deadline := time.Now().Add(5 * time.Second)
+		for bot.numListings() < tt.minReconnects && time.Now().Before(deadline) {
+			time.Sleep(time.Millisecond)
+		}
+		if got := bot.numListings(); got < tt.minReconnects {
+			t.Errorf("%v: connected %v times, want at least %v", tt.name, got, tt.minReconnects)
+		}
+		cancel()
+
+		select {
+		case err := <-done:
+			if err != nil {
+				t.Errorf("%v: watchSubreddits() returned %v, want nil", tt.name, err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("%v: watchSubreddits() did not return after the context was cancelled", tt.name)
+		}
+	}
+}